@@ -0,0 +1,326 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kenshaw/snaker"
+	"github.com/pkg/errors"
+)
+
+// csharpShapeTypes is the c# type of every json shape
+//
+// Bytes are byte[] since System.Text.Json expects base64 for it
+var csharpShapeTypes = map[jsonShape]string{
+	jsonAny:    "JsonElement",
+	jsonString: "string",
+	jsonInt:    "int",
+	jsonBigint: "long",
+	jsonFloat:  "double",
+	jsonBool:   "bool",
+	jsonTime:   "DateTimeOffset",
+	jsonBytes:  "byte[]",
+}
+
+// csharpReferenceTypes are the generated c# types which are reference types and
+// so need to be initialized in classes with nullable reference types enabled
+var csharpReferenceTypes = map[string]bool{
+	"string": true,
+	"byte[]": true,
+}
+
+// GenerateCSharpModels generates a record with System.Text.Json attributes for
+// every table in a single file named csharpFile within csharpDir
+//
+// When csharpFile is empty every table is generated into its own file named
+// after its record instead.  Classes with settable properties are generated
+// instead of positional records when cfg.Classes is set and every file declares
+// the namespace cfg.Package
+func GenerateCSharpModels(tables []Table, csharpDir, csharpFile string, cfg GenerateConfig) error {
+	if csharpDir == "" {
+		return errors.WithStack(fmt.Errorf("model-gen: csharpDir parameter can't be empty"))
+	}
+
+	if err := os.MkdirAll(csharpDir, os.ModePerm); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if csharpFile != "" {
+		if !strings.HasSuffix(csharpFile, ".cs") {
+			csharpFile += ".cs"
+		}
+
+		return writeCSharpFile(filepath.Join(csharpDir, csharpFile), tables, cfg)
+	}
+
+	for _, table := range tables {
+		fileName := filepath.Join(csharpDir, cfg.Model.structName(table.Name)+".cs")
+
+		if err := writeCSharpFile(fileName, []Table{table}, cfg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeCSharpFile writes the types of tables into fileName along with the
+// usings and namespace they need
+func writeCSharpFile(fileName string, tables []Table, cfg GenerateConfig) error {
+	var b, types strings.Builder
+
+	usings := map[string]bool{"System.Text.Json.Serialization": true}
+
+	for _, table := range tables {
+		types.WriteString("\n")
+		types.WriteString(csharpType(table, cfg))
+
+		for _, col := range table.Columns {
+			switch t := csharpPropertyType(table, col, cfg); {
+			case cfg.Model.enumColumn(table, col):
+				usings["System"] = true
+				usings["System.Text.Json"] = true
+			case t == "DateTimeOffset" || t == "Guid":
+				usings["System"] = true
+			case t == "JsonElement":
+				usings["System.Text.Json"] = true
+			}
+		}
+	}
+
+	b.WriteString("#nullable enable\n\n")
+
+	for _, using := range sortedKeys(usings) {
+		b.WriteString(fmt.Sprintf("using %s;\n", using))
+	}
+
+	if cfg.Package != "" {
+		b.WriteString(fmt.Sprintf("\nnamespace %s;\n", cfg.Package))
+	}
+
+	b.WriteString(types.String())
+
+	return errors.WithStack(os.WriteFile(fileName, []byte(b.String()), 0644))
+}
+
+// csharpProperty is a property of a generated record or class
+type csharpProperty struct {
+	name     string
+	jsonName string
+	typeName string
+	comment  string
+}
+
+// csharpType returns the record or class of table followed by the enums of its
+// enum columns
+func csharpType(table Table, cfg GenerateConfig) string {
+	var b, enums strings.Builder
+	var properties []csharpProperty
+
+	typeName := cfg.Model.structName(table.Name)
+
+	for _, col := range table.Columns {
+		jsonName, omitEmpty, ok := cfg.Model.jsonField(table.Name, col)
+
+		// Fields hidden from json are never part of the api contract
+		if !ok {
+			continue
+		}
+
+		propertyType := csharpPropertyType(table, col, cfg)
+
+		if col.Nullable || omitEmpty {
+			propertyType += "?"
+		}
+
+		properties = append(properties, csharpProperty{
+			name:     csharpIdentifier(col.Name, typeName),
+			jsonName: jsonName,
+			typeName: propertyType,
+			comment:  col.Comment,
+		})
+
+		if cfg.Model.enumColumn(table, col) {
+			enums.WriteString(csharpEnum(cfg.Model.enumName(table.Name, col), col.EnumValues))
+		}
+	}
+
+	// Relations are only set when they are loaded so they are always nullable
+	for _, fk := range table.ForeignKeys {
+		jsonName, _ := cfg.Model.relationJSONField(table, fk)
+
+		properties = append(properties, csharpProperty{
+			name:     csharpIdentifier(cfg.Model.relationName(table, fk), typeName),
+			jsonName: jsonName,
+			typeName: cfg.Model.structName(fk.ForeignTableName) + "?",
+		})
+	}
+
+	if cfg.Classes {
+		b.WriteString(csharpDoc("summary", "", table.Comment, ""))
+		b.WriteString(fmt.Sprintf("public class %s\n{\n", typeName))
+
+		for i, p := range properties {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+
+			b.WriteString(csharpDoc("summary", "", p.comment, "    "))
+			b.WriteString(fmt.Sprintf("    [JsonPropertyName(%s)]\n", csharpString(p.jsonName)))
+			b.WriteString(fmt.Sprintf("    public %s %s { get; set; }", p.typeName, p.name))
+
+			if csharpReferenceTypes[p.typeName] {
+				b.WriteString(" = default!;")
+			}
+
+			b.WriteString("\n")
+		}
+
+		b.WriteString("}\n")
+	} else {
+		b.WriteString(csharpDoc("summary", "", table.Comment, ""))
+
+		for _, p := range properties {
+			b.WriteString(csharpDoc("param", p.name, p.comment, ""))
+		}
+
+		b.WriteString(fmt.Sprintf("public record %s(\n", typeName))
+
+		for i, p := range properties {
+			b.WriteString(fmt.Sprintf(
+				"    [property: JsonPropertyName(%s)] %s %s",
+				csharpString(p.jsonName),
+				p.typeName,
+				p.name,
+			))
+
+			if i < len(properties)-1 {
+				b.WriteString(",")
+			}
+
+			b.WriteString("\n")
+		}
+
+		b.WriteString(");\n")
+	}
+
+	b.WriteString(enums.String())
+
+	return b.String()
+}
+
+// csharpEnum returns an enum named name with a member per value along with the
+// json converter serializing every member as its database value
+func csharpEnum(name string, values []string) string {
+	var b, read, write strings.Builder
+
+	converter := name + "JsonConverter"
+
+	for i, member := range enumConstants(values, pascalEnumConstant) {
+		read.WriteString(fmt.Sprintf("            %s => %s.%s,\n", csharpString(values[i]), name, member))
+		write.WriteString(fmt.Sprintf("            %s.%s => %s,\n", name, member, csharpString(values[i])))
+	}
+
+	b.WriteString(fmt.Sprintf("\n[JsonConverter(typeof(%s))]\n", converter))
+	b.WriteString(fmt.Sprintf("public enum %s\n{\n", name))
+
+	for _, member := range enumConstants(values, pascalEnumConstant) {
+		b.WriteString(fmt.Sprintf("    %s,\n", member))
+	}
+
+	b.WriteString("}\n\n")
+	b.WriteString(fmt.Sprintf("public sealed class %s : JsonConverter<%s>\n{\n", converter, name))
+	b.WriteString(fmt.Sprintf(
+		"    public override %s Read(ref Utf8JsonReader reader, Type typeToConvert, JsonSerializerOptions options) =>\n",
+		name,
+	))
+	b.WriteString("        reader.GetString() switch\n        {\n")
+	b.WriteString(read.String())
+	b.WriteString(fmt.Sprintf("            var value => throw new JsonException($\"Unknown %s value '{value}'\"),\n", name))
+	b.WriteString("        };\n\n")
+	b.WriteString(fmt.Sprintf(
+		"    public override void Write(Utf8JsonWriter writer, %s value, JsonSerializerOptions options) =>\n",
+		name,
+	))
+	b.WriteString("        writer.WriteStringValue(value switch\n        {\n")
+	b.WriteString(write.String())
+	b.WriteString(fmt.Sprintf("            _ => throw new JsonException($\"Unknown %s value '{value}'\"),\n", name))
+	b.WriteString("        });\n")
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// csharpPropertyType returns the c# type of col of table without nullability
+func csharpPropertyType(table Table, col Column, cfg GenerateConfig) string {
+	if cfg.Model.enumColumn(table, col) {
+		return cfg.Model.enumName(table.Name, col)
+	}
+
+	switch shape := cfg.Model.jsonShape(table, col); {
+	case shape == jsonString && col.kind() == uuidKind:
+		return "Guid"
+	case shape == jsonInt && smallintTypes[col.DataType]:
+		return "short"
+	case shape == jsonFloat && col.kind() == decimalKind:
+		return "decimal"
+	default:
+		return csharpShapeTypes[shape]
+	}
+}
+
+// csharpIdentifier returns the PascalCase property name of a column, suffixed
+// when it would clash with the name of the type it belongs to
+func csharpIdentifier(columnName, typeName string) string {
+	name := snaker.ForceCamelIdentifier(columnName)
+
+	if name == typeName {
+		return name + "Value"
+	}
+
+	return name
+}
+
+// csharpDoc returns comment as an xml documentation element named element
+// indented by indent, or nothing if comment is empty
+//
+// paramName is the name attribute of param elements
+func csharpDoc(element, paramName, comment, indent string) string {
+	comment = strings.TrimSpace(comment)
+
+	if comment == "" {
+		return ""
+	}
+
+	comment = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(comment)
+	open := element
+
+	if paramName != "" {
+		open = fmt.Sprintf("%s name=\"%s\"", element, paramName)
+	}
+
+	lines := strings.Split(comment, "\n")
+
+	if len(lines) == 1 {
+		return fmt.Sprintf("%s/// <%s>%s</%s>\n", indent, open, lines[0], element)
+	}
+
+	var doc strings.Builder
+
+	doc.WriteString(fmt.Sprintf("%s/// <%s>\n", indent, open))
+
+	for _, line := range lines {
+		doc.WriteString(strings.TrimRight(indent+"/// "+strings.TrimSpace(line), " ") + "\n")
+	}
+
+	doc.WriteString(fmt.Sprintf("%s/// </%s>\n", indent, element))
+
+	return doc.String()
+}
+
+// csharpString returns value as a c# string literal
+func csharpString(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
@@ -0,0 +1,212 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateCSharpModels(t *testing.T) {
+	var err error
+
+	csharpDir := filepath.Join(t.TempDir(), "csharp")
+
+	if err = GenerateCSharpModels(nil, "", "", GenerateConfig{}); err == nil {
+		t.Fatalf("should have error\n")
+	}
+
+	tables := []Table{
+		{
+			Name: "user_profile",
+			Columns: []Column{
+				{Name: "id", DataType: "int8"},
+				{Name: "status", DataType: "user_status", EnumValues: []string{"active", "on-hold"}},
+			},
+		},
+		{
+			Name:    "orders",
+			Columns: []Column{{Name: "id", DataType: "uuid"}},
+		},
+	}
+
+	if err = GenerateCSharpModels(tables, csharpDir, "Models", GenerateConfig{Package: "Acme.Models"}); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	content, err := os.ReadFile(filepath.Join(csharpDir, "Models.cs"))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expected := "#nullable enable\n\n" +
+		"using System;\n" +
+		"using System.Text.Json;\n" +
+		"using System.Text.Json.Serialization;\n\n" +
+		"namespace Acme.Models;\n\n" +
+		"public record UserProfile(\n"
+
+	if !strings.HasPrefix(string(content), expected) {
+		t.Fatalf("expected c# output to start with:\n%s\ngot:\n%s\n", expected, string(content))
+	}
+
+	if err = GenerateCSharpModels(tables, csharpDir, "", GenerateConfig{}); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	content, err = os.ReadFile(filepath.Join(csharpDir, "Order.cs"))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// Only files with enums need the converters of System.Text.Json
+	expected = "#nullable enable\n\n" +
+		"using System;\n" +
+		"using System.Text.Json.Serialization;\n\n" +
+		"public record Order(\n"
+
+	if !strings.HasPrefix(string(content), expected) {
+		t.Fatalf("expected c# output to start with:\n%s\ngot:\n%s\n", expected, string(content))
+	}
+
+	if _, err = os.Stat(filepath.Join(csharpDir, "UserProfile.cs")); err != nil {
+		t.Fatalf("expected file UserProfile.cs; %s\n", err.Error())
+	}
+}
+
+func TestCSharpType(t *testing.T) {
+	classes := GenerateConfig{Classes: true}
+
+	tests := []struct {
+		name     string
+		table    Table
+		cfg      GenerateConfig
+		expected string
+	}{
+		{
+			name: "columns",
+			table: Table{
+				Name:    "user_profile",
+				Comment: "Person using the app",
+				Columns: []Column{
+					{Name: "id", DataType: "int8", Comment: "Identifier"},
+					{Name: "name", DataType: "varchar", Nullable: true},
+					{Name: "created_at", DataType: "timestamptz"},
+				},
+			},
+			cfg: GenerateConfig{
+				Model: ModelConfig{Tags: []TagGenerator{{Name: "json", Case: SnakeCase}}},
+			},
+			expected: "/// <summary>Person using the app</summary>\n" +
+				"/// <param name=\"ID\">Identifier</param>\n" +
+				"public record UserProfile(\n" +
+				"    [property: JsonPropertyName(\"id\")] long ID,\n" +
+				"    [property: JsonPropertyName(\"name\")] string? Name,\n" +
+				"    [property: JsonPropertyName(\"created_at\")] DateTimeOffset CreatedAt\n" +
+				");\n",
+		},
+		{
+			name: "enum",
+			table: Table{
+				Name: "user_profile",
+				Columns: []Column{
+					{Name: "status", DataType: "user_status", EnumValues: []string{"active", "on-hold"}},
+				},
+			},
+			expected: "public record UserProfile(\n" +
+				"    [property: JsonPropertyName(\"status\")] UserProfileStatus Status\n" +
+				");\n\n" +
+				"[JsonConverter(typeof(UserProfileStatusJsonConverter))]\n" +
+				"public enum UserProfileStatus\n" +
+				"{\n" +
+				"    Active,\n" +
+				"    OnHold,\n" +
+				"}\n\n" +
+				"public sealed class UserProfileStatusJsonConverter : JsonConverter<UserProfileStatus>\n" +
+				"{\n" +
+				"    public override UserProfileStatus Read(ref Utf8JsonReader reader, Type typeToConvert, JsonSerializerOptions options) =>\n" +
+				"        reader.GetString() switch\n" +
+				"        {\n" +
+				"            \"active\" => UserProfileStatus.Active,\n" +
+				"            \"on-hold\" => UserProfileStatus.OnHold,\n" +
+				"            var value => throw new JsonException($\"Unknown UserProfileStatus value '{value}'\"),\n" +
+				"        };\n\n" +
+				"    public override void Write(Utf8JsonWriter writer, UserProfileStatus value, JsonSerializerOptions options) =>\n" +
+				"        writer.WriteStringValue(value switch\n" +
+				"        {\n" +
+				"            UserProfileStatus.Active => \"active\",\n" +
+				"            UserProfileStatus.OnHold => \"on-hold\",\n" +
+				"            _ => throw new JsonException($\"Unknown UserProfileStatus value '{value}'\"),\n" +
+				"        });\n" +
+				"}\n",
+		},
+		{
+			name: "plural table with a property named after its type",
+			table: Table{
+				Name: "orders",
+				Columns: []Column{
+					{Name: "id", DataType: "uuid"},
+					{Name: "order", DataType: "text"},
+				},
+			},
+			cfg: classes,
+			expected: "public class Order\n" +
+				"{\n" +
+				"    [JsonPropertyName(\"id\")]\n" +
+				"    public Guid ID { get; set; }\n\n" +
+				"    [JsonPropertyName(\"order\")]\n" +
+				"    public string OrderValue { get; set; } = default!;\n" +
+				"}\n",
+		},
+		{
+			name: "self relation",
+			table: Table{
+				Name:        "employee",
+				Columns:     []Column{{Name: "manager_id", DataType: "int8", Nullable: true}},
+				ForeignKeys: []ForeignKey{{ColumnName: "manager_id", ForeignTableName: "employee"}},
+			},
+			expected: "public record Employee(\n" +
+				"    [property: JsonPropertyName(\"managerID\")] long? ManagerID,\n" +
+				"    [property: JsonPropertyName(\"manager\")] Employee? Manager\n" +
+				");\n",
+		},
+		{
+			name: "relation without id suffix",
+			table: Table{
+				Name:        "orders",
+				Columns:     []Column{{Name: "buyer", DataType: "int8", Comment: "Person who placed the order"}},
+				ForeignKeys: []ForeignKey{{ColumnName: "buyer", ForeignTableName: "user_profile"}},
+			},
+			cfg: classes,
+			expected: "public class Order\n" +
+				"{\n" +
+				"    /// <summary>Person who placed the order</summary>\n" +
+				"    [JsonPropertyName(\"buyer\")]\n" +
+				"    public long Buyer { get; set; }\n\n" +
+				"    [JsonPropertyName(\"buyerUserProfile\")]\n" +
+				"    public UserProfile? BuyerUserProfile { get; set; }\n" +
+				"}\n",
+		},
+	}
+
+	for _, test := range tests {
+		if s := csharpType(test.table, test.cfg); s != test.expected {
+			t.Fatalf("%s: expected c# type:\n%s\ngot:\n%s\n", test.name, test.expected, s)
+		}
+	}
+}
+
+func TestCSharpDoc(t *testing.T) {
+	doc := csharpDoc("summary", "", "Orders <pending>\nand shipped", "    ")
+	expected := "    /// <summary>\n    /// Orders &lt;pending&gt;\n    /// and shipped\n    /// </summary>\n"
+
+	if doc != expected {
+		t.Fatalf("expected doc:\n%s\ngot:\n%s\n", expected, doc)
+	}
+
+	if doc = csharpDoc("summary", "", " ", ""); doc != "" {
+		t.Fatalf("empty comments should have no doc; got '%s'\n", doc)
+	}
+}
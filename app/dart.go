@@ -0,0 +1,253 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kenshaw/snaker"
+	"github.com/pkg/errors"
+)
+
+// dartShapeTypes is the dart type of every json shape
+var dartShapeTypes = map[jsonShape]string{
+	jsonAny:    "Object?",
+	jsonString: "String",
+	jsonInt:    "int",
+	jsonBigint: "int",
+	jsonFloat:  "double",
+	jsonBool:   "bool",
+	jsonTime:   "DateTime",
+	jsonBytes:  "String",
+}
+
+// dartKeywords are the reserved words of dart which can't be used as
+// identifiers, along with the members every object already has
+var dartKeywords = map[string]bool{
+	"assert": true, "break": true, "case": true, "catch": true, "class": true,
+	"const": true, "continue": true, "default": true, "do": true, "else": true,
+	"enum": true, "extends": true, "false": true, "final": true, "finally": true,
+	"for": true, "if": true, "in": true, "is": true, "new": true,
+	"null": true, "rethrow": true, "return": true, "super": true, "switch": true,
+	"this": true, "throw": true, "true": true, "try": true, "var": true,
+	"void": true, "while": true, "with": true, "hashCode": true, "runtimeType": true,
+}
+
+// dartEnumMembers are the members every dart enum already has
+var dartEnumMembers = map[string]bool{
+	"index":  true,
+	"name":   true,
+	"values": true,
+}
+
+// GenerateDartModels generates a json_serializable class for every table in a
+// single library named dartFile within dartDir
+//
+// When dartFile is empty every table is generated into its own library named
+// after the table instead.  Every library declares its .g.dart part so
+// build_runner has to be run to generate the json conversion code
+func GenerateDartModels(tables []Table, dartDir, dartFile string, cfg GenerateConfig) error {
+	if dartDir == "" {
+		return errors.WithStack(fmt.Errorf("model-gen: dartDir parameter can't be empty"))
+	}
+
+	if err := os.MkdirAll(dartDir, os.ModePerm); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if dartFile != "" {
+		return writeDartFile(dartDir, strings.TrimSuffix(dartFile, ".dart"), tables, cfg, false)
+	}
+
+	for _, table := range tables {
+		if err := writeDartFile(dartDir, table.Name, []Table{table}, cfg, true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeDartFile writes the classes of tables into the library named library
+// within dartDir
+//
+// Classes of other tables are imported from their own libraries when perTable
+// is set
+func writeDartFile(dartDir, library string, tables []Table, cfg GenerateConfig, perTable bool) error {
+	var b, classes strings.Builder
+
+	relations := map[string]bool{}
+
+	for _, table := range tables {
+		for _, fk := range table.ForeignKeys {
+			if perTable && fk.ForeignTableName != table.Name {
+				relations[fk.ForeignTableName] = true
+			}
+		}
+
+		classes.WriteString("\n")
+		classes.WriteString(dartClass(table, cfg))
+	}
+
+	b.WriteString("import 'package:json_annotation/json_annotation.dart';\n")
+
+	if len(relations) > 0 {
+		b.WriteString("\n")
+	}
+
+	for _, tableName := range sortedKeys(relations) {
+		b.WriteString(fmt.Sprintf("import '%s.dart';\n", tableName))
+	}
+
+	b.WriteString(fmt.Sprintf("\npart '%s.g.dart';\n", library))
+	b.WriteString(classes.String())
+
+	return errors.WithStack(os.WriteFile(filepath.Join(dartDir, library+".dart"), []byte(b.String()), 0644))
+}
+
+// dartField is a field of a generated class
+type dartField struct {
+	name      string
+	jsonName  string
+	typeName  string
+	comment   string
+	omitEmpty bool
+}
+
+// dartClass returns the class of table followed by the enums of its enum columns
+func dartClass(table Table, cfg GenerateConfig) string {
+	var b, enums strings.Builder
+	var fields []dartField
+
+	className := cfg.Model.structName(table.Name)
+
+	for _, col := range table.Columns {
+		jsonName, omitEmpty, ok := cfg.Model.jsonField(table.Name, col)
+
+		// Fields hidden from json are never part of the api contract
+		if !ok {
+			continue
+		}
+
+		fieldType := dartType(table, col, cfg)
+
+		if (col.Nullable || omitEmpty) && !strings.HasSuffix(fieldType, "?") {
+			fieldType += "?"
+		}
+
+		fields = append(fields, dartField{
+			name:      dartIdentifier(snaker.ForceLowerCamelIdentifier(col.Name)),
+			jsonName:  jsonName,
+			typeName:  fieldType,
+			comment:   col.Comment,
+			omitEmpty: omitEmpty,
+		})
+
+		if cfg.Model.enumColumn(table, col) {
+			enums.WriteString(dartEnum(cfg.Model.enumName(table.Name, col), col.EnumValues))
+		}
+	}
+
+	// Relations are only set when they are loaded so they are always nullable
+	for _, fk := range table.ForeignKeys {
+		jsonName, omitEmpty := cfg.Model.relationJSONField(table, fk)
+
+		fields = append(fields, dartField{
+			name:      dartIdentifier(snaker.ForceLowerCamelIdentifier(cfg.Model.relationName(table, fk))),
+			jsonName:  jsonName,
+			typeName:  cfg.Model.structName(fk.ForeignTableName) + "?",
+			omitEmpty: omitEmpty,
+		})
+	}
+
+	b.WriteString(docLines(table.Comment, ""))
+	b.WriteString("@JsonSerializable()\n")
+	b.WriteString(fmt.Sprintf("class %s {\n", className))
+
+	if len(fields) == 0 {
+		b.WriteString(fmt.Sprintf("  const %s();\n\n", className))
+	} else {
+		b.WriteString(fmt.Sprintf("  const %s({\n", className))
+
+		for _, f := range fields {
+			if strings.HasSuffix(f.typeName, "?") {
+				b.WriteString(fmt.Sprintf("    this.%s,\n", f.name))
+			} else {
+				b.WriteString(fmt.Sprintf("    required this.%s,\n", f.name))
+			}
+		}
+
+		b.WriteString("  });\n\n")
+	}
+
+	b.WriteString(fmt.Sprintf(
+		"  factory %s.fromJson(Map<String, dynamic> json) => _$%sFromJson(json);\n",
+		className,
+		className,
+	))
+
+	for _, f := range fields {
+		b.WriteString("\n")
+		b.WriteString(docLines(f.comment, "  "))
+
+		if f.omitEmpty {
+			b.WriteString(fmt.Sprintf("  @JsonKey(name: %s, includeIfNull: false)\n", dartString(f.jsonName)))
+		} else {
+			b.WriteString(fmt.Sprintf("  @JsonKey(name: %s)\n", dartString(f.jsonName)))
+		}
+
+		b.WriteString(fmt.Sprintf("  final %s %s;\n", f.typeName, f.name))
+	}
+
+	b.WriteString(fmt.Sprintf("\n  Map<String, dynamic> toJson() => _$%sToJson(this);\n", className))
+	b.WriteString("}\n")
+	b.WriteString(enums.String())
+
+	return b.String()
+}
+
+// dartEnum returns an enum named name serializing every value as its database
+// value
+func dartEnum(name string, values []string) string {
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("\nenum %s {\n", name))
+
+	for i, constant := range enumConstants(values, camelEnumConstant) {
+		b.WriteString(fmt.Sprintf("  @JsonValue(%s)\n", dartString(values[i])))
+		if dartEnumMembers[constant] {
+			constant += "_"
+		}
+
+		b.WriteString(fmt.Sprintf("  %s,\n", dartIdentifier(constant)))
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// dartType returns the dart type of col of table without nullability, except for
+// types that are always nullable
+func dartType(table Table, col Column, cfg GenerateConfig) string {
+	if cfg.Model.enumColumn(table, col) {
+		return cfg.Model.enumName(table.Name, col)
+	}
+
+	return dartShapeTypes[cfg.Model.jsonShape(table, col)]
+}
+
+// dartIdentifier suffixes name with an underscore if it is a reserved word
+func dartIdentifier(name string) string {
+	if dartKeywords[name] {
+		return name + "_"
+	}
+
+	return name
+}
+
+// dartString returns value as a dart string literal
+func dartString(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`, "\n", `\n`, "$", `\$`).Replace(value) + "'"
+}
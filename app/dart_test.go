@@ -0,0 +1,210 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateDartModels(t *testing.T) {
+	var err error
+
+	dartDir := filepath.Join(t.TempDir(), "dart")
+
+	if err = GenerateDartModels(nil, "", "", GenerateConfig{}); err == nil {
+		t.Fatalf("should have error\n")
+	}
+
+	tables := []Table{
+		{
+			Name:    "user_profile",
+			Columns: []Column{{Name: "id", DataType: "int8"}},
+		},
+		{
+			Name: "orders",
+			Columns: []Column{
+				{Name: "id", DataType: "int4"},
+				{Name: "buyer", DataType: "int8"},
+			},
+			ForeignKeys: []ForeignKey{
+				{ColumnName: "buyer", ForeignTableName: "user_profile"},
+			},
+		},
+	}
+
+	if err = GenerateDartModels(tables, dartDir, "models", GenerateConfig{}); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	content, err := os.ReadFile(filepath.Join(dartDir, "models.dart"))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expected := "import 'package:json_annotation/json_annotation.dart';\n\n" +
+		"part 'models.g.dart';\n\n" +
+		"@JsonSerializable()\n" +
+		"class UserProfile {\n"
+
+	if !strings.HasPrefix(string(content), expected) {
+		t.Fatalf("expected dart output to start with:\n%s\ngot:\n%s\n", expected, string(content))
+	}
+
+	if err = GenerateDartModels(tables, dartDir, "", GenerateConfig{}); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	content, err = os.ReadFile(filepath.Join(dartDir, "orders.dart"))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if !strings.Contains(string(content), "import 'user_profile.dart';\n\npart 'orders.g.dart';\n") {
+		t.Fatalf("expected orders.dart to import the classes it relates to; got:\n%s\n", string(content))
+	}
+}
+
+func TestDartClass(t *testing.T) {
+	tests := []struct {
+		name     string
+		table    Table
+		cfg      GenerateConfig
+		expected string
+	}{
+		{
+			name: "columns",
+			table: Table{
+				Name:    "user_profile",
+				Comment: "Person using the app",
+				Columns: []Column{
+					{Name: "id", DataType: "int8"},
+					{Name: "name", DataType: "varchar", Nullable: true, Comment: "Display name"},
+					{Name: "settings", DataType: "jsonb"},
+					{Name: "created_at", DataType: "timestamptz"},
+				},
+			},
+			cfg: GenerateConfig{
+				Model: ModelConfig{
+					TypeMap: TypeMap{{DBType: "jsonb", GoType: "datatypes.JSON"}},
+					Tags:    []TagGenerator{{Name: "json", Case: SnakeCase, OmitEmpty: OmitEmptyAlways}},
+				},
+			},
+			expected: "/// Person using the app\n" +
+				"@JsonSerializable()\n" +
+				"class UserProfile {\n" +
+				"  const UserProfile({\n" +
+				"    this.id,\n" +
+				"    this.name,\n" +
+				"    this.settings,\n" +
+				"    this.createdAt,\n" +
+				"  });\n\n" +
+				"  factory UserProfile.fromJson(Map<String, dynamic> json) => _$UserProfileFromJson(json);\n\n" +
+				"  @JsonKey(name: 'id', includeIfNull: false)\n" +
+				"  final int? id;\n\n" +
+				"  /// Display name\n" +
+				"  @JsonKey(name: 'name', includeIfNull: false)\n" +
+				"  final String? name;\n\n" +
+				"  @JsonKey(name: 'settings', includeIfNull: false)\n" +
+				"  final Object? settings;\n\n" +
+				"  @JsonKey(name: 'created_at', includeIfNull: false)\n" +
+				"  final DateTime? createdAt;\n\n" +
+				"  Map<String, dynamic> toJson() => _$UserProfileToJson(this);\n" +
+				"}\n",
+		},
+		{
+			name: "enum",
+			table: Table{
+				Name: "user_profile",
+				Columns: []Column{
+					{Name: "status", DataType: "user_status", EnumValues: []string{"active", "on-hold", "default"}},
+				},
+			},
+			expected: "@JsonSerializable()\n" +
+				"class UserProfile {\n" +
+				"  const UserProfile({\n" +
+				"    required this.status,\n" +
+				"  });\n\n" +
+				"  factory UserProfile.fromJson(Map<String, dynamic> json) => _$UserProfileFromJson(json);\n\n" +
+				"  @JsonKey(name: 'status')\n" +
+				"  final UserProfileStatus status;\n\n" +
+				"  Map<String, dynamic> toJson() => _$UserProfileToJson(this);\n" +
+				"}\n\n" +
+				"enum UserProfileStatus {\n" +
+				"  @JsonValue('active')\n" +
+				"  active,\n" +
+				"  @JsonValue('on-hold')\n" +
+				"  onHold,\n" +
+				"  @JsonValue('default')\n" +
+				"  default_,\n" +
+				"}\n",
+		},
+		{
+			name: "plural table",
+			table: Table{
+				Name:    "orders",
+				Columns: []Column{{Name: "id", DataType: "uuid"}},
+			},
+			expected: "@JsonSerializable()\n" +
+				"class Order {\n" +
+				"  const Order({\n" +
+				"    required this.id,\n" +
+				"  });\n\n" +
+				"  factory Order.fromJson(Map<String, dynamic> json) => _$OrderFromJson(json);\n\n" +
+				"  @JsonKey(name: 'id')\n" +
+				"  final String id;\n\n" +
+				"  Map<String, dynamic> toJson() => _$OrderToJson(this);\n" +
+				"}\n",
+		},
+		{
+			name: "self relation",
+			table: Table{
+				Name:        "employee",
+				Columns:     []Column{{Name: "manager_id", DataType: "int8", Nullable: true}},
+				ForeignKeys: []ForeignKey{{ColumnName: "manager_id", ForeignTableName: "employee"}},
+			},
+			expected: "@JsonSerializable()\n" +
+				"class Employee {\n" +
+				"  const Employee({\n" +
+				"    this.managerID,\n" +
+				"    this.manager,\n" +
+				"  });\n\n" +
+				"  factory Employee.fromJson(Map<String, dynamic> json) => _$EmployeeFromJson(json);\n\n" +
+				"  @JsonKey(name: 'managerID')\n" +
+				"  final int? managerID;\n\n" +
+				"  @JsonKey(name: 'manager')\n" +
+				"  final Employee? manager;\n\n" +
+				"  Map<String, dynamic> toJson() => _$EmployeeToJson(this);\n" +
+				"}\n",
+		},
+		{
+			name: "relation without id suffix",
+			table: Table{
+				Name:        "orders",
+				Columns:     []Column{{Name: "buyer", DataType: "int8"}},
+				ForeignKeys: []ForeignKey{{ColumnName: "buyer", ForeignTableName: "user_profile"}},
+			},
+			expected: "@JsonSerializable()\n" +
+				"class Order {\n" +
+				"  const Order({\n" +
+				"    required this.buyer,\n" +
+				"    this.buyerUserProfile,\n" +
+				"  });\n\n" +
+				"  factory Order.fromJson(Map<String, dynamic> json) => _$OrderFromJson(json);\n\n" +
+				"  @JsonKey(name: 'buyer')\n" +
+				"  final int buyer;\n\n" +
+				"  @JsonKey(name: 'buyerUserProfile')\n" +
+				"  final UserProfile? buyerUserProfile;\n\n" +
+				"  Map<String, dynamic> toJson() => _$OrderToJson(this);\n" +
+				"}\n",
+		},
+	}
+
+	for _, test := range tests {
+		if class := dartClass(test.table, test.cfg); class != test.expected {
+			t.Fatalf("%s: expected dart class:\n%s\ngot:\n%s\n", test.name, test.expected, class)
+		}
+	}
+}
@@ -0,0 +1,488 @@
+package app
+
+import (
+	"fmt"
+	"html"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var ErrInvalidDiagramFormat = errors.New("model-gen: invalid diagram format")
+
+// DiagramFormat is the language an entity relationship diagram is rendered in
+type DiagramFormat string
+
+const (
+	MermaidDiagramFormat  DiagramFormat = "mermaid"
+	DotDiagramFormat      DiagramFormat = "dot"
+	PlantUMLDiagramFormat DiagramFormat = "plantuml"
+	DBMLDiagramFormat     DiagramFormat = "dbml"
+)
+
+// DiagramSchema is a database schema along with the tables drawn for it
+type DiagramSchema struct {
+	Name   string
+	Tables []Table
+}
+
+// diagram holds the schemas being rendered and whether their tables are grouped
+// by schema, which is only the case when there is more than one
+type diagram struct {
+	schemas []DiagramSchema
+	grouped bool
+}
+
+// diagramRelation is a foreign key drawn between two tables of a diagram
+type diagramRelation struct {
+	from, to       string
+	column, target string
+	nullable       bool
+}
+
+// FilterTables returns the tables whose name matches any of the include
+// patterns and none of the exclude patterns
+//
+// Patterns use the syntax of path.Match, eg. "user_*".  Every table is included
+// when include is empty
+func FilterTables(tables []Table, include, exclude []string) ([]Table, error) {
+	var filtered []Table
+
+	for _, table := range tables {
+		included := len(include) == 0
+
+		for _, pattern := range include {
+			ok, err := path.Match(pattern, table.Name)
+
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			if ok {
+				included = true
+				break
+			}
+		}
+
+		for _, pattern := range exclude {
+			ok, err := path.Match(pattern, table.Name)
+
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			if ok {
+				included = false
+				break
+			}
+		}
+
+		if included {
+			filtered = append(filtered, table)
+		}
+	}
+
+	return filtered, nil
+}
+
+// RenderDiagram renders the tables of schemas as an entity relationship diagram
+// in format
+//
+// Columns are marked as primary and foreign keys and every foreign key between
+// two drawn tables becomes a relationship.  Tables are grouped by schema when
+// more than one schema is given, which mermaid has no syntax for so its entities
+// are prefixed with their schema instead
+func RenderDiagram(schemas []DiagramSchema, format DiagramFormat) (string, error) {
+	d := diagram{schemas: schemas, grouped: len(schemas) > 1}
+
+	switch format {
+	case MermaidDiagramFormat:
+		return d.mermaid(), nil
+	case DotDiagramFormat:
+		return d.dot(), nil
+	case PlantUMLDiagramFormat:
+		return d.plantUML(), nil
+	case DBMLDiagramFormat:
+		return d.dbml(), nil
+	}
+
+	return "", errors.WithStack(fmt.Errorf(packageErr, ErrInvalidDiagramFormat, format))
+}
+
+// id returns the identifier of tableName of schema, prefixed with the schema
+// when tables are grouped
+func (d diagram) id(schema, tableName, separator string) string {
+	if d.grouped {
+		return schema + separator + tableName
+	}
+
+	return tableName
+}
+
+// relations returns the foreign keys of the tables of schema which reference a
+// drawn table, which can be a table of another drawn schema
+func (d diagram) relations(schema DiagramSchema, separator string) []diagramRelation {
+	var relations []diagramRelation
+
+	tables := make(map[string]map[string]Table, len(d.schemas))
+
+	for _, s := range d.schemas {
+		tables[s.Name] = make(map[string]Table, len(s.Tables))
+
+		for _, table := range s.Tables {
+			tables[s.Name][table.Name] = table
+		}
+	}
+
+	for _, table := range schema.Tables {
+		for _, fk := range table.allForeignKeys() {
+			foreignSchema := fk.ForeignSchema
+
+			if foreignSchema == "" {
+				foreignSchema = schema.Name
+			}
+
+			target, ok := tables[foreignSchema][fk.ForeignTableName]
+
+			if !ok {
+				continue
+			}
+
+			relation := diagramRelation{
+				from:   d.id(schema.Name, table.Name, separator),
+				to:     d.id(foreignSchema, target.Name, separator),
+				column: fk.ColumnName,
+				target: fk.ForeignColumnName,
+			}
+
+			if relation.target == "" {
+				relation.target = referencedColumn(target)
+			}
+
+			if col := table.column(fk.ColumnName); col != nil {
+				relation.nullable = col.Nullable
+			}
+
+			relations = append(relations, relation)
+		}
+	}
+
+	return relations
+}
+
+// isForeignKey returns whether col of table references another table
+func isForeignKey(table Table, col Column) bool {
+	for _, fk := range table.allForeignKeys() {
+		if fk.ColumnName == col.Name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// diagramKeys returns the key markers of col of table, eg. "PK" or "PK, FK"
+func diagramKeys(table Table, col Column) []string {
+	var keys []string
+
+	if col.PrimaryKey {
+		keys = append(keys, "PK")
+	}
+	if isForeignKey(table, col) {
+		keys = append(keys, "FK")
+	}
+
+	return keys
+}
+
+// diagramType returns the data type of col along with its length, eg. "varchar(255)"
+func diagramType(col Column) string {
+	if col.MaxLength > 0 {
+		return fmt.Sprintf("%s(%d)", col.DataType, col.MaxLength)
+	}
+
+	return col.DataType
+}
+
+func (d diagram) mermaid() string {
+	var b strings.Builder
+
+	b.WriteString("erDiagram\n")
+
+	for _, schema := range d.schemas {
+		for _, table := range schema.Tables {
+			b.WriteString(fmt.Sprintf("    %s {\n", d.id(schema.Name, table.Name, "__")))
+
+			for _, col := range table.Columns {
+				// Mermaid types can't contain spaces or parentheses
+				b.WriteString(fmt.Sprintf("        %s %s", strings.ReplaceAll(col.DataType, " ", "_"), col.Name))
+
+				if keys := diagramKeys(table, col); len(keys) > 0 {
+					b.WriteString(" " + strings.Join(keys, ", "))
+				}
+				if col.Comment != "" {
+					b.WriteString(fmt.Sprintf(` "%s"`, strings.NewReplacer(`"`, "'", "\n", " ").Replace(col.Comment)))
+				}
+
+				b.WriteString("\n")
+			}
+
+			b.WriteString("    }\n")
+		}
+	}
+
+	for _, schema := range d.schemas {
+		for _, relation := range d.relations(schema, "__") {
+			cardinality := "||"
+
+			if relation.nullable {
+				cardinality = "|o"
+			}
+
+			b.WriteString(fmt.Sprintf("    %s %s--o{ %s : %q\n", relation.to, cardinality, relation.from, relation.column))
+		}
+	}
+
+	return b.String()
+}
+
+func (d diagram) dot() string {
+	var b strings.Builder
+
+	b.WriteString("digraph ER {\n")
+	b.WriteString("    graph [rankdir=LR];\n")
+	b.WriteString("    node [shape=plaintext];\n")
+
+	for _, schema := range d.schemas {
+		indent := "    "
+
+		if d.grouped {
+			b.WriteString(fmt.Sprintf("\n    subgraph %q {\n", "cluster_"+schema.Name))
+			b.WriteString(fmt.Sprintf("        label=%q;\n", schema.Name))
+			indent += "    "
+		}
+
+		for _, table := range schema.Tables {
+			b.WriteString(fmt.Sprintf("\n%s%q [label=<\n", indent, d.id(schema.Name, table.Name, ".")))
+			b.WriteString(indent + `    <table border="0" cellborder="1" cellspacing="0">` + "\n")
+			b.WriteString(fmt.Sprintf("%s        <tr><td colspan=\"2\" bgcolor=\"lightgrey\"><b>%s</b></td></tr>\n", indent, html.EscapeString(table.Name)))
+
+			for _, col := range table.Columns {
+				name := html.EscapeString(col.Name)
+
+				if keys := diagramKeys(table, col); len(keys) > 0 {
+					name = fmt.Sprintf("%s (%s)", name, strings.Join(keys, ", "))
+				}
+				if col.PrimaryKey {
+					name = "<u>" + name + "</u>"
+				}
+
+				b.WriteString(fmt.Sprintf(
+					"%s        <tr><td port=%q align=\"left\">%s</td><td align=\"left\">%s</td></tr>\n",
+					indent,
+					col.Name,
+					name,
+					html.EscapeString(diagramType(col)),
+				))
+			}
+
+			b.WriteString(indent + "    </table>\n")
+			b.WriteString(indent + ">];\n")
+		}
+
+		if d.grouped {
+			b.WriteString("    }\n")
+		}
+	}
+
+	var edges []string
+
+	for _, schema := range d.schemas {
+		for _, relation := range d.relations(schema, ".") {
+			style := ""
+
+			if relation.nullable {
+				style = " [style=dashed]"
+			}
+
+			edges = append(edges, fmt.Sprintf(
+				"    %q:%q -> %q:%q%s;\n",
+				relation.from,
+				relation.column,
+				relation.to,
+				relation.target,
+				style,
+			))
+		}
+	}
+
+	if len(edges) > 0 {
+		b.WriteString("\n" + strings.Join(edges, ""))
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+func (d diagram) plantUML() string {
+	var b strings.Builder
+
+	b.WriteString("@startuml\n")
+	b.WriteString("hide circle\n")
+	b.WriteString("skinparam linetype ortho\n")
+
+	for _, schema := range d.schemas {
+		indent := ""
+
+		if d.grouped {
+			b.WriteString(fmt.Sprintf("\npackage %q {\n", schema.Name))
+			indent = "  "
+		}
+
+		for _, table := range schema.Tables {
+			b.WriteString(fmt.Sprintf("\n%sentity %q as %s {\n", indent, table.Name, d.id(schema.Name, table.Name, "_")))
+
+			var keys, others []string
+
+			for _, col := range table.Columns {
+				line := fmt.Sprintf("%s  ", indent)
+
+				// Mandatory columns are marked with a star as is customary in
+				// plantuml entity diagrams
+				if !col.Nullable {
+					line += "* "
+				}
+
+				line += fmt.Sprintf("%s : %s", col.Name, diagramType(col))
+
+				for _, key := range diagramKeys(table, col) {
+					line += fmt.Sprintf(" <<%s>>", key)
+				}
+
+				if col.PrimaryKey {
+					keys = append(keys, line+"\n")
+				} else {
+					others = append(others, line+"\n")
+				}
+			}
+
+			b.WriteString(strings.Join(keys, ""))
+
+			if len(keys) > 0 && len(others) > 0 {
+				b.WriteString(indent + "  --\n")
+			}
+
+			b.WriteString(strings.Join(others, ""))
+			b.WriteString(indent + "}\n")
+		}
+
+		if d.grouped {
+			b.WriteString("}\n")
+		}
+	}
+
+	var relations []string
+
+	for _, schema := range d.schemas {
+		for _, relation := range d.relations(schema, "_") {
+			cardinality := "||"
+
+			if relation.nullable {
+				cardinality = "|o"
+			}
+
+			relations = append(relations, fmt.Sprintf("%s %s--o{ %s\n", relation.to, cardinality, relation.from))
+		}
+	}
+
+	if len(relations) > 0 {
+		b.WriteString("\n" + strings.Join(relations, ""))
+	}
+
+	b.WriteString("@enduml\n")
+
+	return b.String()
+}
+
+func (d diagram) dbml() string {
+	var b strings.Builder
+
+	for _, schema := range d.schemas {
+		for _, table := range schema.Tables {
+			if b.Len() > 0 {
+				b.WriteString("\n")
+			}
+
+			b.WriteString(fmt.Sprintf("Table %s {\n", d.id(schema.Name, table.Name, ".")))
+
+			for _, col := range table.Columns {
+				var settings []string
+
+				if col.PrimaryKey {
+					settings = append(settings, "pk")
+				}
+				if !col.Nullable && !col.PrimaryKey {
+					settings = append(settings, "not null")
+				}
+				if col.Comment != "" {
+					settings = append(settings, "note: "+dbmlString(col.Comment))
+				}
+
+				dataType := diagramType(col)
+
+				if strings.Contains(dataType, " ") {
+					dataType = fmt.Sprintf("%q", dataType)
+				}
+
+				b.WriteString(fmt.Sprintf("  %s %s", col.Name, dataType))
+
+				if len(settings) > 0 {
+					b.WriteString(" [" + strings.Join(settings, ", ") + "]")
+				}
+
+				b.WriteString("\n")
+			}
+
+			if table.Comment != "" {
+				b.WriteString(fmt.Sprintf("\n  Note: %s\n", dbmlString(table.Comment)))
+			}
+
+			b.WriteString("}\n")
+		}
+	}
+
+	for _, schema := range d.schemas {
+		for _, relation := range d.relations(schema, ".") {
+			b.WriteString(fmt.Sprintf(
+				"\nRef: %s.%s > %s.%s\n",
+				relation.from,
+				relation.column,
+				relation.to,
+				relation.target,
+			))
+		}
+	}
+
+	if d.grouped {
+		for _, schema := range d.schemas {
+			b.WriteString(fmt.Sprintf("\nTableGroup %s {\n", schema.Name))
+
+			for _, table := range schema.Tables {
+				b.WriteString(fmt.Sprintf("  %s\n", d.id(schema.Name, table.Name, ".")))
+			}
+
+			b.WriteString("}\n")
+		}
+	}
+
+	return b.String()
+}
+
+// dbmlString returns s as a single quoted dbml string
+func dbmlString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "'", `\'`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+
+	return "'" + s + "'"
+}
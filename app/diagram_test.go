@@ -0,0 +1,130 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFilterTables(t *testing.T) {
+	var err error
+
+	tables := []Table{{Name: "user_profile"}, {Name: "user_role"}, {Name: "phone"}}
+
+	filtered, err := FilterTables(tables, []string{"user_*", "phone"}, []string{"*_role"})
+
+	if err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	if len(filtered) != 2 || filtered[0].Name != "user_profile" || filtered[1].Name != "phone" {
+		t.Fatalf("expected user_profile and phone; got %+v\n", filtered)
+	}
+
+	if filtered, _ = FilterTables(tables, nil, nil); len(filtered) != 3 {
+		t.Fatalf("expected every table; got %+v\n", filtered)
+	}
+
+	if _, err = FilterTables(tables, []string{"["}, nil); err == nil {
+		t.Fatalf("should have error\n")
+	}
+}
+
+func TestRenderDiagram(t *testing.T) {
+	var err error
+
+	tables := []Table{
+		{
+			Name: "user_profile",
+			Columns: []Column{
+				{Name: "id", DataType: "int8", PrimaryKey: true},
+				{Name: "email", DataType: "varchar", MaxLength: 255, Nullable: true, Comment: "Login \"email\""},
+			},
+		},
+		{
+			Name: "phone",
+			Columns: []Column{
+				{Name: "id", DataType: "int4", PrimaryKey: true},
+				{Name: "user_profile_id", DataType: "int8"},
+				{Name: "account_id", DataType: "int8", Nullable: true},
+			},
+			ForeignKeys: []ForeignKey{
+				{ColumnName: "user_profile_id", ForeignTableName: "user_profile"},
+				{ColumnName: "account_id", ForeignTableName: "account"},
+			},
+		},
+	}
+
+	if _, err = RenderDiagram(nil, "svg"); !errors.Is(err, ErrInvalidDiagramFormat) {
+		t.Fatalf("error should contain '%v'; got '%v'\n", ErrInvalidDiagramFormat, err)
+	}
+
+	content, err := RenderDiagram([]DiagramSchema{{Name: "public", Tables: tables}}, MermaidDiagramFormat)
+
+	if err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	// Foreign keys to tables left out of the diagram aren't drawn
+	expected := "erDiagram\n" +
+		"    user_profile {\n" +
+		"        int8 id PK\n" +
+		"        varchar email \"Login 'email'\"\n" +
+		"    }\n" +
+		"    phone {\n" +
+		"        int4 id PK\n" +
+		"        int8 user_profile_id FK\n" +
+		"        int8 account_id FK\n" +
+		"    }\n" +
+		"    user_profile ||--o{ phone : \"user_profile_id\"\n"
+
+	if content != expected {
+		t.Fatalf("expected mermaid output:\n%s\ngot:\n%s\n", expected, content)
+	}
+
+	schemas := []DiagramSchema{
+		{Name: "public", Tables: tables},
+		{Name: "audit", Tables: []Table{{
+			Name:    "log",
+			Columns: []Column{{Name: "id", DataType: "int8"}, {Name: "user_email", DataType: "varchar"}},
+			ExternalForeignKeys: []ForeignKey{
+				{ColumnName: "user_email", ForeignSchema: "public", ForeignTableName: "user_profile", ForeignColumnName: "email"},
+			},
+		}}},
+	}
+
+	for format, contains := range map[DiagramFormat][]string{
+		MermaidDiagramFormat: {
+			"    audit__log {\n",
+			"    public__user_profile ||--o{ public__phone : \"user_profile_id\"\n",
+		},
+		DotDiagramFormat: {
+			"    subgraph \"cluster_audit\" {\n        label=\"audit\";\n",
+			"<tr><td port=\"id\" align=\"left\"><u>id (PK)</u></td><td align=\"left\">int8</td></tr>",
+			"<td align=\"left\">varchar(255)</td>",
+			"    \"public.phone\":\"user_profile_id\" -> \"public.user_profile\":\"id\";\n",
+		},
+		PlantUMLDiagramFormat: {
+			"package \"public\" {\n\n  entity \"user_profile\" as public_user_profile {\n" +
+				"    * id : int8 <<PK>>\n    --\n    email : varchar(255)\n  }\n",
+			"public_user_profile ||--o{ public_phone\npublic_user_profile ||--o{ audit_log\n@enduml\n",
+		},
+		DBMLDiagramFormat: {
+			"Table public.user_profile {\n  id int8 [pk]\n  email varchar(255) [note: 'Login \"email\"']\n}\n",
+			"  user_profile_id int8 [not null]\n",
+			"Ref: public.phone.user_profile_id > public.user_profile.id\n",
+			"TableGroup audit {\n  audit.log\n}\n",
+			"Ref: audit.log.user_email > public.user_profile.email\n",
+		},
+	} {
+		if content, err = RenderDiagram(schemas, format); err != nil {
+			t.Fatalf("should not have error; %s\n", err.Error())
+		}
+
+		for _, s := range contains {
+			if !strings.Contains(content, s) {
+				t.Fatalf("expected %s diagram to contain '%s'; got:\n%s\n", format, s, content)
+			}
+		}
+	}
+}
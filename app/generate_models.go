@@ -1,22 +1,18 @@
 package app
 
 import (
-	"bufio"
 	"fmt"
-	"io"
-	"io/fs"
 	"os"
 	"path/filepath"
-	"regexp"
+	"sort"
 	"strings"
-	"unicode"
 
 	"github.com/pkg/errors"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/kenshaw/snaker"
 	"gorm.io/gen"
-	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 )
 
 const (
@@ -32,249 +28,604 @@ var (
 	ErrQueryTableNames  = errors.New("model-gen: query table name error")
 	ErrQueryColumnNames = errors.New("model-gen: query column name error")
 	ErrQueryForeignKeys = errors.New("model-gen: query foreign key error")
+	ErrGenerateCode     = errors.New("model-gen: generate code error")
 )
 
 type GenExecutor interface {
 	Execute()
 	ApplyBasic(...interface{})
 	GenerateModel(string, ...gen.ModelOpt) interface{}
+	GenerateModelAs(string, string, ...gen.ModelOpt) interface{}
 }
 
 type GenerateConfig struct {
 	OutFile    string
 	SingleFile string
+
+	// Model is the config go models are generated with so generated types use
+	// the same names and types as the json of the go models
+	Model ModelConfig
+
+	// NullMode decides how nullable columns are represented in typescript,
+	// TsNullModeNull by default
+	NullMode TsNullMode
+
+	// Mode decides whether interfaces or zod schemas are generated,
+	// TsModeInterface by default
+	Mode TsMode
+
+	// PerTable generates a file per table and an index.ts barrel instead of a
+	// single file
+	PerTable bool
+
+	// Types picks the typescript type of timestamp, bigint, numeric and json columns
+	Types TsTypes
+
+	// BrandedIDs generates a branded id type per table with a single column
+	// primary key, eg. "UserId", used by the primary key and every foreign key
+	// referencing it
+	BrandedIDs bool
+
+	// Package is the package or namespace generated files declare in languages
+	// that have one, eg. "com.acme.models" for kotlin
+	Package string
+
+	// LiteralEnums generates Literal types instead of enum classes for enum
+	// columns in python
+	LiteralEnums bool
+
+	// FromRow derives sqlx::FromRow for rust structs and sqlx::Type for their enums
+	FromRow bool
+
+	// Classes generates classes with settable properties instead of positional
+	// records in c#
+	Classes bool
+
+	// GraphQLTables overrides how the graphql types of single tables are
+	// generated keyed by table name
+	GraphQLTables map[string]GraphQLTableConfig
+
+	// Title and Version are the info of generated openapi documents
+	Title   string
+	Version string
+
+	// idTypes holds the branded id type of every table, set by GenerateTsModels
+	idTypes map[string]string
+
+	// cyclicTables holds the tables whose relations lead back to themselves, set
+	// by GenerateTsModels
+	cyclicTables map[string]bool
 }
 
-type foreignKey struct {
-	ColumnName       string
-	ForeignTableName string
+// ModelConfig holds options that alter how go models are generated
+type ModelConfig struct {
+	// Driver is the database driver the models are generated from, which decides
+	// the go types gorm/gen picks for columns
+	Driver DBDriver
+
+	// TypeMap maps database types to the types used in generated models
+	//
+	// Unconditional mappings are expected to be passed to gorm/gen's WithDataTypeMap
+	// already so only conditional mappings are applied by GenerateModels
+	TypeMap TypeMap
+
+	// Tables overrides how individual tables are generated keyed by table name
+	Tables map[string]TableConfig
+
+	// Tags are the struct tags generated for every column and relation field
+	//
+	// DefaultTagGenerators is used when no tag generators are given
+	Tags []TagGenerator
+
+	// ValidateTags adds a go-playground/validator "validate" tag built from the
+	// column constraints if none of the tag generators already generate one
+	ValidateTags bool
+
+	// Nullable decides the go type of nullable columns, applied by ApplyNullableTypes
+	Nullable NullableConfig
+
+	// IDTypes holds the typed id of every table keyed by table name, used by the
+	// primary key and every foreign key referencing it
+	//
+	// The ids are loaded by LoadIDTypes and declared by WriteIDTypes
+	IDTypes map[string]IDType
 }
 
-type tableColumn struct {
-	ColumnName string
-	DataType   string
+// TableConfig overrides how a single table is generated
+type TableConfig struct {
+	// StructName overrides the name of the generated struct
+	StructName string
+
+	// Columns overrides how individual columns are generated keyed by column name
+	Columns map[string]ColumnConfig
 }
 
-func GenerateModels(g GenExecutor, gormDB *gorm.DB, driver DBDriver, schema string) error {
-	var err error
-	var tableNames []string
+// ColumnConfig overrides how a single column is generated
+type ColumnConfig struct {
+	// GoType overrides the type of the generated field
+	GoType string
 
-	if driver == PostgresDriver && schema == "" {
-		return ErrMustSetSchema
-	}
+	// GoImport is the import path GoType requires, if any
+	GoImport string
 
-	if err = gormDB.Raw(
-		getTableNamesQuery(driver, schema),
-	).Scan(&tableNames).Error; err != nil {
-		return fmt.Errorf(packageErr, ErrQueryTableNames, err.Error())
+	// FieldName overrides the name of the generated field
+	FieldName string
+
+	// Tags adds extra struct tags to the generated field keyed by tag name
+	Tags map[string]string
+
+	// JSONName overrides the value of the json tag
+	JSONName string
+
+	// JSONOmit hides the field from json entirely
+	JSONOmit bool
+}
+
+// GoImports returns every import path the type map and table overrides require
+func (cfg ModelConfig) GoImports(driver DBDriver) []string {
+	imports := cfg.TypeMap.GoImports(driver)
+	seen := make(map[string]bool)
+
+	for _, path := range imports {
+		seen[path] = true
 	}
 
-	for _, tableName := range tableNames {
-		var fks []foreignKey
-		var opts []gen.ModelOpt
-		var cols []tableColumn
+	for _, tableName := range sortedKeys(cfg.Tables) {
+		table := cfg.Tables[tableName]
 
-		if err = gormDB.Raw(
-			getColumnNameQuery(driver, schema, tableName),
-		).Scan(&cols).Error; err != nil {
-			return fmt.Errorf(packageErr, ErrQueryColumnNames, err.Error())
+		for _, columnName := range sortedKeys(table.Columns) {
+			if path := table.Columns[columnName].GoImport; path != "" && !seen[path] {
+				seen[path] = true
+				imports = append(imports, path)
+			}
 		}
+	}
 
-		for _, col := range cols {
-			opts = append(
-				opts,
-				gen.FieldNewTag(col.ColumnName, `db:"`+col.ColumnName+`"`),
-				gen.FieldJSONTag(col.ColumnName, snaker.ForceLowerCamelIdentifier(col.ColumnName)),
-			)
-		}
+	return imports
+}
 
-		if err = gormDB.Raw(
-			getForeignKeyQuery(driver, schema, tableName),
-		).Scan(&fks).Error; err != nil {
-			return fmt.Errorf(packageErr, ErrQueryForeignKeys, err.Error())
-		}
+// tagGenerators returns the configured tag generators or the default ones
+func (cfg ModelConfig) tagGenerators() []TagGenerator {
+	tags := cfg.Tags
 
-		for _, fk := range fks {
-			columnName := fk.ColumnName[:len(fk.ColumnName)-3]
-			fieldName := snaker.SnakeToCamel(columnName)
-			opts = append(
-				opts,
-				gen.FieldNew(
-					fieldName,
-					"*"+snaker.SnakeToCamel(fk.ForeignTableName),
-					`db:"`+columnName+`" json:"`+snaker.ForceLowerCamelIdentifier(columnName)+`"`,
-				),
-			)
+	if len(tags) == 0 {
+		tags = DefaultTagGenerators()
+	}
+
+	if !cfg.ValidateTags {
+		return tags
+	}
+
+	for _, tg := range tags {
+		if tg.Name == "validate" {
+			return tags
 		}
+	}
+
+	return append(tags[:len(tags):len(tags)], TagGenerator{Name: "validate"})
+}
 
-		g.ApplyBasic(g.GenerateModel(tableName, opts...))
+// jsonTag returns the value of the json tag of the field generated for col of
+// tableName, empty if the field has no json tag
+func (cfg ModelConfig) jsonTag(tableName string, col Column) string {
+	colCfg := cfg.Tables[tableName].Columns[col.Name]
+
+	switch tag, ok := colCfg.Tags["json"]; {
+	case colCfg.JSONOmit:
+		return "-"
+	case ok:
+		return tag
+	case colCfg.JSONName != "":
+		return colCfg.JSONName
 	}
 
-	g.Execute()
-	return nil
+	for _, tg := range cfg.tagGenerators() {
+		if tg.Name == "json" {
+			return tg.Value(col)
+		}
+	}
+
+	return ""
 }
 
-func GenerateTsModels(goModelDir, goOutFile, tsDir, tsFile, tsOutFile string, cfg GenerateConfig) error {
-	if tsDir == "" {
-		return errors.WithStack(fmt.Errorf("model-gen: tsDir parameter can't be empty"))
+// jsonField returns the json name of the field generated for col of tableName,
+// whether it is omitted when empty and false if the field is hidden from json
+func (cfg ModelConfig) jsonField(tableName string, col Column) (string, bool, bool) {
+	tag := strings.Split(cfg.jsonTag(tableName, col), ",")
+
+	if tag[0] == "-" && len(tag) == 1 {
+		return "", false, false
 	}
-	if tsFile == "" {
-		return errors.WithStack(fmt.Errorf("model-gen: tsFile parameter can't be empty"))
+
+	name := tag[0]
+
+	// Fields without a json name are encoded with their field name
+	if name == "" {
+		name = cfg.Tables[tableName].Columns[col.Name].FieldName
 	}
-	if tsOutFile == "" {
-		return errors.WithStack(fmt.Errorf("model-gen: tsOutFile parameter can't be empty"))
+	if name == "" {
+		name = snaker.ForceCamelIdentifier(col.Name)
 	}
 
-	strConv := []string{
-		"Int64",
-		"int64",
-		"float64",
-		"string",
+	return name, hasOption(tag[1:], "omitempty"), true
+}
+
+// relationName returns the snake case name of the relation field generated for
+// fk of table, which every target names its relation after
+//
+// The name is the foreign key column without its "_id" suffix, eg. "owner_id"
+// into "owner".  Columns without the suffix, or whose trimmed name is taken by
+// another column, get the name of the referenced struct appended so the relation
+// doesn't collide with the column, eg. "owner" into "owner_user"
+func (cfg ModelConfig) relationName(table Table, fk ForeignKey) string {
+	name := strings.TrimSuffix(fk.ColumnName, "_id")
+
+	// Columns without the suffix find themselves here
+	if table.column(name) != nil {
+		name += "_" + snaker.CamelToSnake(cfg.structName(fk.ForeignTableName))
 	}
 
-	numConv := []string{
-		"int8",
-		"int16",
-		"int",
-		"int32",
-		"float32",
+	return name
+}
+
+// relationJSONField returns the json name of the relation field generated for fk
+// of table and whether it is omitted when empty
+func (cfg ModelConfig) relationJSONField(table Table, fk ForeignKey) (string, bool) {
+	columnName := cfg.relationName(table, fk)
+
+	for _, tg := range cfg.tagGenerators() {
+		if tg.Name == "json" {
+			tag := strings.Split(tg.Value(Column{Name: columnName, Nullable: true}), ",")
+			return tag[0], hasOption(tag[1:], "omitempty")
+		}
 	}
 
-	space := regexp.MustCompile(`\s+`)
+	return snaker.SnakeToCamel(columnName), false
+}
 
-	var err error
+// structName returns the name of the struct generated for tableName
+//
+// gorm/gen names structs with the default naming strategy of gorm, which
+// singularizes the table name, eg. "users" into "User"
+func (cfg ModelConfig) structName(tableName string) string {
+	if name := cfg.Tables[tableName].StructName; name != "" {
+		return name
+	}
 
-	if err = os.MkdirAll(tsDir, os.ModePerm); err != nil {
-		return errors.WithStack(err)
+	return schema.NamingStrategy{}.SchemaName(tableName)
+}
+
+// fieldName returns the name of the field generated for columnName of tableName
+//
+// gorm/gen names fields with the naming strategy of gorm, renamed ones included,
+// eg. "user_id" into "UserID"
+func (cfg ModelConfig) fieldName(tableName, columnName string) string {
+	name := columnName
+
+	if fieldName := cfg.Tables[tableName].Columns[columnName].FieldName; fieldName != "" {
+		name = fieldName
 	}
 
-	newFile, err := os.Create(filepath.Join(tsDir, tsFile) + "." + tsOutFile)
+	return schema.NamingStrategy{SingularTable: true}.SchemaName(name)
+}
 
-	if err != nil {
-		return errors.WithStack(err)
+// relationGormTag returns the gorm tag of the relation field generated for fk of
+// table, which references foreign
+//
+// gorm only works out the fields a relation joins on when the foreign key is
+// named after the relation with an "ID" suffix and references the primary key so
+// both are always named
+func (cfg ModelConfig) relationGormTag(table Table, fk ForeignKey, foreign Table) string {
+	references := fk.ForeignColumnName
+
+	if references == "" {
+		references = referencedColumn(foreign)
 	}
 
-	defer newFile.Close()
+	return fmt.Sprintf(
+		`gorm:"foreignKey:%s;references:%s"`,
+		cfg.fieldName(table.Name, fk.ColumnName),
+		cfg.fieldName(fk.ForeignTableName, references),
+	)
+}
 
-	return filepath.Walk(goModelDir, func(path string, info fs.FileInfo, err error) error {
-		if !info.IsDir() {
-			if !strings.HasSuffix(info.Name(), goOutFile) {
-				return nil
-			}
+// enumName returns the name of the enum type generated for col of tableName in
+// languages with enums, eg. "ProductStatus"
+func (cfg ModelConfig) enumName(tableName string, col Column) string {
+	return cfg.structName(tableName) + snaker.SnakeToCamel(col.Name)
+}
+
+// GenerateModels generates a go model for every table loaded by LoadSchema
+func GenerateModels(g GenExecutor, tables []Table, driver DBDriver, cfg ModelConfig) (err error) {
+	// gorm/gen panics when it fails to generate or write code so recover and
+	// return it as an error to let the caller roll back any staged output
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf(packageErr, ErrGenerateCode, fmt.Sprint(r))
+		}
+	}()
+
+	tagGenerators := cfg.tagGenerators()
+	tablesByName := make(map[string]Table, len(tables))
+
+	for _, table := range tables {
+		tablesByName[table.Name] = table
+	}
+
+	for _, table := range tables {
+		var opts []gen.ModelOpt
+
+		tableCfg := cfg.Tables[table.Name]
 
-			openFile, err := os.Open(path)
+		for _, col := range table.Columns {
+			colCfg := tableCfg.Columns[col.Name]
+			goType := colCfg.GoType
+			m, mapped, err := cfg.TypeMap.Lookup(driver, col.DataType, col.Nullable, col.Name)
 
 			if err != nil {
-				return errors.WithStack(err)
+				return err
 			}
 
-			defer openFile.Close()
+			mapped = mapped && m.GoType != ""
 
-			newFileWriter := bufio.NewWriter(newFile)
-			openFileReader := bufio.NewReader(openFile)
+			// Unconditional mappings are already applied through WithDataTypeMap
+			if goType == "" && mapped && m.conditional() {
+				goType = m.GoType
+			}
+
+			// validator applies numeric rules to the length of strings so they are
+			// left out unless the field ends up with a numeric go type
+			numericField := col.kind() == integerKind || col.kind() == decimalKind || col.kind() == floatKind
+
+			id, isID := cfg.idType(table, col)
+
+			switch {
+			case isID:
+				numericField = isNumericGoType(id.Base)
+			case colCfg.GoType != "":
+				numericField = isNumericGoType(colCfg.GoType)
+			case mapped:
+				numericField = isNumericGoType(m.GoType)
+			case driver == SqliteDriver && col.NumericPrecision > 0:
+				// gorm/gen maps sqlite types declared with a precision to strings
+				numericField = false
+			}
 
-			withinStruct := false
+			tagCol := col
 
-			for {
-				l, err := openFileReader.ReadString('\n')
+			if !numericField {
+				tagCol.NumericPrecision = 0
+				tagCol.Checks = nil
+			}
 
-				if err != nil {
-					if err == io.EOF {
-						break
-					}
+			for _, tg := range tagGenerators {
+				if _, ok := colCfg.Tags[tg.Name]; ok || tg.Name == "json" {
+					continue
+				}
 
-					return errors.WithStack(err)
+				if tag := tg.Tag(tagCol); tag != "" {
+					opts = append(opts, gen.FieldNewTag(col.Name, tag))
 				}
+			}
 
-				ajustedLine := strings.TrimSpace(space.ReplaceAllString(l, " "))
+			// gorm/gen gives every field a json tag of the column name unless
+			// it is explicitly cleared
+			jsonTag := ""
 
-				if strings.Contains(ajustedLine, " struct {") {
-					structArr := strings.Split(ajustedLine, " ")
-					newFileWriter.WriteString(fmt.Sprintf("export interface %s {\n", structArr[1]))
-					withinStruct = true
+			if _, ok := colCfg.Tags["json"]; !ok || colCfg.JSONOmit {
+				jsonTag = cfg.jsonTag(table.Name, col)
+			}
+
+			opts = append(opts, gen.FieldJSONTag(col.Name, jsonTag))
+
+			for _, tagName := range sortedKeys(colCfg.Tags) {
+				if tagName == "json" && colCfg.JSONOmit {
 					continue
 				}
 
-				if strings.TrimSpace(ajustedLine) == "}" && withinStruct {
-					newFileWriter.WriteString("}\n\n")
-					break
+				opts = append(opts, gen.FieldNewTag(col.Name, tagName+`:"`+colCfg.Tags[tagName]+`"`))
+			}
+
+			// gorm/gen only adds pointers to the types it predicts itself
+			if isID {
+				goType = id.Name
+
+				if col.Nullable && cfg.Nullable.Pointers() {
+					goType = "*" + goType
 				}
+			}
+
+			if goType != "" {
+				opts = append(opts, gen.FieldType(col.Name, goType))
+			}
 
-				if withinStruct {
-					lineArr := strings.Split(ajustedLine, " ")
+			if colCfg.FieldName != "" {
+				opts = append(opts, gen.FieldRename(col.Name, colCfg.FieldName))
+			}
 
-					var fieldType, fieldName string
+			if col.Comment != "" {
+				opts = append(opts, gen.FieldComment(col.Name, col.Comment))
+			}
+		}
 
-					if snaker.IsInitialism(lineArr[0]) {
-						fieldName = strings.ToLower(lineArr[0])
-					} else {
-						fn := []rune(lineArr[0])
-						fn[0] = unicode.ToLower(fn[0])
-						fieldName = string(fn)
-					}
+		for _, fk := range table.ForeignKeys {
+			tags := []string{cfg.relationGormTag(table, fk, tablesByName[fk.ForeignTableName])}
+			columnName := cfg.relationName(table, fk)
+			fieldName := snaker.SnakeToCamel(columnName)
 
-					if lineArr[1][0] == '*' {
-						fieldType = lineArr[1][1:len(lineArr[1])]
-					}
+			for _, tg := range tagGenerators {
+				if tag := tg.relationTag(columnName); tag != "" {
+					tags = append(tags, tag)
+				}
+			}
 
-					for _, v := range strConv {
-						if strings.Contains(lineArr[1], v) {
-							//fmt.Printf("string type: %s\n conv type: %s\n", lineArr[1], v)
-							fieldType = "string"
-						}
-					}
+			opts = append(
+				opts,
+				gen.FieldNew(
+					fieldName,
+					"*"+cfg.structName(fk.ForeignTableName),
+					strings.Join(tags, " "),
+				),
+			)
+		}
 
-					for _, v := range numConv {
-						if strings.Contains(lineArr[1], v) {
-							fieldType = "number"
-						}
-					}
+		if tableCfg.StructName != "" {
+			g.ApplyBasic(g.GenerateModelAs(table.Name, tableCfg.StructName, opts...))
+		} else {
+			g.ApplyBasic(g.GenerateModel(table.Name, opts...))
+		}
+	}
 
-					if lineArr[1] == "bool" || lineArr[1] == "*bool" {
-						fieldType = "boolean"
-					}
+	g.Execute()
+	return nil
+}
 
-					//fmt.Printf("field type gettign hheeeeeer: %s\n", fieldType)
+// ApplyTableComments replaces the struct comment gorm/gen writes for every table
+// with a comment in modelDir with the table's comment
+//
+// gorm/gen has no option to set struct comments so the generated files are
+// rewritten instead
+func ApplyTableComments(modelDir string, tables []Table, cfg ModelConfig) error {
+	replacements := make(map[string]string)
+
+	for _, table := range tables {
+		if table.Comment == "" {
+			continue
+		}
 
-					if fieldType == "" {
-						fieldType = lineArr[1]
-					}
+		structName := cfg.structName(table.Name)
+		genComment := fmt.Sprintf("// %s mapped from table <%s>\n", structName, table.Name)
+		lines := strings.Split(strings.TrimSpace(table.Comment), "\n")
 
-					newLine := fmt.Sprintf("\t%s?: %s\n", fieldName, fieldType)
+		// Comments already written as go doc, eg. "User is a person", keep their wording
+		if first := strings.Fields(lines[0]); len(first) == 0 || first[0] != structName {
+			lines[0] = structName + " " + lines[0]
+		}
 
-					if _, err = newFileWriter.WriteString(newLine); err != nil {
-						return errors.WithStack(err)
-					}
-				}
-			}
+		var comment strings.Builder
 
-			if err = newFileWriter.Flush(); err != nil {
-				return errors.WithStack(err)
-			}
+		for _, line := range lines {
+			comment.WriteString(strings.TrimRight("// "+line, " ") + "\n")
 		}
 
+		comment.WriteString(fmt.Sprintf("//\n// mapped from table <%s>\n", table.Name))
+		replacements[genComment] = comment.String()
+	}
+
+	if len(replacements) == 0 {
 		return nil
-	})
+	}
+
+	files, err := filepath.Glob(filepath.Join(modelDir, "*.go"))
+
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		newContent := string(content)
+
+		for genComment, comment := range replacements {
+			newContent = strings.Replace(newContent, genComment, comment, 1)
+		}
+
+		if newContent == string(content) {
+			continue
+		}
+
+		if err = os.WriteFile(file, []byte(newContent), 0644); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	return nil
 }
 
-func RemoveGenDirs(queryOutPath, modelOutPath string) error {
-	var err error
+// RemoveGenDirs removes the generated files recorded in manifest that live within
+// queryOutPath or modelOutPath
+//
+// Only files that are unmodified since they were generated are removed, so any
+// hand written code that lives next to generated code is left alone.  Generated
+// files that were edited are kept and returned so the caller can warn about them
+func RemoveGenDirs(manifest *Manifest, queryOutPath, modelOutPath string) ([]string, error) {
+	var modified []string
 
 	if queryOutPath == "" {
-		queryOutPath = "./query"
+		queryOutPath = DefaultQueryOutPath
 	}
 	if modelOutPath == "" {
-		modelOutPath = "./model"
+		modelOutPath = DefaultModelOutPath
 	}
 
-	if err = os.RemoveAll(queryOutPath); err != nil {
-		return errors.WithStack(err)
+	for _, dir := range []string{queryOutPath, modelOutPath} {
+		files, err := manifest.FilesWithin(dir)
+
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		for _, file := range files {
+			unmodified, err := manifest.Unmodified(file)
+
+			if err != nil {
+				if os.IsNotExist(errors.Cause(err)) {
+					manifest.Forget(file)
+					continue
+				}
+
+				return nil, errors.WithStack(err)
+			}
+
+			if !unmodified {
+				modified = append(modified, file)
+				continue
+			}
+
+			if err = os.Remove(file); err != nil {
+				return nil, errors.WithStack(err)
+			}
+
+			manifest.Forget(file)
+			removeEmptyDirs(filepath.Dir(file), dir)
+		}
 	}
-	if err = os.RemoveAll(modelOutPath); err != nil {
-		return errors.WithStack(err)
+
+	return modified, nil
+}
+
+// removeEmptyDirs removes dir and its parents up to and including root as long as
+// they are empty
+func removeEmptyDirs(dir, root string) {
+	absRoot, err := filepath.Abs(root)
+
+	if err != nil {
+		return
 	}
 
-	return nil
+	for {
+		absDir, err := filepath.Abs(dir)
+
+		if err != nil {
+			return
+		}
+
+		if rel, err := filepath.Rel(absRoot, absDir); err != nil || strings.HasPrefix(rel, "..") {
+			return
+		}
+
+		// os.Remove refuses to remove directories that are not empty
+		if err = os.Remove(absDir); err != nil || absDir == absRoot {
+			return
+		}
+
+		dir = filepath.Dir(absDir)
+	}
 }
 
 func getTableNamesQuery(driver DBDriver, schema string) string {
@@ -283,7 +634,11 @@ func getTableNamesQuery(driver DBDriver, schema string) string {
 		return fmt.Sprintf(
 			`
 			select
-				table_name
+				table_name,
+				coalesce(
+					obj_description((quote_ident(table_schema) || '.' || quote_ident(table_name))::regclass, 'pg_class'),
+					''
+				) as comment
 			from
 				information_schema.tables
 			where
@@ -294,14 +649,16 @@ func getTableNamesQuery(driver DBDriver, schema string) string {
 	case MysqlDriver:
 		return `
 		select
-			table_name
+			table_name as table_name,
+			table_comment as comment
 		from
 			information_schema.tables
 		`
 	default:
 		return `
 		select
-    		name
+    		name as table_name,
+			'' as comment
 		from
 			sqlite_schema
 		where
@@ -315,25 +672,33 @@ func getTableNamesQuery(driver DBDriver, schema string) string {
 func getForeignKeyQuery(driver DBDriver, schema, tableName string) string {
 	switch driver {
 	case PostgresDriver:
+		// The columns of both keys are matched by position so composite foreign
+		// keys don't pair every column with every referenced column
 		return fmt.Sprintf(
 			`
 			select
 				kcu.column_name,
-				ccu.table_name AS "foreign_table_name"
+				case
+					when rcu.table_schema = kcu.table_schema then ''
+					else rcu.table_schema
+				end as "foreign_schema",
+				rcu.table_name as "foreign_table_name",
+				rcu.column_name as "foreign_column_name"
 			from
-				information_schema.table_constraints AS tc
-				JOIN information_schema.key_column_usage AS kcu
-				ON tc.constraint_name = kcu.constraint_name
-				and tc.table_schema = kcu.table_schema
-				JOIN information_schema.constraint_column_usage AS ccu
-				ON ccu.constraint_name = tc.constraint_name
-				and ccu.table_schema = tc.table_schema
+				information_schema.referential_constraints as rc
+				join information_schema.key_column_usage as kcu
+				on kcu.constraint_schema = rc.constraint_schema
+				and kcu.constraint_name = rc.constraint_name
+				join information_schema.key_column_usage as rcu
+				on rcu.constraint_schema = rc.unique_constraint_schema
+				and rcu.constraint_name = rc.unique_constraint_name
+				and rcu.ordinal_position = kcu.position_in_unique_constraint
 			where
-				tc.table_schema = '%s'
-			and
-				tc.constraint_type = 'FOREIGN KEY'
+				kcu.table_schema = '%s'
 			and
-				tc.table_name='%s';
+				kcu.table_name = '%s'
+			order by
+				kcu.ordinal_position;
 			`,
 			schema,
 			tableName,
@@ -342,14 +707,23 @@ func getForeignKeyQuery(driver DBDriver, schema, tableName string) string {
 		return fmt.Sprintf(
 			`
 			select
-				column_name,
-				referenced_table_name as "foreign_table_name"
+				column_name as column_name,
+				case
+					when referenced_table_schema = table_schema then ''
+					else referenced_table_schema
+				end as foreign_schema,
+				referenced_table_name as foreign_table_name,
+				referenced_column_name as foreign_column_name
 			from
-				information_schema.key_column_usgae
+				information_schema.key_column_usage
 			where
+				table_schema = database()
+			and
 				table_name = '%s'
 			and
-				referenced_table_name is not null;
+				referenced_table_name is not null
+			order by
+				ordinal_position;
 			`,
 			tableName,
 		)
@@ -358,7 +732,9 @@ func getForeignKeyQuery(driver DBDriver, schema, tableName string) string {
 			`
 			select
 				"from" as "column_name",
-				"table" as "foreign_table_name"
+				'' as "foreign_schema",
+				"table" as "foreign_table_name",
+				coalesce("to", '') as "foreign_column_name"
 			from
 				pragma_foreign_key_list('%s');
 			`,
@@ -374,13 +750,41 @@ func getColumnNameQuery(driver DBDriver, schema, tableName string) string {
 			`
 			select
 				column_name,
-				data_type
+				udt_name as data_type,
+				is_nullable = 'YES' as nullable,
+				column_default is not null or is_identity = 'YES' as has_default,
+				exists (
+					select
+						1
+					from
+						information_schema.table_constraints as tc
+						join information_schema.key_column_usage as kcu
+						on tc.constraint_name = kcu.constraint_name
+						and tc.table_schema = kcu.table_schema
+					where
+						tc.constraint_type = 'PRIMARY KEY'
+					and
+						tc.table_schema = c.table_schema
+					and
+						tc.table_name = c.table_name
+					and
+						kcu.column_name = c.column_name
+				) as primary_key,
+				character_maximum_length as max_length,
+				numeric_precision,
+				numeric_scale,
+				coalesce(
+					col_description((quote_ident(table_schema) || '.' || quote_ident(table_name))::regclass, ordinal_position),
+					''
+				) as comment
 			from
-				information_schema.columns
+				information_schema.columns as c
 			where
 				table_schema = '%s'
 			and
-				table_name   = '%s';
+				table_name   = '%s'
+			order by
+				ordinal_position;
 			`,
 			schema,
 			tableName,
@@ -389,12 +793,22 @@ func getColumnNameQuery(driver DBDriver, schema, tableName string) string {
 		return fmt.Sprintf(
 			`
 			select
-				column_name,
-				data_type
+				column_name as column_name,
+				data_type as data_type,
+				column_type as column_type,
+				is_nullable = 'YES' as nullable,
+				column_default is not null or extra like '%%auto_increment%%' as has_default,
+				column_key = 'PRI' as primary_key,
+				character_maximum_length as max_length,
+				numeric_precision as numeric_precision,
+				numeric_scale as numeric_scale,
+				column_comment as comment
 			from
 				information_schema.columns
 			where
-				table_name = '%s';
+				table_name = '%s'
+			order by
+				ordinal_position;
 			`,
 			tableName,
 		)
@@ -402,8 +816,12 @@ func getColumnNameQuery(driver DBDriver, schema, tableName string) string {
 		return fmt.Sprintf(
 			`
 			select
-				name,
-				data_type
+				name as column_name,
+				type as data_type,
+				"notnull" = 0 and pk = 0 as nullable,
+				dflt_value is not null or (pk = 1 and lower(type) = 'integer') as has_default,
+				pk > 0 as primary_key,
+				'' as comment
 			from
 				pragma_table_info('%s');
 			`,
@@ -411,3 +829,145 @@ func getColumnNameQuery(driver DBDriver, schema, tableName string) string {
 		)
 	}
 }
+
+// getCheckConstraintQuery returns the query selecting the definition of every
+// check constraint of tableName
+//
+// sqlite only keeps check constraints within the table's create statement so
+// they are not supported and an empty query is returned
+func getCheckConstraintQuery(driver DBDriver, schema, tableName string) string {
+	switch driver {
+	case PostgresDriver:
+		return fmt.Sprintf(
+			`
+			select
+				pg_get_constraintdef(c.oid)
+			from
+				pg_constraint c
+				JOIN pg_class t ON t.oid = c.conrelid
+				JOIN pg_namespace n ON n.oid = t.relnamespace
+			where
+				c.contype = 'c'
+			and
+				n.nspname = '%s'
+			and
+				t.relname = '%s';
+			`,
+			schema,
+			tableName,
+		)
+	case MysqlDriver:
+		return fmt.Sprintf(
+			`
+			select
+				cc.check_clause
+			from
+				information_schema.check_constraints AS cc
+				JOIN information_schema.table_constraints AS tc
+				ON tc.constraint_schema = cc.constraint_schema
+				and tc.constraint_name = cc.constraint_name
+			where
+				tc.constraint_type = 'CHECK'
+			and
+				tc.constraint_schema = database()
+			and
+				tc.table_name = '%s';
+			`,
+			tableName,
+		)
+	default:
+		return ""
+	}
+}
+
+// getCheckConstraintTableQuery returns the query counting the tables check
+// constraints are selected from
+//
+// mysql only has them from 8.0.16 on, every other driver always has them so an
+// empty query is returned
+func getCheckConstraintTableQuery(driver DBDriver) string {
+	switch driver {
+	case MysqlDriver:
+		return `
+			select
+				count(*)
+			from
+				information_schema.tables
+			where
+				table_schema = 'information_schema'
+			and
+				table_name = 'CHECK_CONSTRAINTS';
+			`
+	default:
+		return ""
+	}
+}
+
+// getEnumValueQuery returns the query selecting the values of every enum typed
+// column of tableName
+//
+// mysql declares enum values within the column type and sqlite has no enums so an
+// empty query is returned for both
+func getEnumValueQuery(driver DBDriver, schema, tableName string) string {
+	switch driver {
+	case PostgresDriver:
+		return fmt.Sprintf(
+			`
+			select
+				c.column_name,
+				e.enumlabel as enum_value
+			from
+				information_schema.columns AS c
+				JOIN pg_type t ON t.typname = c.udt_name
+				JOIN pg_namespace n ON n.oid = t.typnamespace
+				and n.nspname = c.udt_schema
+				JOIN pg_enum e ON e.enumtypid = t.oid
+			where
+				c.table_schema = '%s'
+			and
+				c.table_name = '%s'
+			order by
+				c.ordinal_position,
+				e.enumsortorder;
+			`,
+			schema,
+			tableName,
+		)
+	default:
+		return ""
+	}
+}
+
+// hasOption returns whether the tag options contain option
+func hasOption(options []string, option string) bool {
+	for _, o := range options {
+		if o == option {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isNumericGoType returns whether goType is one of go's built in number types
+func isNumericGoType(goType string) bool {
+	switch strings.TrimPrefix(goType, "*") {
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "float32", "float64":
+		return true
+	default:
+		return false
+	}
+}
+
+// sortedKeys returns the keys of m in sorted order so generated output is stable
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -1,135 +1,442 @@
 package app
 
 import (
-	"database/sql"
 	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 
-	"github.com/DATA-DOG/go-sqlmock"
-	"gorm.io/driver/postgres"
+	"github.com/kenshaw/snaker"
 	"gorm.io/gen"
-	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
 )
 
-type mockGenerator struct{}
+type mockGenerator struct {
+	structNames map[string]string
+}
 
 func (m *mockGenerator) Execute()                  {}
 func (m *mockGenerator) ApplyBasic(...interface{}) {}
 func (m *mockGenerator) GenerateModel(model string, opts ...gen.ModelOpt) interface{} {
 	return nil
 }
+func (m *mockGenerator) GenerateModelAs(model, structName string, opts ...gen.ModelOpt) interface{} {
+	if m.structNames == nil {
+		m.structNames = make(map[string]string)
+	}
+
+	m.structNames[model] = structName
+	return nil
+}
+
+type panicGenerator struct {
+	mockGenerator
+}
+
+func (p *panicGenerator) Execute() {
+	panic("generate model struct fail")
+}
 
 func TestGenerateModels(t *testing.T) {
 	var err error
-	var gormDB *gorm.DB
-	var db *sql.DB
-	var mockDB sqlmock.Sqlmock
-	var tableRows, userColumnRows, phoneForeignKeyRows, phoneColumnRows, userForeignKeyRows *sqlmock.Rows
 
-	initNewMockDB := func() {
-		var innerErr error
+	mockGen := &mockGenerator{}
+	tables := []Table{
+		{
+			Name: "user_profile",
+			Columns: []Column{
+				{Name: "id", DataType: "int4", HasDefault: true},
+				{Name: "name", DataType: "varchar", MaxLength: 255},
+			},
+		},
+		{
+			Name: "phone",
+			Columns: []Column{
+				{Name: "id", DataType: "int4", HasDefault: true},
+				{Name: "number", DataType: "text"},
+				{Name: "user_profile_id", DataType: "int4"},
+			},
+			ForeignKeys: []ForeignKey{
+				{ColumnName: "user_profile_id", ForeignTableName: "user_profile"},
+			},
+		},
+	}
 
-		if db != nil {
-			db.Close()
-		}
+	if err = GenerateModels(mockGen, tables, PostgresDriver, ModelConfig{ValidateTags: true}); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
 
-		db, mockDB, innerErr = sqlmock.New(sqlmock.QueryMatcherOption(sqlAnyMatcher))
+	if err = GenerateModels(mockGen, []Table{{Name: "tbl_usr", Columns: []Column{{Name: "password_hash"}}}}, PostgresDriver, ModelConfig{
+		Tables: map[string]TableConfig{
+			"tbl_usr": {
+				StructName: "User",
+				Columns: map[string]ColumnConfig{
+					"password_hash": {JSONOmit: true},
+				},
+			},
+		},
+	}); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
 
-		if innerErr != nil {
-			t.Fatalf(innerErr.Error())
-		}
+	if mockGen.structNames["tbl_usr"] != "User" {
+		t.Fatalf("table 'tbl_usr' should be generated as 'User'; got '%s'\n", mockGen.structNames["tbl_usr"])
+	}
 
-		if gormDB, innerErr = gorm.Open(postgres.New(postgres.Config{Conn: db})); innerErr != nil {
-			t.Fatalf(innerErr.Error())
-		}
+	if err = GenerateModels(&panicGenerator{}, nil, PostgresDriver, ModelConfig{}); err == nil {
+		t.Fatalf("should have error\n")
 	}
 
-	mockGen := &mockGenerator{}
+	if !errors.Is(err, ErrGenerateCode) {
+		t.Fatalf("should have error %v; got %v\n", ErrGenerateCode, err)
+	}
+}
 
-	initNewMockDB()
+func TestModelConfigTagGenerators(t *testing.T) {
+	tags := ModelConfig{ValidateTags: true}.tagGenerators()
 
-	if err = GenerateModels(mockGen, gormDB, PostgresDriver, ""); err == nil {
-		t.Fatalf("should have error\n")
+	if len(tags) != 3 || tags[2].Name != "validate" {
+		t.Fatalf("should append validate tag generator to defaults; got %v\n", tags)
 	}
 
-	if !errors.Is(err, ErrMustSetSchema) {
-		t.Fatalf("should have error %v; got %v\n", ErrMustSetSchema, err)
+	tags = ModelConfig{Tags: []TagGenerator{{Name: "validate"}}, ValidateTags: true}.tagGenerators()
+
+	if len(tags) != 1 {
+		t.Fatalf("should not append validate tag generator twice; got %v\n", tags)
 	}
+}
 
-	sqlErr := errors.New("error")
+func TestModelConfigStructName(t *testing.T) {
+	cfg := ModelConfig{Tables: map[string]TableConfig{"tbl_usr": {StructName: "Account"}}}
+
+	// Struct names must match the ones gorm/gen generates
+	for tableName, expected := range map[string]string{
+		"users":        "User",
+		"orders":       "Order",
+		"categories":   "Category",
+		"user_ids":     "UserID",
+		"user_profile": "UserProfile",
+		"tbl_usr":      "Account",
+	} {
+		if name := cfg.structName(tableName); name != expected {
+			t.Fatalf("expected struct name '%s' for '%s'; got '%s'\n", expected, tableName, name)
+		}
+	}
+}
 
-	mockDB.ExpectQuery("").WillReturnError(sqlErr)
+func TestApplyTableComments(t *testing.T) {
+	var err error
 
-	if err = GenerateModels(mockGen, gormDB, PostgresDriver, "public"); err == nil {
-		t.Fatalf("should have error\n")
+	modelDir := t.TempDir()
+	userModel := `package model
+
+const TableNameUser = "tbl_usr"
+
+// User mapped from table <tbl_usr>
+type User struct {
+	ID int32 ` + "`" + `gorm:"column:id;primaryKey" json:"id"` + "`" + `
+}
+`
+
+	if err = os.WriteFile(filepath.Join(modelDir, "tbl_usr.gen.go"), []byte(userModel), 0640); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	orderModel := `package model
+
+const TableNameOrder = "orders"
+
+// Order mapped from table <orders>
+type Order struct {
+	ID int32 ` + "`" + `gorm:"column:id;primaryKey" json:"id"` + "`" + `
+}
+`
+
+	if err = os.WriteFile(filepath.Join(modelDir, "orders.gen.go"), []byte(orderModel), 0640); err != nil {
+		t.Fatalf(err.Error())
 	}
 
-	if !errors.Is(err, ErrQueryTableNames) {
-		t.Fatalf("should have error %v; got %v\n", ErrQueryTableNames, err)
+	tables := []Table{
+		{Name: "tbl_usr", Comment: "User is a person signed up\nto the app"},
+		{Name: "orders", Comment: "purchases placed by users"},
 	}
+	cfg := ModelConfig{Tables: map[string]TableConfig{"tbl_usr": {StructName: "User"}}}
 
-	initNewMockDB()
-	tableRows = mockDB.NewRows([]string{"name"}).AddRow("user_profile").AddRow("phone")
+	if err = ApplyTableComments(modelDir, tables, cfg); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
 
-	mockDB.ExpectQuery("").WillReturnRows(tableRows)
-	mockDB.ExpectQuery("").WillReturnError(sqlErr)
+	content, err := os.ReadFile(filepath.Join(modelDir, "tbl_usr.gen.go"))
 
-	if err = GenerateModels(mockGen, gormDB, PostgresDriver, "public"); err == nil {
-		t.Fatalf("should not have error\n")
+	if err != nil {
+		t.Fatalf(err.Error())
 	}
 
-	if !errors.Is(err, ErrQueryColumnNames) {
-		t.Fatalf("should have error %v; got %v\n", ErrQueryColumnNames, err)
+	expected := "// User is a person signed up\n" +
+		"// to the app\n" +
+		"//\n" +
+		"// mapped from table <tbl_usr>\n" +
+		"type User struct {"
+
+	if !strings.Contains(string(content), expected) {
+		t.Fatalf("expected struct comment:\n%s\ngot:\n%s\n", expected, string(content))
 	}
 
-	initNewMockDB()
-	tableRows = mockDB.NewRows([]string{"name"}).AddRow("phone").AddRow("user_profile")
-	phoneColumnRows = mockDB.NewRows([]string{"cols"}).AddRow("id").AddRow("number").AddRow("user_profile_id")
+	if content, err = os.ReadFile(filepath.Join(modelDir, "orders.gen.go")); err != nil {
+		t.Fatalf(err.Error())
+	}
 
-	mockDB.ExpectQuery("select name from phone table").WillReturnRows(tableRows)
-	mockDB.ExpectQuery("select columns from phone table").WillReturnRows(phoneColumnRows)
-	mockDB.ExpectQuery("select foreignkey from phone table").WillReturnError(sqlErr)
+	expected = "// Order purchases placed by users\n" +
+		"//\n" +
+		"// mapped from table <orders>\n" +
+		"type Order struct {"
 
-	if err = GenerateModels(mockGen, gormDB, PostgresDriver, "public"); err == nil {
-		t.Fatalf("should have error\n")
+	if !strings.Contains(string(content), expected) {
+		t.Fatalf("expected struct comment:\n%s\ngot:\n%s\n", expected, string(content))
+	}
+}
+
+func TestRemoveGenDirs(t *testing.T) {
+	var err error
+
+	root := t.TempDir()
+	modelDir := filepath.Join(root, "model")
+	queryDir := filepath.Join(root, "query")
+	generated := filepath.Join(modelDir, "user.gen.go")
+	edited := filepath.Join(modelDir, "phone.gen.go")
+	handWritten := filepath.Join(modelDir, "hooks.go")
+	query := filepath.Join(queryDir, "gen.go")
+
+	for _, dir := range []string{modelDir, queryDir} {
+		if err = os.MkdirAll(dir, os.ModePerm); err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+	for _, file := range []string{generated, edited, handWritten, query} {
+		if err = os.WriteFile(file, []byte(filepath.Base(file)), 0640); err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+
+	manifest, err := ReadManifest(filepath.Join(root, DefaultManifestFile))
+
+	if err != nil {
+		t.Fatalf(err.Error())
 	}
 
-	if !errors.Is(err, ErrQueryForeignKeys) {
-		t.Fatalf("should have error %v; got %v\n", ErrQueryForeignKeys, err)
+	if err = manifest.Record(generated, edited, query); err != nil {
+		t.Fatalf(err.Error())
 	}
 
-	initNewMockDB()
-	tableRows = mockDB.NewRows([]string{"name"}).AddRow("user_profile").AddRow("phone")
-	phoneColumnRows = mockDB.NewRows([]string{"cols"}).AddRow("id").AddRow("number").AddRow("user_profile_id")
-	phoneForeignKeyRows = mockDB.NewRows([]string{"column_name", "foreign_table_name"}).AddRow("user_profile_id", "user_profile")
-	userColumnRows = mockDB.NewRows([]string{"col"}).AddRow("id").AddRow("name")
-	userForeignKeyRows = mockDB.NewRows([]string{"column_name", "foreign_table_name"})
+	if err = os.WriteFile(edited, []byte("edited"), 0640); err != nil {
+		t.Fatalf(err.Error())
+	}
 
-	mockDB.ExpectQuery("select name from tables").WillReturnRows(tableRows)
-	mockDB.ExpectQuery("select columns from phone table").WillReturnRows(phoneColumnRows)
-	mockDB.ExpectQuery("select foreignkey from phone table").WillReturnRows(phoneForeignKeyRows)
-	mockDB.ExpectQuery("select columns from user table").WillReturnRows(userColumnRows)
-	mockDB.ExpectQuery("select columns from user foreign keys table").WillReturnRows(userForeignKeyRows)
+	modified, err := RemoveGenDirs(manifest, queryDir, modelDir)
 
-	if err = GenerateModels(mockGen, gormDB, PostgresDriver, "public"); err != nil {
+	if err != nil {
 		t.Fatalf("should not have error; %s\n", err.Error())
 	}
 
+	if len(modified) != 1 || modified[0] != edited {
+		t.Fatalf("expected '%s' to be reported as modified; got %v\n", edited, modified)
+	}
+
+	if _, err = os.Stat(generated); !os.IsNotExist(err) {
+		t.Fatalf("unmodified generated file should be removed\n")
+	}
+	if _, err = os.Stat(queryDir); !os.IsNotExist(err) {
+		t.Fatalf("empty query dir should be removed\n")
+	}
+	if _, err = os.Stat(edited); err != nil {
+		t.Fatalf("edited generated file should be kept; %s\n", err.Error())
+	}
+	if _, err = os.Stat(handWritten); err != nil {
+		t.Fatalf("hand written file should be kept; %s\n", err.Error())
+	}
 }
 
-func TestGenerateTsModels(t *testing.T) {
-	var err error
+func TestModelConfigJSONField(t *testing.T) {
+	cfg := ModelConfig{
+		Tables: map[string]TableConfig{
+			"user_profile": {
+				Columns: map[string]ColumnConfig{
+					"email":         {JSONName: "emailAddress"},
+					"password_hash": {JSONOmit: true},
+					"nickname":      {Tags: map[string]string{"json": "nick,omitempty"}},
+				},
+			},
+		},
+		Tags: []TagGenerator{{Name: "json", Case: SnakeCase, OmitEmpty: OmitEmptyNullable}},
+	}
 
-	if err = GenerateTsModels(
-		"/home/travis/programming/projects/pac-env/app/server/model/",
-		"gen.go",
-		"/home/travis/programming/projects/pac-env/app/web/src/",
-		"model",
-		"gen.ts",
-		GenerateConfig{},
-	); err != nil {
-		t.Fatalf(err.Error())
+	tests := []struct {
+		col       Column
+		name      string
+		omitEmpty bool
+		ok        bool
+	}{
+		{Column{Name: "first_name"}, "first_name", false, true},
+		{Column{Name: "last_name", Nullable: true}, "last_name", true, true},
+		{Column{Name: "email", Nullable: true}, "emailAddress", false, true},
+		{Column{Name: "password_hash"}, "", false, false},
+		{Column{Name: "nickname"}, "nick", true, true},
+	}
+
+	for _, test := range tests {
+		name, omitEmpty, ok := cfg.jsonField("user_profile", test.col)
+
+		if name != test.name || omitEmpty != test.omitEmpty || ok != test.ok {
+			t.Fatalf(
+				"expected json field (%s, %t, %t) for column '%s'; got (%s, %t, %t)\n",
+				test.name, test.omitEmpty, test.ok, test.col.Name, name, omitEmpty, ok,
+			)
+		}
+	}
+}
+
+func TestModelConfigRelationName(t *testing.T) {
+	table := Table{
+		Name: "orders",
+		Columns: []Column{
+			{Name: "user_id"},
+			{Name: "owner"},
+			{Name: "shipper_id"},
+			{Name: "shipper"},
+		},
+	}
+	cfg := ModelConfig{}
+
+	tests := []struct {
+		fk       ForeignKey
+		expected string
+	}{
+		{ForeignKey{ColumnName: "user_id", ForeignTableName: "users"}, "user"},
+		{ForeignKey{ColumnName: "owner", ForeignTableName: "users"}, "owner_user"},
+		{ForeignKey{ColumnName: "shipper_id", ForeignTableName: "companies"}, "shipper_company"},
 	}
 
-	t.Fatalf("boom")
+	for _, test := range tests {
+		if name := cfg.relationName(table, test.fk); name != test.expected {
+			t.Fatalf("expected relation name '%s' for column '%s'; got '%s'\n", test.expected, test.fk.ColumnName, name)
+		}
+	}
+
+	if name, _ := cfg.relationJSONField(table, tests[1].fk); name != "ownerUser" {
+		t.Fatalf("expected relation json name 'ownerUser'; got '%s'\n", name)
+	}
+}
+
+func TestModelConfigRelationGormTag(t *testing.T) {
+	users := Table{
+		Name: "users",
+		Columns: []Column{
+			{Name: "id", DataType: "int8", PrimaryKey: true},
+			{Name: "email", DataType: "text"},
+		},
+	}
+	cfg := ModelConfig{
+		Tables: map[string]TableConfig{
+			"orders": {Columns: map[string]ColumnConfig{"seller_id": {FieldName: "Vendor"}}},
+		},
+	}
+
+	tests := []struct {
+		fk         ForeignKey
+		dataType   string
+		expected   string
+		foreignKey string
+		references string
+	}{
+		{
+			ForeignKey{ColumnName: "buyer", ForeignTableName: "users"},
+			"int8",
+			`gorm:"foreignKey:Buyer;references:ID"`,
+			"Buyer",
+			"ID",
+		},
+		{
+			ForeignKey{ColumnName: "seller_id", ForeignTableName: "users"},
+			"int8",
+			`gorm:"foreignKey:Vendor;references:ID"`,
+			"Vendor",
+			"ID",
+		},
+		{
+			ForeignKey{ColumnName: "contact_email", ForeignTableName: "users", ForeignColumnName: "email"},
+			"text",
+			`gorm:"foreignKey:ContactEmail;references:Email"`,
+			"ContactEmail",
+			"Email",
+		},
+	}
+
+	goTypes := map[string]reflect.Type{"int8": reflect.TypeOf(int64(0)), "text": reflect.TypeOf("")}
+
+	// structOf returns the struct gorm/gen generates for table with fields
+	// appended to its columns
+	structOf := func(table Table, fields ...reflect.StructField) reflect.Type {
+		for _, col := range table.Columns {
+			tag := "column:" + col.Name
+
+			if col.PrimaryKey {
+				tag += ";primaryKey"
+			}
+
+			fields = append(fields, reflect.StructField{
+				Name: cfg.fieldName(table.Name, col.Name),
+				Type: goTypes[col.DataType],
+				Tag:  reflect.StructTag(`gorm:"` + tag + `"`),
+			})
+		}
+
+		return reflect.StructOf(fields)
+	}
+
+	userType := structOf(users)
+
+	for _, test := range tests {
+		table := Table{
+			Name: "orders",
+			Columns: []Column{
+				{Name: "id", DataType: "int8", PrimaryKey: true},
+				{Name: test.fk.ColumnName, DataType: test.dataType},
+			},
+			ForeignKeys: []ForeignKey{test.fk},
+		}
+
+		tag := cfg.relationGormTag(table, test.fk, users)
+
+		if tag != test.expected {
+			t.Fatalf("expected gorm tag '%s' for column '%s'; got '%s'\n", test.expected, test.fk.ColumnName, tag)
+		}
+
+		relation := snaker.SnakeToCamel(cfg.relationName(table, test.fk))
+		orderType := structOf(table, reflect.StructField{
+			Name: relation,
+			Type: reflect.PtrTo(userType),
+			Tag:  reflect.StructTag(tag),
+		})
+
+		s, err := schema.Parse(reflect.New(orderType).Interface(), &sync.Map{}, schema.NamingStrategy{})
+
+		if err != nil {
+			t.Fatalf("should not have error; %s\n", err.Error())
+		}
+
+		rel := s.Relationships.Relations[relation]
+
+		if rel == nil || rel.Type != schema.BelongsTo {
+			t.Fatalf("expected relation '%s' to belong to users; got %+v\n", relation, rel)
+		}
+		if ref := rel.References[0]; ref.ForeignKey.Name != test.foreignKey || ref.PrimaryKey.Name != test.references {
+			t.Fatalf(
+				"expected relation '%s' to join %s on %s; got %s on %s\n",
+				relation, test.foreignKey, test.references, ref.ForeignKey.Name, ref.PrimaryKey.Name,
+			)
+		}
+	}
 }
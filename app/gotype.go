@@ -0,0 +1,198 @@
+package app
+
+import "strings"
+
+// jsonShape is the json value a go type is encoded to by encoding/json
+//
+// The go models are the api contract every other target describes so their
+// types follow the json of the go type a column ends up with rather than the
+// database type
+type jsonShape int
+
+const (
+	// jsonAny is free form json or a go type whose json isn't known
+	jsonAny jsonShape = iota
+	jsonString
+
+	// jsonInt is an integer that fits into 32 bits
+	jsonInt
+
+	// jsonBigint is an integer of up to 64 bits, beyond what a javascript number
+	// holds exactly
+	jsonBigint
+	jsonFloat
+	jsonBool
+
+	// jsonTime is an RFC 3339 date time string, even for date columns
+	jsonTime
+
+	// jsonBytes is a base64 encoded string
+	jsonBytes
+)
+
+// genScanTypes are the go types gorm/gen picks for postgres columns, which it
+// takes from the scan type of the pgx driver, keyed by udt name
+//
+// Every other type is scanned into a string
+var genScanTypes = map[string]string{
+	"int8":        "int64",
+	"int4":        "int32",
+	"int2":        "int16",
+	"float8":      "float64",
+	"float4":      "float32",
+	"numeric":     "float64",
+	"bool":        "bool",
+	"date":        "time.Time",
+	"timestamp":   "time.Time",
+	"timestamptz": "time.Time",
+	"bytea":       "[]byte",
+}
+
+// genDataTypes are the go types gorm/gen picks for mysql and sqlite columns
+// keyed by the lower case type name
+//
+// Every other type is generated as a string
+var genDataTypes = map[string]string{
+	"numeric":    "int32",
+	"integer":    "int32",
+	"int":        "int32",
+	"smallint":   "int32",
+	"mediumint":  "int32",
+	"tinyint":    "int32",
+	"year":       "int32",
+	"bigint":     "int64",
+	"float":      "float32",
+	"real":       "float64",
+	"double":     "float64",
+	"decimal":    "float64",
+	"boolean":    "bool",
+	"binary":     "[]byte",
+	"varbinary":  "[]byte",
+	"tinyblob":   "[]byte",
+	"blob":       "[]byte",
+	"mediumblob": "[]byte",
+	"longblob":   "[]byte",
+	"bit":        "[]uint8",
+	"time":       "time.Time",
+	"date":       "time.Time",
+	"datetime":   "time.Time",
+	"timestamp":  "time.Time",
+}
+
+// goJSONShapes is the json shape of the go types models are commonly generated
+// with
+//
+// Types not listed here, like the database/sql null types which encode as
+// objects, are free form json
+var goJSONShapes = map[string]jsonShape{
+	"string":          jsonString,
+	"int8":            jsonInt,
+	"int16":           jsonInt,
+	"int32":           jsonInt,
+	"uint8":           jsonInt,
+	"uint16":          jsonInt,
+	"uint32":          jsonInt,
+	"int":             jsonBigint,
+	"int64":           jsonBigint,
+	"uint":            jsonBigint,
+	"uint64":          jsonBigint,
+	"float32":         jsonFloat,
+	"float64":         jsonFloat,
+	"bool":            jsonBool,
+	"time.Time":       jsonTime,
+	"gorm.DeletedAt":  jsonTime,
+	"datatypes.Date":  jsonTime,
+	"[]byte":          jsonBytes,
+	"[]uint8":         jsonBytes,
+	"decimal.Decimal": jsonString,
+	"uuid.UUID":       jsonString,
+}
+
+// genGoType returns the go type gorm/gen generates for col of driver when no
+// type is configured for it
+func genGoType(driver DBDriver, col Column) string {
+	var goType string
+
+	switch driver {
+	case MysqlDriver, SqliteDriver:
+		goType = genDataTypes[col.DataType]
+
+		// sqlite reports types as they were declared so types declared with a
+		// precision, eg. "decimal(10,2)", match nothing
+		if driver == SqliteDriver && col.NumericPrecision > 0 {
+			goType = ""
+		}
+	default:
+		goType = genScanTypes[col.DataType]
+	}
+
+	if goType == "" {
+		goType = "string"
+	}
+
+	if col.Name == "deleted_at" && goType == "time.Time" {
+		return "gorm.DeletedAt"
+	}
+
+	return goType
+}
+
+// goType returns the go type of the field generated for col of table without
+// nullability, along with whether nullable columns get the type cfg.Nullable
+// picks for it
+//
+// The type is the one of the typed id, the column config, the type map or the
+// one gorm/gen picks, in that order.  Mappings with a column pattern that
+// doesn't compile never match as TypeMap.Validate reports them
+func (cfg ModelConfig) goType(table Table, col Column) (string, bool) {
+	if id, ok := cfg.idType(table, col); ok {
+		return id.Base, true
+	}
+
+	if goType := cfg.Tables[table.Name].Columns[col.Name].GoType; goType != "" {
+		return goType, false
+	}
+
+	if m, ok, _ := cfg.TypeMap.Lookup(cfg.Driver, col.DataType, col.Nullable, col.Name); ok && m.GoType != "" {
+		// Only the types gorm/gen predicts itself are made nullable
+		return m.GoType, !m.conditional()
+	}
+
+	return genGoType(cfg.Driver, col), true
+}
+
+// jsonShape returns the json shape of the field generated for col of table
+func (cfg ModelConfig) jsonShape(table Table, col Column) jsonShape {
+	goType, nullable := cfg.goType(table, col)
+
+	if col.Nullable && nullable && cfg.Nullable.Pointers() {
+		goType, _ = cfg.Nullable.goType(goType)
+	}
+
+	return goJSONShape(goType)
+}
+
+// enumColumn returns whether col of table is generated as an enum, which takes
+// enum values and a go type encoded as a string
+func (cfg ModelConfig) enumColumn(table Table, col Column) bool {
+	return len(col.EnumValues) > 0 && cfg.jsonShape(table, col) == jsonString
+}
+
+// goJSONShape returns the json shape of goType
+//
+// Pointers encode as what they point to and generic types, like the null types
+// of NullableGeneric, are expected to encode as the type they wrap.  The null
+// type of NullableDatatypes has no json methods and encodes as a struct
+func goJSONShape(goType string) jsonShape {
+	goType = strings.TrimPrefix(goType, "*")
+
+	if strings.HasPrefix(goType, "datatypes.Null[") {
+		return jsonAny
+	}
+
+	if start := strings.Index(goType, "["); start > 0 && strings.HasSuffix(goType, "]") {
+		goType = goType[start+1 : len(goType)-1]
+	}
+
+	return goJSONShapes[goType]
+}
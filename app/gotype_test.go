@@ -0,0 +1,85 @@
+package app
+
+import "testing"
+
+func TestGenGoType(t *testing.T) {
+	tests := []struct {
+		driver   DBDriver
+		col      Column
+		expected string
+	}{
+		{PostgresDriver, Column{Name: "id", DataType: "int8"}, "int64"},
+		{PostgresDriver, Column{Name: "total", DataType: "numeric"}, "float64"},
+		{PostgresDriver, Column{Name: "id", DataType: "uuid"}, "string"},
+		{PostgresDriver, Column{Name: "data", DataType: "jsonb"}, "string"},
+		{PostgresDriver, Column{Name: "deleted_at", DataType: "timestamptz"}, "gorm.DeletedAt"},
+		{MysqlDriver, Column{Name: "total", DataType: "decimal"}, "float64"},
+		{MysqlDriver, Column{Name: "count", DataType: "numeric"}, "int32"},
+		{MysqlDriver, Column{Name: "file", DataType: "blob"}, "[]byte"},
+		{SqliteDriver, Column{Name: "total", DataType: "decimal", NumericPrecision: 10}, "string"},
+		{SqliteDriver, Column{Name: "id", DataType: "integer"}, "int32"},
+	}
+
+	for _, test := range tests {
+		if goType := genGoType(test.driver, test.col); goType != test.expected {
+			t.Fatalf(
+				"expected go type '%s' for %s column '%s'; got '%s'\n",
+				test.expected, test.driver, test.col.DataType, goType,
+			)
+		}
+	}
+}
+
+func TestModelConfigJSONShape(t *testing.T) {
+	table := Table{
+		Name: "user_profile",
+		Columns: []Column{
+			{Name: "id", DataType: "int8", PrimaryKey: true},
+			{Name: "balance", DataType: "numeric"},
+			{Name: "score", DataType: "numeric"},
+			{Name: "rating", DataType: "int4", Nullable: true},
+			{Name: "visits", DataType: "int4", Nullable: true},
+			{Name: "avatar", DataType: "bytea"},
+		},
+	}
+	cfg := ModelConfig{
+		Driver: PostgresDriver,
+		TypeMap: TypeMap{
+			{DBType: "int4", GoType: "int32", ColumnPattern: "^visits$"},
+		},
+		Tables: map[string]TableConfig{
+			"user_profile": {
+				Columns: map[string]ColumnConfig{
+					"balance": {GoType: "decimal.Decimal"},
+				},
+			},
+		},
+		Nullable: NullableConfig{Strategy: NullableSQL},
+		IDTypes:  map[string]IDType{"user_profile": {Name: "UserProfileID", Base: "int64"}},
+	}
+
+	// Conditional mappings are set on the field as is so they aren't rewritten
+	// to database/sql null types, which encode as objects
+	expected := map[string]jsonShape{
+		"id":      jsonBigint,
+		"balance": jsonString,
+		"score":   jsonFloat,
+		"rating":  jsonAny,
+		"visits":  jsonInt,
+		"avatar":  jsonBytes,
+	}
+
+	for _, col := range table.Columns {
+		if shape := cfg.jsonShape(table, col); shape != expected[col.Name] {
+			t.Fatalf("expected json shape %d for column '%s'; got %d\n", expected[col.Name], col.Name, shape)
+		}
+	}
+
+	if shape := goJSONShape("null.Value[time.Time]"); shape != jsonTime {
+		t.Fatalf("generic types should have the json shape of the type they wrap; got %d\n", shape)
+	}
+
+	if shape := goJSONShape("datatypes.Null[time.Time]"); shape != jsonAny {
+		t.Fatalf("expected json shape %d for datatypes null type; got %d\n", jsonAny, shape)
+	}
+}
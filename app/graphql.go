@@ -0,0 +1,345 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kenshaw/snaker"
+	"github.com/pkg/errors"
+)
+
+// graphqlShapeTypes is the graphql type of every json shape, custom scalars for
+// the shapes graphql has no type for
+//
+// Integers too large for the 32 bit Int of graphql are BigInts
+var graphqlShapeTypes = map[jsonShape]string{
+	jsonAny:    "JSON",
+	jsonString: "String",
+	jsonInt:    "Int",
+	jsonBigint: "BigInt",
+	jsonFloat:  "Float",
+	jsonBool:   "Boolean",
+	jsonTime:   "DateTime",
+	jsonBytes:  "String",
+}
+
+// graphqlScalars are the custom scalars generated types may use
+var graphqlScalars = map[string]bool{
+	"BigInt":   true,
+	"DateTime": true,
+	"UUID":     true,
+	"JSON":     true,
+}
+
+const graphqlScalarsFile = "scalars"
+
+// GraphQLTableConfig overrides how the graphql type of a single table is generated
+type GraphQLTableConfig struct {
+	// Omit leaves the table out of the schema along with every relation to it
+	Omit bool
+
+	// TypeName overrides the name of the type, the struct name by default
+	TypeName string
+
+	// Implements lists the interfaces the type implements
+	Implements []string
+
+	// OmitReverseRelations leaves out the list fields of the tables referencing
+	// the table
+	OmitReverseRelations bool
+}
+
+// graphqlRelation is a relation field of a generated type
+type graphqlRelation struct {
+	name     string
+	typeName string
+}
+
+// GenerateGraphQLSchema generates a graphql type for every table in a single
+// schema file named graphqlFile within graphqlDir
+//
+// When graphqlFile is empty every table is generated into its own file named
+// after the table instead along with a file declaring the custom scalars.
+// Foreign keys get a field on the referencing type and a list field on the
+// referenced type.  Enum values are written in SCREAMING_SNAKE_CASE so they
+// have to be mapped to their database values by the server
+func GenerateGraphQLSchema(tables []Table, graphqlDir, graphqlFile string, cfg GenerateConfig) error {
+	if graphqlDir == "" {
+		return errors.WithStack(fmt.Errorf("model-gen: graphqlDir parameter can't be empty"))
+	}
+
+	if err := os.MkdirAll(graphqlDir, os.ModePerm); err != nil {
+		return errors.WithStack(err)
+	}
+
+	var included []Table
+
+	for _, table := range tables {
+		if !cfg.GraphQLTables[table.Name].Omit {
+			included = append(included, table)
+		}
+	}
+
+	relations := graphqlRelations(included, cfg)
+
+	if graphqlFile != "" {
+		if !strings.HasSuffix(graphqlFile, ".graphql") {
+			graphqlFile += ".graphql"
+		}
+
+		var b strings.Builder
+
+		b.WriteString(graphqlScalarDeclarations(included, cfg))
+
+		for _, table := range included {
+			if b.Len() > 0 {
+				b.WriteString("\n")
+			}
+
+			b.WriteString(graphqlType(table, relations[table.Name], cfg))
+		}
+
+		return errors.WithStack(os.WriteFile(filepath.Join(graphqlDir, graphqlFile), []byte(b.String()), 0644))
+	}
+
+	if scalars := graphqlScalarDeclarations(included, cfg); scalars != "" {
+		err := os.WriteFile(filepath.Join(graphqlDir, graphqlScalarsFile+".graphql"), []byte(scalars), 0644)
+
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	for _, table := range included {
+		content := graphqlType(table, relations[table.Name], cfg)
+
+		if err := os.WriteFile(filepath.Join(graphqlDir, table.Name+".graphql"), []byte(content), 0644); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	return nil
+}
+
+// graphqlRelations returns the relation fields of every table keyed by table
+// name, both the referenced types of its foreign keys and the lists of the types
+// referencing it
+func graphqlRelations(tables []Table, cfg GenerateConfig) map[string][]graphqlRelation {
+	relations := make(map[string][]graphqlRelation)
+	included := make(map[string]bool, len(tables))
+
+	for _, table := range tables {
+		included[table.Name] = true
+	}
+
+	for _, table := range tables {
+		references := make(map[string]int)
+
+		for _, fk := range table.ForeignKeys {
+			references[fk.ForeignTableName]++
+		}
+
+		for _, fk := range table.ForeignKeys {
+			if !included[fk.ForeignTableName] {
+				continue
+			}
+
+			name, _ := cfg.Model.relationJSONField(table, fk)
+			typeName := cfg.graphqlTypeName(fk.ForeignTableName)
+
+			if col := table.column(fk.ColumnName); col != nil && !col.Nullable {
+				typeName += "!"
+			}
+
+			relations[table.Name] = append(relations[table.Name], graphqlRelation{name: name, typeName: typeName})
+
+			if cfg.GraphQLTables[fk.ForeignTableName].OmitReverseRelations {
+				continue
+			}
+
+			// Tables referencing the same table more than once get a list per
+			// foreign key, eg. "messagesBySender" and "messagesByRecipient"
+			reverseName := snaker.ForceLowerCamelIdentifier(pluralize(cfg.graphqlTypeName(table.Name)))
+
+			if references[fk.ForeignTableName] > 1 {
+				reverseName += "By" + snaker.ForceCamelIdentifier(cfg.Model.relationName(table, fk))
+			}
+
+			relations[fk.ForeignTableName] = append(relations[fk.ForeignTableName], graphqlRelation{
+				name:     reverseName,
+				typeName: fmt.Sprintf("[%s!]!", cfg.graphqlTypeName(table.Name)),
+			})
+		}
+	}
+
+	return relations
+}
+
+// graphqlType returns the type of table followed by the enums of its enum columns
+func graphqlType(table Table, relations []graphqlRelation, cfg GenerateConfig) string {
+	var b, enums strings.Builder
+
+	typeName := cfg.graphqlTypeName(table.Name)
+
+	b.WriteString(graphqlDescription(table.Comment, ""))
+	b.WriteString("type " + typeName)
+
+	if implements := cfg.GraphQLTables[table.Name].Implements; len(implements) > 0 {
+		b.WriteString(" implements " + strings.Join(implements, " & "))
+	}
+
+	b.WriteString(" {\n")
+
+	for _, col := range table.Columns {
+		name, _, ok := cfg.Model.jsonField(table.Name, col)
+
+		// Fields hidden from json are never part of the api contract
+		if !ok {
+			continue
+		}
+
+		fieldType := cfg.graphqlFieldType(table, col)
+
+		if !col.Nullable {
+			fieldType += "!"
+		}
+
+		b.WriteString(graphqlDescription(col.Comment, "  "))
+		b.WriteString(fmt.Sprintf("  %s: %s\n", name, fieldType))
+
+		if cfg.Model.enumColumn(table, col) {
+			enums.WriteString(graphqlEnum(typeName+snaker.SnakeToCamel(col.Name), col.EnumValues))
+		}
+	}
+
+	for _, relation := range relations {
+		b.WriteString(fmt.Sprintf("  %s: %s\n", relation.name, relation.typeName))
+	}
+
+	b.WriteString("}\n")
+	b.WriteString(enums.String())
+
+	return b.String()
+}
+
+// graphqlEnum returns an enum named name with a value per enum value
+func graphqlEnum(name string, values []string) string {
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("\nenum %s {\n", name))
+
+	for _, constant := range enumConstants(values, screamingEnumConstant) {
+		b.WriteString(fmt.Sprintf("  %s\n", constant))
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// graphqlScalarDeclarations returns the declarations of the custom scalars
+// tables use, or nothing if they use none
+func graphqlScalarDeclarations(tables []Table, cfg GenerateConfig) string {
+	used := make(map[string]bool)
+
+	for _, table := range tables {
+		for _, col := range table.Columns {
+			if _, _, ok := cfg.Model.jsonField(table.Name, col); !ok {
+				continue
+			}
+
+			if t := cfg.graphqlFieldType(table, col); graphqlScalars[t] {
+				used[t] = true
+			}
+		}
+	}
+
+	var b strings.Builder
+
+	for _, scalar := range sortedKeys(used) {
+		b.WriteString(fmt.Sprintf("scalar %s\n", scalar))
+	}
+
+	return b.String()
+}
+
+// graphqlTypeName returns the name of the type generated for tableName
+func (cfg GenerateConfig) graphqlTypeName(tableName string) string {
+	if name := cfg.GraphQLTables[tableName].TypeName; name != "" {
+		return name
+	}
+
+	return cfg.Model.structName(tableName)
+}
+
+// graphqlFieldType returns the graphql type of col of table without the non-null
+// marker
+//
+// Single column primary keys and foreign keys are IDs
+func (cfg GenerateConfig) graphqlFieldType(table Table, col Column) string {
+	if cfg.Model.enumColumn(table, col) {
+		return cfg.graphqlTypeName(table.Name) + snaker.SnakeToCamel(col.Name)
+	}
+
+	if pk := table.primaryKey(); pk != nil && pk.Name == col.Name {
+		return "ID"
+	}
+
+	for _, fk := range table.ForeignKeys {
+		if fk.ColumnName == col.Name {
+			return "ID"
+		}
+	}
+
+	shape := cfg.Model.jsonShape(table, col)
+
+	if shape == jsonString && col.kind() == uuidKind {
+		return "UUID"
+	}
+
+	return graphqlShapeTypes[shape]
+}
+
+// graphqlDescription returns comment as a block string description indented by
+// indent, or nothing if comment is empty
+func graphqlDescription(comment, indent string) string {
+	comment = strings.TrimSpace(comment)
+
+	if comment == "" {
+		return ""
+	}
+
+	comment = strings.ReplaceAll(comment, `"""`, `\"""`)
+	lines := strings.Split(comment, "\n")
+
+	if len(lines) == 1 {
+		return fmt.Sprintf("%s\"\"\"%s\"\"\"\n", indent, lines[0])
+	}
+
+	var doc strings.Builder
+
+	doc.WriteString(indent + `"""` + "\n")
+
+	for _, line := range lines {
+		doc.WriteString(strings.TrimRight(indent+strings.TrimSpace(line), " ") + "\n")
+	}
+
+	doc.WriteString(indent + `"""` + "\n")
+
+	return doc.String()
+}
+
+// pluralize returns the plural of the english noun name
+func pluralize(name string) string {
+	switch {
+	case strings.HasSuffix(name, "s"), strings.HasSuffix(name, "x"), strings.HasSuffix(name, "z"),
+		strings.HasSuffix(name, "ch"), strings.HasSuffix(name, "sh"):
+		return name + "es"
+	case strings.HasSuffix(name, "y") && len(name) > 1 && !strings.ContainsRune("aeiou", rune(name[len(name)-2])):
+		return name[:len(name)-1] + "ies"
+	}
+
+	return name + "s"
+}
@@ -0,0 +1,171 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateGraphQLSchema(t *testing.T) {
+	var err error
+
+	graphqlDir := filepath.Join(t.TempDir(), "graphql")
+
+	if err = GenerateGraphQLSchema(nil, "", "", GenerateConfig{}); err == nil {
+		t.Fatalf("should have error\n")
+	}
+
+	tables := []Table{
+		{
+			Name:    "user_profile",
+			Comment: "Person using the app",
+			Columns: []Column{
+				{Name: "id", DataType: "int8", PrimaryKey: true},
+				{Name: "name", DataType: "varchar", Nullable: true, Comment: "Display name"},
+				{Name: "status", DataType: "user_status", EnumValues: []string{"active", "on-hold"}},
+				{Name: "settings", DataType: "jsonb"},
+				{Name: "created_at", DataType: "timestamptz"},
+			},
+		},
+		{
+			Name: "message",
+			Columns: []Column{
+				{Name: "id", DataType: "uuid", PrimaryKey: true},
+				{Name: "views", DataType: "int8"},
+				{Name: "sender_id", DataType: "int8"},
+				{Name: "recipient_id", DataType: "int8", Nullable: true},
+			},
+			ForeignKeys: []ForeignKey{
+				{ColumnName: "sender_id", ForeignTableName: "user_profile"},
+				{ColumnName: "recipient_id", ForeignTableName: "user_profile"},
+			},
+		},
+		{
+			Name: "phone",
+			Columns: []Column{
+				{Name: "id", DataType: "int4", PrimaryKey: true},
+				{Name: "user_profile_id", DataType: "int8"},
+			},
+			ForeignKeys: []ForeignKey{
+				{ColumnName: "user_profile_id", ForeignTableName: "user_profile"},
+			},
+		},
+		{
+			Name: "orders",
+			Columns: []Column{
+				{Name: "id", DataType: "int4", PrimaryKey: true},
+				{Name: "user_profile_id", DataType: "int8"},
+			},
+			ForeignKeys: []ForeignKey{
+				{ColumnName: "user_profile_id", ForeignTableName: "user_profile"},
+			},
+		},
+		{
+			Name:    "audit_log",
+			Columns: []Column{{Name: "event", DataType: "text"}},
+		},
+	}
+
+	cfg := GenerateConfig{
+		Model: ModelConfig{
+			TypeMap: TypeMap{{DBType: "jsonb", GoType: "datatypes.JSON"}},
+		},
+		GraphQLTables: map[string]GraphQLTableConfig{
+			"user_profile": {TypeName: "User", Implements: []string{"Node"}},
+			"phone":        {OmitReverseRelations: true},
+			"audit_log":    {Omit: true},
+		},
+	}
+
+	if err = GenerateGraphQLSchema(tables, graphqlDir, "schema", cfg); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	content, err := os.ReadFile(filepath.Join(graphqlDir, "schema.graphql"))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expected := "scalar BigInt\n" +
+		"scalar DateTime\n" +
+		"scalar JSON\n\n" +
+		"\"\"\"Person using the app\"\"\"\n" +
+		"type User implements Node {\n" +
+		"  id: ID!\n" +
+		"  \"\"\"Display name\"\"\"\n" +
+		"  name: String\n" +
+		"  status: UserStatus!\n" +
+		"  settings: JSON!\n" +
+		"  createdAt: DateTime!\n" +
+		"  messagesBySender: [Message!]!\n" +
+		"  messagesByRecipient: [Message!]!\n" +
+		"  phones: [Phone!]!\n" +
+		"  orders: [Order!]!\n" +
+		"}\n\n" +
+		"enum UserStatus {\n" +
+		"  ACTIVE\n" +
+		"  ON_HOLD\n" +
+		"}\n\n" +
+		"type Message {\n" +
+		"  id: ID!\n" +
+		"  views: BigInt!\n" +
+		"  senderID: ID!\n" +
+		"  recipientID: ID\n" +
+		"  sender: User!\n" +
+		"  recipient: User\n" +
+		"}\n\n" +
+		"type Phone {\n" +
+		"  id: ID!\n" +
+		"  userProfileID: ID!\n" +
+		"  userProfile: User!\n" +
+		"}\n\n" +
+		"type Order {\n" +
+		"  id: ID!\n" +
+		"  userProfileID: ID!\n" +
+		"  userProfile: User!\n" +
+		"}\n"
+
+	if string(content) != expected {
+		t.Fatalf("expected graphql output:\n%s\ngot:\n%s\n", expected, string(content))
+	}
+
+	if err = GenerateGraphQLSchema(tables, graphqlDir, "", cfg); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	for _, name := range []string{"scalars.graphql", "user_profile.graphql", "message.graphql", "phone.graphql", "orders.graphql"} {
+		if _, err = os.Stat(filepath.Join(graphqlDir, name)); err != nil {
+			t.Fatalf("expected file %s; %s\n", name, err.Error())
+		}
+	}
+
+	if _, err = os.Stat(filepath.Join(graphqlDir, "audit_log.graphql")); !os.IsNotExist(err) {
+		t.Fatalf("omitted tables should not be generated; got %v\n", err)
+	}
+
+	content, err = os.ReadFile(filepath.Join(graphqlDir, "scalars.graphql"))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if !strings.HasPrefix(string(content), "scalar BigInt\n") {
+		t.Fatalf("expected scalar declarations; got:\n%s\n", string(content))
+	}
+}
+
+func TestPluralize(t *testing.T) {
+	for name, expected := range map[string]string{
+		"phone":   "phones",
+		"address": "addresses",
+		"company": "companies",
+		"day":     "days",
+		"branch":  "branches",
+	} {
+		if plural := pluralize(name); plural != expected {
+			t.Fatalf("expected plural '%s' of '%s'; got '%s'\n", expected, name, plural)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package app
+
+import (
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrLoadIDTypes = errors.New("model-gen: load id types error")
+)
+
+// IDType is the typed id generated for the primary key of a table, eg.
+// "type UserID int64"
+type IDType struct {
+	// Name is the name of the type, eg. "UserID"
+	Name string
+
+	// Base is the go type gorm/gen would have generated for the primary key
+	Base string
+
+	// Import is the import path Base requires, if any
+	Import string
+}
+
+// idIntegerTypes are the base types scanned through sql.NullInt64
+var idIntegerTypes = map[string]bool{
+	"int":    true,
+	"int8":   true,
+	"int16":  true,
+	"int32":  true,
+	"int64":  true,
+	"uint":   true,
+	"uint8":  true,
+	"uint16": true,
+	"uint32": true,
+	"uint64": true,
+}
+
+// idTypesFile is the file WriteIDTypes generates within the model directory
+const idTypesFile = "id_types.gen.go"
+
+// LoadIDTypes returns the typed id of every table with a single column primary
+// key keyed by table name
+//
+// The base type of every id is the type gorm/gen generates for the primary key
+// so g is asked for the model of the table first.  Base types with no sensible
+// Scan and Value implementation, like []byte, get no typed id
+func LoadIDTypes(g GenExecutor, tables []Table, driver DBDriver, cfg ModelConfig) (ids map[string]IDType, err error) {
+	// gorm/gen panics when it fails to introspect a table
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf(packageErr, ErrLoadIDTypes, fmt.Sprint(r))
+		}
+	}()
+
+	ids = make(map[string]IDType)
+
+	for _, table := range tables {
+		pk := table.primaryKey()
+
+		if pk == nil {
+			continue
+		}
+
+		id := IDType{Name: cfg.structName(table.Name) + "ID"}
+		colCfg := cfg.Tables[table.Name].Columns[pk.Name]
+		m, mapped, err := cfg.TypeMap.Lookup(driver, pk.DataType, pk.Nullable, pk.Name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case colCfg.GoType != "":
+			id.Base, id.Import = colCfg.GoType, colCfg.GoImport
+		case mapped && m.GoType != "" && m.conditional():
+			id.Base, id.Import = m.GoType, m.GoImport
+		default:
+			id.Base = strings.TrimPrefix(genFieldTypes(g.GenerateModel(table.Name))[pk.Name], "*")
+
+			if mapped && m.GoType == id.Base {
+				id.Import = m.GoImport
+			}
+		}
+
+		if id.Base == "" {
+			id.Base = "string"
+
+			if pk.kind() == integerKind {
+				id.Base = "int64"
+			}
+		}
+
+		if idIntegerTypes[id.Base] || id.Base == "string" || strings.Contains(id.Base, ".") {
+			ids[table.Name] = id
+		}
+	}
+
+	return ids, nil
+}
+
+// genFieldTypes returns the go type of every field of the model meta gorm/gen
+// returns keyed by column name
+//
+// The meta is of an internal gorm/gen type so its fields are read through
+// reflection, nil metas return nothing
+func genFieldTypes(meta interface{}) map[string]string {
+	fieldTypes := make(map[string]string)
+	v := reflect.Indirect(reflect.ValueOf(meta))
+
+	if v.Kind() != reflect.Struct {
+		return fieldTypes
+	}
+
+	fields := v.FieldByName("Fields")
+
+	if fields.Kind() != reflect.Slice {
+		return fieldTypes
+	}
+
+	for i := 0; i < fields.Len(); i++ {
+		field := reflect.Indirect(fields.Index(i))
+
+		if field.Kind() != reflect.Struct {
+			continue
+		}
+
+		columnName, fieldType := field.FieldByName("ColumnName"), field.FieldByName("Type")
+
+		if columnName.Kind() == reflect.String && fieldType.Kind() == reflect.String {
+			fieldTypes[columnName.String()] = fieldType.String()
+		}
+	}
+
+	return fieldTypes
+}
+
+// idType returns the typed id of col, either the one of table if col is its
+// primary key or the one of the referenced table if col is a foreign key
+func (cfg ModelConfig) idType(table Table, col Column) (IDType, bool) {
+	if pk := table.primaryKey(); pk != nil && pk.Name == col.Name {
+		id, ok := cfg.IDTypes[table.Name]
+		return id, ok
+	}
+
+	for _, fk := range table.ForeignKeys {
+		if fk.ColumnName == col.Name {
+			id, ok := cfg.IDTypes[fk.ForeignTableName]
+			return id, ok
+		}
+	}
+
+	return IDType{}, false
+}
+
+// WriteIDTypes generates the declarations of cfg.IDTypes along with their Scan,
+// Value, MarshalJSON and UnmarshalJSON methods into modelDir
+//
+// The package name is taken from the models gorm/gen already generated
+func WriteIDTypes(modelDir string, tables []Table, cfg ModelConfig) error {
+	if len(cfg.IDTypes) == 0 {
+		return nil
+	}
+
+	pkgName, err := goPackageName(modelDir)
+
+	if err != nil {
+		return err
+	}
+
+	var decls, b strings.Builder
+
+	imports := map[string]bool{
+		"database/sql/driver": true,
+		"encoding/json":       true,
+	}
+
+	for _, table := range tables {
+		id, ok := cfg.IDTypes[table.Name]
+
+		if !ok {
+			continue
+		}
+
+		decls.WriteString(idTypeDeclaration(table.Name, id))
+
+		if id.Import != "" {
+			imports[id.Import] = true
+		}
+
+		if idIntegerTypes[id.Base] || id.Base == "string" {
+			imports["database/sql"] = true
+		}
+	}
+
+	b.WriteString("// Code generated by model-gen. DO NOT EDIT.\n\n")
+	b.WriteString("package " + pkgName + "\n\n")
+	b.WriteString("import (\n")
+
+	for _, importPath := range sortedKeys(imports) {
+		b.WriteString(fmt.Sprintf("\t%q\n", importPath))
+	}
+
+	b.WriteString(")\n")
+	b.WriteString(decls.String())
+
+	content, err := format.Source([]byte(b.String()))
+
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return errors.WithStack(os.WriteFile(filepath.Join(modelDir, idTypesFile), content, 0644))
+}
+
+// idTypeDeclaration returns the declaration of id along with its methods
+//
+// Integer and string ids are scanned through the database/sql null types so any
+// driver value converts, other base types are expected to implement sql.Scanner
+// and driver.Valuer themselves
+func idTypeDeclaration(tableName string, id IDType) string {
+	var scan, value string
+
+	switch {
+	case idIntegerTypes[id.Base]:
+		scan = fmt.Sprintf("var v sql.NullInt64\n\nif err := v.Scan(value); err != nil {\nreturn err\n}\n\n*id = %s(v.Int64)\nreturn nil", id.Name)
+		value = "return int64(id), nil"
+	case id.Base == "string":
+		scan = fmt.Sprintf("var v sql.NullString\n\nif err := v.Scan(value); err != nil {\nreturn err\n}\n\n*id = %s(v.String)\nreturn nil", id.Name)
+		value = "return string(id), nil"
+	default:
+		scan = fmt.Sprintf("return (*%s)(id).Scan(value)", id.Base)
+		value = fmt.Sprintf("return %s(id).Value()", id.Base)
+	}
+
+	return fmt.Sprintf(
+		"\n// %s is the primary key of table %s\n"+
+			"type %s %s\n\n"+
+			"// Scan implements sql.Scanner\n"+
+			"func (id *%s) Scan(value interface{}) error {\n%s\n}\n\n"+
+			"// Value implements driver.Valuer\n"+
+			"func (id %s) Value() (driver.Value, error) {\n%s\n}\n\n"+
+			"// MarshalJSON implements json.Marshaler\n"+
+			"func (id %s) MarshalJSON() ([]byte, error) {\nreturn json.Marshal(%s(id))\n}\n\n"+
+			"// UnmarshalJSON implements json.Unmarshaler\n"+
+			"func (id *%s) UnmarshalJSON(data []byte) error {\nreturn json.Unmarshal(data, (*%s)(id))\n}\n",
+		id.Name, tableName,
+		id.Name, id.Base,
+		id.Name, scan,
+		id.Name, value,
+		id.Name, id.Base,
+		id.Name, id.Base,
+	)
+}
+
+// goPackageName returns the package name of the go files in dir
+func goPackageName(dir string) (string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	for _, file := range files {
+		if filepath.Base(file) == idTypesFile {
+			continue
+		}
+
+		f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.PackageClauseOnly)
+
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+
+		return f.Name.Name, nil
+	}
+
+	return filepath.Base(dir), nil
+}
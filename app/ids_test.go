@@ -0,0 +1,168 @@
+package app
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gorm.io/gen"
+)
+
+type metaField struct {
+	ColumnName string
+	Type       string
+}
+
+type metaGenerator struct {
+	mockGenerator
+	fields map[string][]*metaField
+}
+
+func (m *metaGenerator) GenerateModel(model string, opts ...gen.ModelOpt) interface{} {
+	return &struct{ Fields []*metaField }{Fields: m.fields[model]}
+}
+
+type panicMetaGenerator struct {
+	mockGenerator
+}
+
+func (p *panicMetaGenerator) GenerateModel(model string, opts ...gen.ModelOpt) interface{} {
+	panic("get table info fail")
+}
+
+func TestLoadIDTypes(t *testing.T) {
+	var err error
+
+	tables := []Table{
+		{Name: "user_profile", Columns: []Column{{Name: "id", DataType: "int8", PrimaryKey: true}}},
+		{Name: "invoice", Columns: []Column{{Name: "id", DataType: "uuid", PrimaryKey: true}}},
+		{Name: "orders", Columns: []Column{{Name: "id", DataType: "int4", PrimaryKey: true}}},
+		{Name: "file", Columns: []Column{{Name: "id", DataType: "bytea", PrimaryKey: true}}},
+		{Name: "audit", Columns: []Column{{Name: "event", DataType: "text"}}},
+		{
+			Name: "user_role",
+			Columns: []Column{
+				{Name: "user_profile_id", DataType: "int8", PrimaryKey: true},
+				{Name: "role", DataType: "text", PrimaryKey: true},
+			},
+		},
+	}
+
+	if _, err = LoadIDTypes(&panicMetaGenerator{}, tables, PostgresDriver, ModelConfig{}); err == nil {
+		t.Fatalf("should have error\n")
+	}
+
+	g := &metaGenerator{
+		fields: map[string][]*metaField{
+			"user_profile": {{ColumnName: "id", Type: "int64"}},
+			"invoice":      {{ColumnName: "id", Type: "uuid.UUID"}},
+			"orders":       {{ColumnName: "id", Type: "int32"}},
+			"file":         {{ColumnName: "id", Type: "[]byte"}},
+		},
+	}
+	cfg := ModelConfig{
+		TypeMap: TypeMap{{DBType: "uuid", GoType: "uuid.UUID", GoImport: "github.com/google/uuid"}},
+	}
+
+	ids, err := LoadIDTypes(g, tables, PostgresDriver, cfg)
+
+	if err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	expected := map[string]IDType{
+		"user_profile": {Name: "UserProfileID", Base: "int64"},
+		"invoice":      {Name: "InvoiceID", Base: "uuid.UUID", Import: "github.com/google/uuid"},
+		// Named after the singular struct gorm/gen generates
+		"orders": {Name: "OrderID", Base: "int32"},
+	}
+
+	if len(ids) != len(expected) {
+		t.Fatalf("expected ids %+v; got %+v\n", expected, ids)
+	}
+
+	for tableName, id := range expected {
+		if ids[tableName] != id {
+			t.Fatalf("expected id %+v for table '%s'; got %+v\n", id, tableName, ids[tableName])
+		}
+	}
+}
+
+func TestWriteIDTypes(t *testing.T) {
+	var err error
+
+	modelDir := t.TempDir()
+	tables := []Table{
+		{Name: "user_profile", Columns: []Column{{Name: "id", DataType: "int4", PrimaryKey: true}}},
+		{Name: "invoice", Columns: []Column{{Name: "id", DataType: "uuid", PrimaryKey: true}}},
+	}
+	cfg := ModelConfig{
+		IDTypes: map[string]IDType{
+			"user_profile": {Name: "UserProfileID", Base: "int32"},
+			"invoice":      {Name: "InvoiceID", Base: "uuid.UUID", Import: "github.com/google/uuid"},
+		},
+	}
+
+	if err = os.WriteFile(filepath.Join(modelDir, "user_profile.gen.go"), []byte("package models\n"), 0644); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if err = WriteIDTypes(modelDir, tables, cfg); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	content, err := os.ReadFile(filepath.Join(modelDir, idTypesFile))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	f, err := parser.ParseFile(token.NewFileSet(), idTypesFile, content, parser.ImportsOnly)
+
+	if err != nil {
+		t.Fatalf("should generate valid go; %s\n", err.Error())
+	}
+
+	if f.Name.Name != "models" {
+		t.Fatalf("expected package 'models'; got '%s'\n", f.Name.Name)
+	}
+
+	for _, s := range []string{
+		"type UserProfileID int32",
+		"*id = UserProfileID(v.Int64)",
+		"return json.Marshal(int32(id))",
+		"type InvoiceID uuid.UUID",
+		"return (*uuid.UUID)(id).Scan(value)",
+		"return uuid.UUID(id).Value()",
+		`"github.com/google/uuid"`,
+	} {
+		if !strings.Contains(string(content), s) {
+			t.Fatalf("expected id types to contain '%s'; got:\n%s\n", s, string(content))
+		}
+	}
+}
+
+func TestModelConfigIDType(t *testing.T) {
+	cfg := ModelConfig{
+		IDTypes: map[string]IDType{"user_profile": {Name: "UserProfileID", Base: "int64"}},
+	}
+	table := Table{
+		Name: "phone",
+		Columns: []Column{
+			{Name: "id", DataType: "int8", PrimaryKey: true},
+			{Name: "user_profile_id", DataType: "int8"},
+		},
+		ForeignKeys: []ForeignKey{{ColumnName: "user_profile_id", ForeignTableName: "user_profile"}},
+	}
+
+	if id, ok := cfg.idType(table, table.Columns[1]); !ok || id.Name != "UserProfileID" {
+		t.Fatalf("foreign key should use the id of the referenced table; got %+v\n", id)
+	}
+
+	if _, ok := cfg.idType(table, table.Columns[0]); ok {
+		t.Fatalf("table without an id type should not get one\n")
+	}
+}
@@ -0,0 +1,254 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// jsonSchemaShapeTypes is the json schema type and format of every json shape,
+// where free form json has no type at all
+var jsonSchemaShapeTypes = map[jsonShape][2]string{
+	jsonString: {"string", ""},
+	jsonInt:    {"integer", ""},
+	jsonBigint: {"integer", ""},
+	jsonFloat:  {"number", ""},
+	jsonBool:   {"boolean", ""},
+	jsonTime:   {"string", "date-time"},
+	jsonBytes:  {"string", ""},
+}
+
+// jsonSchemaCheckKeywords is the keyword of every check constraint operator
+var jsonSchemaCheckKeywords = map[string]string{
+	">":  "exclusiveMinimum",
+	">=": "minimum",
+	"<":  "exclusiveMaximum",
+	"<=": "maximum",
+}
+
+// jsonObject is a json object which keeps its members in the order they were set
+type jsonObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newJSONObject() *jsonObject {
+	return &jsonObject{values: make(map[string]interface{})}
+}
+
+// set sets the member key to value, keeping the position of existing members
+func (o *jsonObject) set(key string, value interface{}) *jsonObject {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+
+	o.values[key] = value
+
+	return o
+}
+
+// MarshalJSON encodes the members in the order they were set
+func (o *jsonObject) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+
+	b.WriteString("{")
+
+	for i, key := range o.keys {
+		if i > 0 {
+			b.WriteString(",")
+		}
+
+		k, err := json.Marshal(key)
+
+		if err != nil {
+			return nil, err
+		}
+
+		v, err := json.Marshal(o.values[key])
+
+		if err != nil {
+			return nil, err
+		}
+
+		b.Write(k)
+		b.WriteString(":")
+		b.Write(v)
+	}
+
+	b.WriteString("}")
+
+	return b.Bytes(), nil
+}
+
+// GenerateJSONSchemas generates a json schema for every table within schemaDir
+// named after the table, eg. "user_profile.schema.json"
+//
+// Relations reference the schema of the table they lead to
+func GenerateJSONSchemas(tables []Table, schemaDir string, cfg GenerateConfig) error {
+	if schemaDir == "" {
+		return errors.WithStack(fmt.Errorf("model-gen: schemaDir parameter can't be empty"))
+	}
+
+	if err := os.MkdirAll(schemaDir, os.ModePerm); err != nil {
+		return errors.WithStack(err)
+	}
+
+	for _, table := range tables {
+		object := objectSchema(table, cfg, jsonSchemaFile, false)
+		schema := newJSONObject().
+			set("$schema", jsonSchemaDialect).
+			set("$id", jsonSchemaFile(table.Name))
+
+		for _, key := range object.keys {
+			schema.set(key, object.values[key])
+		}
+
+		if err := writeJSON(filepath.Join(schemaDir, jsonSchemaFile(table.Name)), schema); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// jsonSchemaFile returns the file name of the json schema of tableName
+func jsonSchemaFile(tableName string) string {
+	return tableName + ".schema.json"
+}
+
+// objectSchema returns the schema of the json object of table
+//
+// ref returns the reference to the schema of a table and openAPI adds the
+// integer formats of openapi
+func objectSchema(table Table, cfg GenerateConfig, ref func(string) string, openAPI bool) *jsonObject {
+	schema := newJSONObject().set("title", cfg.Model.structName(table.Name))
+
+	if table.Comment != "" {
+		schema.set("description", table.Comment)
+	}
+
+	schema.set("type", "object")
+
+	properties := newJSONObject()
+	required := []string{}
+
+	for _, col := range table.Columns {
+		name, omitEmpty, ok := cfg.Model.jsonField(table.Name, col)
+
+		// Fields hidden from json are never part of the api contract
+		if !ok {
+			continue
+		}
+
+		properties.set(name, columnSchema(table, col, cfg.Model, openAPI))
+
+		// Empty values of omitempty fields are left out of the json entirely
+		if !col.Nullable && !omitEmpty {
+			required = append(required, name)
+		}
+	}
+
+	// Relations are only set when they are loaded so they are never required
+	for _, fk := range table.ForeignKeys {
+		name, _ := cfg.Model.relationJSONField(table, fk)
+
+		properties.set(name, newJSONObject().set("anyOf", []interface{}{
+			newJSONObject().set("$ref", ref(fk.ForeignTableName)),
+			newJSONObject().set("type", "null"),
+		}))
+	}
+
+	schema.set("properties", properties)
+
+	if len(required) > 0 {
+		schema.set("required", required)
+	}
+
+	return schema
+}
+
+// columnSchema returns the schema of the json value of col of table
+func columnSchema(table Table, col Column, cfg ModelConfig, openAPI bool) *jsonObject {
+	schema := newJSONObject()
+
+	if col.Comment != "" {
+		schema.set("description", col.Comment)
+	}
+
+	kind := col.kind()
+	shape := cfg.jsonShape(table, col)
+	t, known := jsonSchemaShapeTypes[shape]
+
+	// Free form json can hold anything
+	if known {
+		if col.Nullable {
+			schema.set("type", []string{t[0], "null"})
+		} else {
+			schema.set("type", t[0])
+		}
+	}
+
+	switch {
+	case t[1] != "":
+		schema.set("format", t[1])
+	case shape == jsonString && kind == uuidKind:
+		schema.set("format", "uuid")
+	case openAPI && shape == jsonBigint:
+		schema.set("format", "int64")
+	case openAPI && shape == jsonInt:
+		schema.set("format", "int32")
+	case shape == jsonBytes:
+		schema.set("contentEncoding", "base64")
+	}
+
+	if cfg.enumColumn(table, col) {
+		values := make([]interface{}, 0, len(col.EnumValues)+1)
+
+		for _, value := range col.EnumValues {
+			values = append(values, value)
+		}
+		if col.Nullable {
+			values = append(values, nil)
+		}
+
+		schema.set("enum", values)
+	}
+
+	if col.MaxLength > 0 && t[0] == "string" {
+		schema.set("maxLength", col.MaxLength)
+	}
+
+	if shape == jsonInt || shape == jsonBigint || shape == jsonFloat {
+		for _, check := range col.Checks {
+			keyword, ok := jsonSchemaCheckKeywords[check.Operator]
+
+			if !ok {
+				continue
+			}
+
+			if value, err := strconv.ParseFloat(check.Value, 64); err == nil {
+				schema.set(keyword, value)
+			}
+		}
+	}
+
+	return schema
+}
+
+// writeJSON writes value as indented json into fileName
+func writeJSON(fileName string, value interface{}) error {
+	content, err := json.MarshalIndent(value, "", "  ")
+
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return errors.WithStack(os.WriteFile(fileName, append(content, '\n'), 0644))
+}
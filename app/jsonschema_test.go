@@ -0,0 +1,115 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateJSONSchemas(t *testing.T) {
+	var err error
+
+	schemaDir := filepath.Join(t.TempDir(), "schema")
+
+	if err = GenerateJSONSchemas(nil, "", GenerateConfig{}); err == nil {
+		t.Fatalf("should have error\n")
+	}
+
+	tables := []Table{
+		{
+			Name:    "user_profile",
+			Comment: "Person using the app",
+			Columns: []Column{
+				{Name: "id", DataType: "uuid"},
+				{Name: "name", DataType: "varchar", MaxLength: 50, Comment: "Display name"},
+				{Name: "status", DataType: "user_status", EnumValues: []string{"active", "on-hold"}, Nullable: true},
+				{Name: "age", DataType: "int4", Checks: []Check{{Operator: ">=", Value: "0"}}},
+				{Name: "created_at", DataType: "timestamptz"},
+			},
+		},
+		{
+			Name: "phone",
+			Columns: []Column{
+				{Name: "id", DataType: "int4"},
+				{Name: "user_profile_id", DataType: "uuid"},
+			},
+			ForeignKeys: []ForeignKey{
+				{ColumnName: "user_profile_id", ForeignTableName: "user_profile"},
+			},
+		},
+	}
+
+	cfg := GenerateConfig{
+		Model: ModelConfig{Tags: []TagGenerator{{Name: "json", Case: SnakeCase}}},
+	}
+
+	if err = GenerateJSONSchemas(tables, schemaDir, cfg); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	content, err := os.ReadFile(filepath.Join(schemaDir, "user_profile.schema.json"))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expected := `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "$id": "user_profile.schema.json",
+  "title": "UserProfile",
+  "description": "Person using the app",
+  "type": "object",
+  "properties": {
+    "id": {
+      "type": "string",
+      "format": "uuid"
+    },
+    "name": {
+      "description": "Display name",
+      "type": "string",
+      "maxLength": 50
+    },
+    "status": {
+      "type": [
+        "string",
+        "null"
+      ],
+      "enum": [
+        "active",
+        "on-hold",
+        null
+      ]
+    },
+    "age": {
+      "type": "integer",
+      "minimum": 0
+    },
+    "created_at": {
+      "type": "string",
+      "format": "date-time"
+    }
+  },
+  "required": [
+    "id",
+    "name",
+    "age",
+    "created_at"
+  ]
+}
+`
+
+	if string(content) != expected {
+		t.Fatalf("expected json schema output:\n%s\ngot:\n%s\n", expected, string(content))
+	}
+
+	if content, err = os.ReadFile(filepath.Join(schemaDir, "phone.schema.json")); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	s := `"$ref": "user_profile.schema.json"`
+
+	if !strings.Contains(string(content), s) {
+		t.Fatalf("expected phone schema to contain '%s'; got:\n%s\n", s, string(content))
+	}
+}
@@ -0,0 +1,196 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kenshaw/snaker"
+	"github.com/pkg/errors"
+)
+
+// kotlinShapeTypes is the kotlin type of every json shape
+var kotlinShapeTypes = map[jsonShape]string{
+	jsonAny:    "JsonElement",
+	jsonString: "String",
+	jsonInt:    "Int",
+	jsonBigint: "Long",
+	jsonFloat:  "Double",
+	jsonBool:   "Boolean",
+	jsonTime:   "String",
+	jsonBytes:  "String",
+}
+
+// kotlinKeywords are the hard keywords of kotlin which have to be escaped with
+// backticks when used as property names
+var kotlinKeywords = map[string]bool{
+	"as": true, "break": true, "class": true, "continue": true, "do": true,
+	"else": true, "false": true, "for": true, "fun": true, "if": true,
+	"in": true, "interface": true, "is": true, "null": true, "object": true,
+	"package": true, "return": true, "super": true, "this": true, "throw": true,
+	"true": true, "try": true, "typealias": true, "typeof": true, "val": true,
+	"var": true, "when": true, "while": true,
+}
+
+const kotlinJSONElement = "kotlinx.serialization.json.JsonElement"
+
+// GenerateKotlinModels generates a kotlinx.serialization data class for every
+// table in a single file named kotlinFile within kotlinDir
+//
+// When kotlinFile is empty every table is generated into its own file named
+// after its class instead.  Properties are serialized with the json names of the
+// go models and enum columns get an enum class of their own
+func GenerateKotlinModels(tables []Table, kotlinDir, kotlinFile string, cfg GenerateConfig) error {
+	if kotlinDir == "" {
+		return errors.WithStack(fmt.Errorf("model-gen: kotlinDir parameter can't be empty"))
+	}
+
+	if err := os.MkdirAll(kotlinDir, os.ModePerm); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if kotlinFile != "" {
+		if !strings.HasSuffix(kotlinFile, ".kt") {
+			kotlinFile += ".kt"
+		}
+
+		return writeKotlinFile(filepath.Join(kotlinDir, kotlinFile), tables, cfg)
+	}
+
+	for _, table := range tables {
+		fileName := filepath.Join(kotlinDir, cfg.Model.structName(table.Name)+".kt")
+
+		if err := writeKotlinFile(fileName, []Table{table}, cfg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeKotlinFile writes the classes of tables into fileName along with the
+// package declaration and imports they need
+func writeKotlinFile(fileName string, tables []Table, cfg GenerateConfig) error {
+	var b, classes strings.Builder
+
+	imports := map[string]bool{
+		"kotlinx.serialization.SerialName":   true,
+		"kotlinx.serialization.Serializable": true,
+	}
+
+	for _, table := range tables {
+		classes.WriteString("\n")
+		classes.WriteString(kotlinClass(table, cfg))
+
+		for _, col := range table.Columns {
+			if kotlinType(table, col, cfg) == "JsonElement" {
+				imports[kotlinJSONElement] = true
+			}
+		}
+	}
+
+	if cfg.Package != "" {
+		b.WriteString(fmt.Sprintf("package %s\n\n", cfg.Package))
+	}
+
+	for _, importPath := range sortedKeys(imports) {
+		b.WriteString(fmt.Sprintf("import %s\n", importPath))
+	}
+
+	b.WriteString(classes.String())
+
+	return errors.WithStack(os.WriteFile(fileName, []byte(b.String()), 0644))
+}
+
+// kotlinClass returns the data class of table followed by the enum classes of
+// its enum columns
+func kotlinClass(table Table, cfg GenerateConfig) string {
+	var b, enums strings.Builder
+
+	b.WriteString(docBlock(table.Comment, ""))
+	b.WriteString("@Serializable\n")
+	b.WriteString(fmt.Sprintf("data class %s(\n", cfg.Model.structName(table.Name)))
+
+	for _, col := range table.Columns {
+		name, omitEmpty, ok := cfg.Model.jsonField(table.Name, col)
+
+		// Fields hidden from json are never part of the api contract
+		if !ok {
+			continue
+		}
+
+		b.WriteString(docBlock(col.Comment, "    "))
+		b.WriteString(kotlinProperty(name, col.Name, kotlinType(table, col, cfg), col.Nullable || omitEmpty))
+
+		if cfg.Model.enumColumn(table, col) {
+			enums.WriteString(kotlinEnum(cfg.Model.enumName(table.Name, col), col.EnumValues))
+		}
+	}
+
+	// Relations are only set when they are loaded so they are always nullable
+	for _, fk := range table.ForeignKeys {
+		name, _ := cfg.Model.relationJSONField(table, fk)
+
+		b.WriteString(kotlinProperty(
+			name,
+			cfg.Model.relationName(table, fk),
+			cfg.Model.structName(fk.ForeignTableName),
+			true,
+		))
+	}
+
+	b.WriteString(")\n")
+	b.WriteString(enums.String())
+
+	return b.String()
+}
+
+// kotlinProperty returns the constructor property of a field serialized as
+// jsonName
+//
+// Nullable properties default to null so missing fields decode as well
+func kotlinProperty(jsonName, columnName, propertyType string, nullable bool) string {
+	name := snaker.ForceLowerCamelIdentifier(columnName)
+
+	if kotlinKeywords[name] {
+		name = "`" + name + "`"
+	}
+
+	if nullable {
+		return fmt.Sprintf("    @SerialName(%s) val %s: %s? = null,\n", kotlinString(jsonName), name, propertyType)
+	}
+
+	return fmt.Sprintf("    @SerialName(%s) val %s: %s,\n", kotlinString(jsonName), name, propertyType)
+}
+
+// kotlinEnum returns an enum class named name serializing every entry as its
+// database value
+func kotlinEnum(name string, values []string) string {
+	var b strings.Builder
+
+	b.WriteString("\n@Serializable\n")
+	b.WriteString(fmt.Sprintf("enum class %s {\n", name))
+
+	for i, constant := range enumConstants(values, screamingEnumConstant) {
+		b.WriteString(fmt.Sprintf("    @SerialName(%s) %s,\n", kotlinString(values[i]), constant))
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// kotlinType returns the kotlin type of col of table without nullability
+func kotlinType(table Table, col Column, cfg GenerateConfig) string {
+	if cfg.Model.enumColumn(table, col) {
+		return cfg.Model.enumName(table.Name, col)
+	}
+
+	return kotlinShapeTypes[cfg.Model.jsonShape(table, col)]
+}
+
+// kotlinString returns value as a kotlin string literal
+func kotlinString(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "$", `\$`).Replace(value) + `"`
+}
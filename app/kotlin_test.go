@@ -0,0 +1,174 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateKotlinModels(t *testing.T) {
+	var err error
+
+	kotlinDir := filepath.Join(t.TempDir(), "kotlin")
+
+	if err = GenerateKotlinModels(nil, "", "", GenerateConfig{}); err == nil {
+		t.Fatalf("should have error\n")
+	}
+
+	tables := []Table{
+		{
+			Name: "user_profile",
+			Columns: []Column{
+				{Name: "id", DataType: "int8"},
+				{Name: "settings", DataType: "jsonb"},
+			},
+		},
+		{
+			Name: "orders",
+			Columns: []Column{
+				{Name: "id", DataType: "uuid"},
+			},
+		},
+	}
+
+	cfg := GenerateConfig{
+		Package: "com.acme.models",
+		Model: ModelConfig{
+			Tables: map[string]TableConfig{
+				"user_profile": {Columns: map[string]ColumnConfig{"settings": {GoType: "datatypes.JSON"}}},
+			},
+		},
+	}
+
+	if err = GenerateKotlinModels(tables, kotlinDir, "Models", cfg); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	content, err := os.ReadFile(filepath.Join(kotlinDir, "Models.kt"))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expected := "package com.acme.models\n\n" +
+		"import kotlinx.serialization.SerialName\n" +
+		"import kotlinx.serialization.Serializable\n" +
+		"import kotlinx.serialization.json.JsonElement\n\n" +
+		"@Serializable\n" +
+		"data class UserProfile(\n"
+
+	if !strings.HasPrefix(string(content), expected) {
+		t.Fatalf("expected kotlin output to start with:\n%s\ngot:\n%s\n", expected, string(content))
+	}
+
+	if err = GenerateKotlinModels(tables, kotlinDir, "", GenerateConfig{}); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	for _, name := range []string{"UserProfile.kt", "Order.kt"} {
+		if _, err = os.Stat(filepath.Join(kotlinDir, name)); err != nil {
+			t.Fatalf("expected file %s; %s\n", name, err.Error())
+		}
+	}
+}
+
+func TestKotlinClass(t *testing.T) {
+	tests := []struct {
+		name     string
+		table    Table
+		cfg      GenerateConfig
+		expected string
+	}{
+		{
+			name: "columns",
+			table: Table{
+				Name:    "user_profile",
+				Comment: "Person using the app",
+				Columns: []Column{
+					{Name: "id", DataType: "int8"},
+					{Name: "name", DataType: "varchar", Nullable: true, Comment: "Display name"},
+					{Name: "object", DataType: "jsonb"},
+					{Name: "password_hash", DataType: "text"},
+				},
+			},
+			cfg: GenerateConfig{
+				Model: ModelConfig{
+					Tables: map[string]TableConfig{
+						"user_profile": {Columns: map[string]ColumnConfig{"password_hash": {JSONOmit: true}}},
+					},
+				},
+			},
+			expected: "/** Person using the app */\n" +
+				"@Serializable\n" +
+				"data class UserProfile(\n" +
+				"    @SerialName(\"id\") val id: Long,\n" +
+				"    /** Display name */\n" +
+				"    @SerialName(\"name\") val name: String? = null,\n" +
+				"    @SerialName(\"object\") val `object`: String,\n" +
+				")\n",
+		},
+		{
+			name: "enum",
+			table: Table{
+				Name: "user_profile",
+				Columns: []Column{
+					{Name: "status", DataType: "user_status", EnumValues: []string{"active", "on-hold", "1st"}},
+				},
+			},
+			expected: "@Serializable\n" +
+				"data class UserProfile(\n" +
+				"    @SerialName(\"status\") val status: UserProfileStatus,\n" +
+				")\n\n" +
+				"@Serializable\n" +
+				"enum class UserProfileStatus {\n" +
+				"    @SerialName(\"active\") ACTIVE,\n" +
+				"    @SerialName(\"on-hold\") ON_HOLD,\n" +
+				"    @SerialName(\"1st\") _1ST,\n" +
+				"}\n",
+		},
+		{
+			name: "plural table",
+			table: Table{
+				Name:    "orders",
+				Columns: []Column{{Name: "id", DataType: "uuid"}},
+			},
+			expected: "@Serializable\n" +
+				"data class Order(\n" +
+				"    @SerialName(\"id\") val id: String,\n" +
+				")\n",
+		},
+		{
+			name: "self relation",
+			table: Table{
+				Name:        "employee",
+				Columns:     []Column{{Name: "manager_id", DataType: "int4", Nullable: true}},
+				ForeignKeys: []ForeignKey{{ColumnName: "manager_id", ForeignTableName: "employee"}},
+			},
+			expected: "@Serializable\n" +
+				"data class Employee(\n" +
+				"    @SerialName(\"managerID\") val managerID: Int? = null,\n" +
+				"    @SerialName(\"manager\") val manager: Employee? = null,\n" +
+				")\n",
+		},
+		{
+			name: "relation without id suffix",
+			table: Table{
+				Name:        "orders",
+				Columns:     []Column{{Name: "buyer", DataType: "int8"}},
+				ForeignKeys: []ForeignKey{{ColumnName: "buyer", ForeignTableName: "user_profile"}},
+			},
+			expected: "@Serializable\n" +
+				"data class Order(\n" +
+				"    @SerialName(\"buyer\") val buyer: Long,\n" +
+				"    @SerialName(\"buyerUserProfile\") val buyerUserProfile: UserProfile? = null,\n" +
+				")\n",
+		},
+	}
+
+	for _, test := range tests {
+		if class := kotlinClass(test.table, test.cfg); class != test.expected {
+			t.Fatalf("%s: expected kotlin class:\n%s\ngot:\n%s\n", test.name, test.expected, class)
+		}
+	}
+}
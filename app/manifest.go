@@ -0,0 +1,195 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultManifestFile is the file the generation manifest is kept in when none is given
+	DefaultManifestFile = ".model_gen_manifest.json"
+)
+
+var (
+	ErrReadManifest  = errors.New("model-gen: read manifest error")
+	ErrWriteManifest = errors.New("model-gen: write manifest error")
+)
+
+// Manifest records every file model-gen generated along with a hash of the content
+// it was generated with
+//
+// Paths are stored relative to the directory of the manifest file so the manifest
+// can be committed alongside the generated code
+type Manifest struct {
+	path  string
+	Files map[string]string `json:"files"`
+}
+
+// ReadManifest reads the manifest at path, returning an empty manifest if the file
+// does not exist yet
+func ReadManifest(path string) (*Manifest, error) {
+	if path == "" {
+		path = DefaultManifestFile
+	}
+
+	absPath, err := filepath.Abs(path)
+
+	if err != nil {
+		return nil, fmt.Errorf(packageErr, ErrReadManifest, err.Error())
+	}
+
+	m := &Manifest{
+		path:  absPath,
+		Files: make(map[string]string),
+	}
+
+	content, err := os.ReadFile(absPath)
+
+	if err != nil {
+		if os.IsNotExist(err) {
+			return m, nil
+		}
+
+		return nil, fmt.Errorf(packageErr, ErrReadManifest, err.Error())
+	}
+
+	if err = json.Unmarshal(content, m); err != nil {
+		return nil, fmt.Errorf(packageErr, ErrReadManifest, err.Error())
+	}
+
+	if m.Files == nil {
+		m.Files = make(map[string]string)
+	}
+
+	return m, nil
+}
+
+// Save writes the manifest back to the file it was read from
+func (m *Manifest) Save() error {
+	content, err := json.MarshalIndent(m, "", "\t")
+
+	if err != nil {
+		return fmt.Errorf(packageErr, ErrWriteManifest, err.Error())
+	}
+
+	if err = os.WriteFile(m.path, append(content, '\n'), 0640); err != nil {
+		return fmt.Errorf(packageErr, ErrWriteManifest, err.Error())
+	}
+
+	return nil
+}
+
+// Record hashes the current content of every file given and records it as generated
+func (m *Manifest) Record(files ...string) error {
+	for _, file := range files {
+		hash, err := hashFile(file)
+
+		if err != nil {
+			return fmt.Errorf(packageErr, ErrWriteManifest, err.Error())
+		}
+
+		key, err := m.key(file)
+
+		if err != nil {
+			return fmt.Errorf(packageErr, ErrWriteManifest, err.Error())
+		}
+
+		m.Files[key] = hash
+	}
+
+	return nil
+}
+
+// Forget removes file from the manifest
+func (m *Manifest) Forget(file string) {
+	if key, err := m.key(file); err == nil {
+		delete(m.Files, key)
+	}
+}
+
+// FilesWithin returns the absolute path of every recorded file that lives within dir
+func (m *Manifest) FilesWithin(dir string) ([]string, error) {
+	var files []string
+
+	absDir, err := filepath.Abs(dir)
+
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	for key := range m.Files {
+		file := filepath.Join(filepath.Dir(m.path), filepath.FromSlash(key))
+
+		if rel, err := filepath.Rel(absDir, file); err == nil && !strings.HasPrefix(rel, "..") {
+			files = append(files, file)
+		}
+	}
+
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// Unmodified returns whether file still has the content it was generated with
+//
+// Files that are not recorded in the manifest are never considered unmodified
+func (m *Manifest) Unmodified(file string) (bool, error) {
+	key, err := m.key(file)
+
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	recorded, ok := m.Files[key]
+
+	if !ok {
+		return false, nil
+	}
+
+	hash, err := hashFile(file)
+
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	return hash == recorded, nil
+}
+
+func (m *Manifest) key(file string) (string, error) {
+	absFile, err := filepath.Abs(file)
+
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(filepath.Dir(m.path), absFile)
+
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.ToSlash(rel), nil
+}
+
+func hashFile(file string) (string, error) {
+	content, err := os.ReadFile(file)
+
+	if err != nil {
+		return "", err
+	}
+
+	return hashContent(content), nil
+}
+
+func hashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestManifest(t *testing.T) {
+	var err error
+
+	root := t.TempDir()
+	manifestFile := filepath.Join(root, DefaultManifestFile)
+	modelFile := filepath.Join(root, "model", "user.gen.go")
+
+	manifest, err := ReadManifest(manifestFile)
+
+	if err != nil {
+		t.Fatalf("missing manifest should not have error; %s\n", err.Error())
+	}
+
+	if len(manifest.Files) != 0 {
+		t.Fatalf("missing manifest should be empty; got %v\n", manifest.Files)
+	}
+
+	if err = os.MkdirAll(filepath.Dir(modelFile), os.ModePerm); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if err = os.WriteFile(modelFile, []byte("package model\n"), 0640); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if err = manifest.Record(modelFile); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if err = manifest.Save(); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if manifest, err = ReadManifest(manifestFile); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if _, ok := manifest.Files["model/user.gen.go"]; !ok {
+		t.Fatalf("manifest should record path relative to manifest; got %v\n", manifest.Files)
+	}
+
+	files, err := manifest.FilesWithin(filepath.Join(root, "model"))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(files) != 1 || files[0] != modelFile {
+		t.Fatalf("expected only '%s' within model dir; got %v\n", modelFile, files)
+	}
+
+	if files, err = manifest.FilesWithin(filepath.Join(root, "query")); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(files) != 0 {
+		t.Fatalf("expected no files within query dir; got %v\n", files)
+	}
+
+	unmodified, err := manifest.Unmodified(modelFile)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if !unmodified {
+		t.Fatalf("file should be unmodified\n")
+	}
+
+	if err = os.WriteFile(modelFile, []byte("package model\n\n// edited\n"), 0640); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if unmodified, err = manifest.Unmodified(modelFile); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if unmodified {
+		t.Fatalf("file should be modified\n")
+	}
+}
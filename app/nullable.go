@@ -0,0 +1,312 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// NullableStrategy decides the go type of nullable columns
+type NullableStrategy string
+
+var (
+	// NullableNone uses the plain type, eg. "string"
+	NullableNone NullableStrategy = "none"
+
+	// NullablePointer uses a pointer to the type, eg. "*string"
+	NullablePointer NullableStrategy = "pointer"
+
+	// NullableSQL uses the database/sql null types, eg. "sql.NullString"
+	//
+	// Types database/sql has no null type for fall back to a pointer
+	NullableSQL NullableStrategy = "sql"
+
+	// NullableDatatypes uses the generic null type of gorm.io/datatypes v1.2.4 and
+	// later, eg. "datatypes.Null[string]"
+	NullableDatatypes NullableStrategy = "datatypes"
+
+	// NullableGeneric uses the user supplied generic type of NullableConfig.GenericType
+	NullableGeneric NullableStrategy = "generic"
+)
+
+var (
+	ErrInvalidNullable = errors.New("model-gen: invalid nullable config")
+)
+
+var nullableStrategyMap = map[NullableStrategy]bool{
+	NullableNone:      true,
+	NullablePointer:   true,
+	NullableSQL:       true,
+	NullableDatatypes: true,
+	NullableGeneric:   true,
+}
+
+// sqlNullTypes are the database/sql null types keyed by the type they wrap
+var sqlNullTypes = map[string]string{
+	"string":    "sql.NullString",
+	"int64":     "sql.NullInt64",
+	"int32":     "sql.NullInt32",
+	"int16":     "sql.NullInt16",
+	"uint8":     "sql.NullByte",
+	"byte":      "sql.NullByte",
+	"float64":   "sql.NullFloat64",
+	"bool":      "sql.NullBool",
+	"time.Time": "sql.NullTime",
+}
+
+// NullableConfig decides the go type of nullable columns per type
+type NullableConfig struct {
+	// Strategy applies to every type without an entry in Types
+	Strategy NullableStrategy
+
+	// Types overrides the strategy keyed by the go type of the column, eg. "time.Time"
+	//
+	// Go types are matched case insensitively
+	Types map[string]NullableStrategy
+
+	// GenericType is the generic type used by NullableGeneric including its import
+	// path, eg. "github.com/acme/null.Value" generates "null.Value[string]"
+	GenericType string
+}
+
+// Validate checks every strategy is known and a generic type is set when used
+func (c NullableConfig) Validate() error {
+	strategies := []NullableStrategy{c.Strategy}
+
+	for _, goType := range sortedKeys(c.Types) {
+		strategies = append(strategies, c.Types[goType])
+	}
+
+	for _, strategy := range strategies {
+		if strategy == "" {
+			continue
+		}
+
+		if _, ok := nullableStrategyMap[strategy]; !ok {
+			return fmt.Errorf(
+				packageErr,
+				ErrInvalidNullable,
+				fmt.Sprintf(
+					"unknown strategy '%s'.  Options are 'none', 'pointer', 'sql', 'datatypes', 'generic'",
+					strategy,
+				),
+			)
+		}
+
+		if typeName := path.Base(c.GenericType); strategy == NullableGeneric &&
+			(c.GenericType == "" || !strings.Contains(strings.Trim(typeName, "."), ".")) {
+			return fmt.Errorf(
+				packageErr,
+				ErrInvalidNullable,
+				"generic strategy requires a generic type like 'github.com/acme/null.Value'",
+			)
+		}
+	}
+
+	return nil
+}
+
+// Pointers returns whether gorm/gen has to generate nullable fields as pointers
+// for ApplyNullableTypes to find them
+func (c NullableConfig) Pointers() bool {
+	if c.Strategy != "" && c.Strategy != NullableNone {
+		return true
+	}
+
+	for _, strategy := range c.Types {
+		if strategy != NullableNone {
+			return true
+		}
+	}
+
+	return false
+}
+
+// goType returns the go type and import path a nullable column of goType is
+// generated as
+func (c NullableConfig) goType(goType string) (string, string) {
+	strategy := c.Strategy
+
+	// Config keys are lower cased when read so types are matched case insensitively
+	for t, s := range c.Types {
+		if strings.EqualFold(t, goType) {
+			strategy = s
+			break
+		}
+	}
+
+	switch strategy {
+	case NullableNone:
+		return goType, ""
+	case NullableSQL:
+		if nullType, ok := sqlNullTypes[goType]; ok {
+			return nullType, "database/sql"
+		}
+	case NullableDatatypes:
+		return "datatypes.Null[" + goType + "]", "gorm.io/datatypes"
+	case NullableGeneric:
+		importPath := c.GenericType[:strings.LastIndex(c.GenericType, ".")]
+		typeName := c.GenericType[strings.LastIndex(c.GenericType, ".")+1:]
+		return path.Base(importPath) + "." + typeName + "[" + goType + "]", importPath
+	}
+
+	return "*" + goType, ""
+}
+
+// ApplyNullableTypes rewrites the pointer fields gorm/gen generates for nullable
+// columns in modelDir to the type cfg.Nullable picks for them
+//
+// gorm/gen only knows pointers so nullable fields are generated as pointers and
+// rewritten afterwards.  Fields whose type was overridden are left alone
+func ApplyNullableTypes(modelDir string, tables []Table, cfg ModelConfig) error {
+	if !cfg.Nullable.Pointers() {
+		return nil
+	}
+
+	nullableColumns := make(map[string]map[string]bool)
+
+	for _, table := range tables {
+		columns := make(map[string]bool)
+
+		for _, col := range table.Columns {
+			columns[col.Name] = col.Nullable
+		}
+
+		nullableColumns[cfg.structName(table.Name)] = columns
+	}
+
+	files, err := filepath.Glob(filepath.Join(modelDir, "*.go"))
+
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	for _, file := range files {
+		if err = applyNullableTypes(file, nullableColumns, cfg.Nullable); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func applyNullableTypes(file string, nullableColumns map[string]map[string]bool, cfg NullableConfig) error {
+	type replacement struct {
+		start, end int
+		goType     string
+	}
+
+	var replacements []replacement
+
+	content, err := os.ReadFile(file)
+
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, content, parser.ParseComments)
+
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	importPaths := make(map[string]bool)
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		typeSpec, ok := n.(*ast.TypeSpec)
+
+		if !ok {
+			return true
+		}
+
+		structType, ok := typeSpec.Type.(*ast.StructType)
+		columns := nullableColumns[typeSpec.Name.Name]
+
+		if !ok || columns == nil {
+			return false
+		}
+
+		for _, field := range structType.Fields.List {
+			star, ok := field.Type.(*ast.StarExpr)
+
+			if !ok || field.Tag == nil || !columns[tagColumnName(field.Tag.Value)] {
+				continue
+			}
+
+			goType := types.ExprString(star.X)
+			nullType, importPath := cfg.goType(goType)
+
+			if nullType == "*"+goType {
+				continue
+			}
+
+			replacements = append(replacements, replacement{
+				start:  fset.Position(star.Pos()).Offset,
+				end:    fset.Position(star.End()).Offset,
+				goType: nullType,
+			})
+
+			if importPath != "" {
+				importPaths[importPath] = true
+			}
+		}
+
+		return false
+	})
+
+	if len(replacements) == 0 {
+		return nil
+	}
+
+	// Replace from the end so earlier offsets stay valid
+	sort.Slice(replacements, func(i, j int) bool {
+		return replacements[i].start > replacements[j].start
+	})
+
+	for _, r := range replacements {
+		content = append(content[:r.start], append([]byte(r.goType), content[r.end:]...)...)
+	}
+
+	fset = token.NewFileSet()
+
+	if f, err = parser.ParseFile(fset, file, content, parser.ParseComments); err != nil {
+		return errors.WithStack(err)
+	}
+
+	for _, importPath := range sortedKeys(importPaths) {
+		astutil.AddImport(fset, f, importPath)
+	}
+
+	var buf bytes.Buffer
+
+	if err = format.Node(&buf, fset, f); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return errors.WithStack(os.WriteFile(file, buf.Bytes(), 0644))
+}
+
+// tagColumnName returns the column name of a gorm/gen struct tag
+func tagColumnName(tag string) string {
+	for _, setting := range strings.Split(reflect.StructTag(strings.Trim(tag, "`")).Get("gorm"), ";") {
+		if strings.HasPrefix(setting, "column:") {
+			return strings.TrimPrefix(setting, "column:")
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,149 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNullableConfig(t *testing.T) {
+	var err error
+
+	if err = (NullableConfig{Strategy: "maybe"}).Validate(); !errors.Is(err, ErrInvalidNullable) {
+		t.Fatalf("should have error %v; got %v\n", ErrInvalidNullable, err)
+	}
+
+	if err = (NullableConfig{Strategy: NullableGeneric}).Validate(); !errors.Is(err, ErrInvalidNullable) {
+		t.Fatalf("should have error %v; got %v\n", ErrInvalidNullable, err)
+	}
+
+	if (NullableConfig{Strategy: NullableNone}).Pointers() {
+		t.Fatalf("none strategy should not need pointers\n")
+	}
+
+	cfg := NullableConfig{
+		Strategy:    NullableSQL,
+		GenericType: "github.com/acme/null.Value",
+		Types: map[string]NullableStrategy{
+			"time.time": NullableGeneric,
+			"int64":     NullableDatatypes,
+			"bool":      NullableNone,
+		},
+	}
+
+	if err = cfg.Validate(); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	tests := []struct {
+		goType     string
+		expected   string
+		importPath string
+	}{
+		{"string", "sql.NullString", "database/sql"},
+		{"float32", "*float32", ""},
+		{"time.Time", "null.Value[time.Time]", "github.com/acme/null"},
+		{"int64", "datatypes.Null[int64]", "gorm.io/datatypes"},
+		{"bool", "bool", ""},
+	}
+
+	for _, test := range tests {
+		if goType, importPath := cfg.goType(test.goType); goType != test.expected || importPath != test.importPath {
+			t.Fatalf(
+				"expected (%s, %s) for '%s'; got (%s, %s)\n",
+				test.expected, test.importPath, test.goType, goType, importPath,
+			)
+		}
+	}
+}
+
+func TestApplyNullableTypes(t *testing.T) {
+	var err error
+
+	modelDir := t.TempDir()
+	userModel := `package model
+
+import (
+	"time"
+)
+
+const TableNameUserProfile = "user_profile"
+
+// UserProfile mapped from table <user_profile>
+type UserProfile struct {
+	ID        int32      ` + "`" + `gorm:"column:id;primaryKey" json:"id"` + "`" + `
+	Name      *string    ` + "`" + `gorm:"column:name" json:"name"` + "`" + ` // Full name
+	Score     *float64   ` + "`" + `gorm:"column:score;default:0" json:"score"` + "`" + `
+	DeletedAt *time.Time ` + "`" + `gorm:"column:deleted_at" json:"deletedAt"` + "`" + `
+	Phone     *Phone     ` + "`" + `json:"phone"` + "`" + `
+}
+`
+
+	if err = os.WriteFile(filepath.Join(modelDir, "user_profile.gen.go"), []byte(userModel), 0640); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	orderModel := `package model
+
+const TableNameOrder = "orders"
+
+// Order mapped from table <orders>
+type Order struct {
+	Note *string ` + "`" + `gorm:"column:note" json:"note"` + "`" + `
+}
+`
+
+	if err = os.WriteFile(filepath.Join(modelDir, "orders.gen.go"), []byte(orderModel), 0640); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	tables := []Table{
+		{
+			Name: "user_profile",
+			Columns: []Column{
+				{Name: "id"},
+				{Name: "name", Nullable: true},
+				{Name: "score", HasDefault: true},
+				{Name: "deleted_at", Nullable: true},
+			},
+		},
+		{
+			Name:    "orders",
+			Columns: []Column{{Name: "note", Nullable: true}},
+		},
+	}
+
+	cfg := ModelConfig{Nullable: NullableConfig{Strategy: NullableSQL}}
+
+	if err = ApplyNullableTypes(modelDir, tables, cfg); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	content, err := os.ReadFile(filepath.Join(modelDir, "user_profile.gen.go"))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	for _, expected := range []string{
+		`"database/sql"`,
+		"Name      sql.NullString `gorm:\"column:name\" json:\"name\"` // Full name",
+		"Score     *float64",
+		"DeletedAt sql.NullTime",
+		"Phone     *Phone",
+	} {
+		if !strings.Contains(string(content), expected) {
+			t.Fatalf("expected model to contain '%s'; got:\n%s\n", expected, string(content))
+		}
+	}
+	// Structs of plural tables are named after the singular like gorm/gen does
+	if content, err = os.ReadFile(filepath.Join(modelDir, "orders.gen.go")); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if expected := "Note sql.NullString"; !strings.Contains(string(content), expected) {
+		t.Fatalf("expected model to contain '%s'; got:\n%s\n", expected, string(content))
+	}
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	openAPIVersion     = "3.1.0"
+	defaultOpenAPIFile = "openapi.json"
+)
+
+// GenerateOpenAPIComponents generates an openapi 3.1 document named openAPIFile
+// within openAPIDir holding the schema of every table under components.schemas
+//
+// Schemas are keyed by struct name and relations reference the schema of the
+// table they lead to.  The info of the document is cfg.Title and cfg.Version
+func GenerateOpenAPIComponents(tables []Table, openAPIDir, openAPIFile string, cfg GenerateConfig) error {
+	if openAPIDir == "" {
+		return errors.WithStack(fmt.Errorf("model-gen: openAPIDir parameter can't be empty"))
+	}
+
+	if err := os.MkdirAll(openAPIDir, os.ModePerm); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if openAPIFile == "" {
+		openAPIFile = defaultOpenAPIFile
+	}
+	if !strings.HasSuffix(openAPIFile, ".json") {
+		openAPIFile += ".json"
+	}
+
+	ref := func(tableName string) string {
+		return "#/components/schemas/" + cfg.Model.structName(tableName)
+	}
+
+	schemas := newJSONObject()
+
+	for _, table := range tables {
+		schemas.set(cfg.Model.structName(table.Name), objectSchema(table, cfg, ref, true))
+	}
+
+	info := newJSONObject().
+		set("title", defaultString(cfg.Title, "Models")).
+		set("version", defaultString(cfg.Version, "1.0.0"))
+	document := newJSONObject().
+		set("openapi", openAPIVersion).
+		set("info", info).
+		set("components", newJSONObject().set("schemas", schemas))
+
+	return writeJSON(filepath.Join(openAPIDir, openAPIFile), document)
+}
+
+// defaultString returns value, or def if value is empty
+func defaultString(value, def string) string {
+	if value == "" {
+		return def
+	}
+
+	return value
+}
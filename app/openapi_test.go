@@ -0,0 +1,74 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateOpenAPIComponents(t *testing.T) {
+	var err error
+
+	openAPIDir := filepath.Join(t.TempDir(), "openapi")
+
+	if err = GenerateOpenAPIComponents(nil, "", "", GenerateConfig{}); err == nil {
+		t.Fatalf("should have error\n")
+	}
+
+	tables := []Table{
+		{
+			Name: "user_profile",
+			Columns: []Column{
+				{Name: "id", DataType: "int8"},
+				{Name: "code", DataType: "int4", Nullable: true},
+			},
+		},
+		{
+			Name: "phone",
+			Columns: []Column{
+				{Name: "id", DataType: "int4"},
+				{Name: "user_profile_id", DataType: "int8"},
+			},
+			ForeignKeys: []ForeignKey{
+				{ColumnName: "user_profile_id", ForeignTableName: "user_profile"},
+			},
+		},
+	}
+
+	cfg := GenerateConfig{Title: "Acme", Version: "2.0.0"}
+
+	if err = GenerateOpenAPIComponents(tables, openAPIDir, "api", cfg); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	content, err := os.ReadFile(filepath.Join(openAPIDir, "api.json"))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	for _, s := range []string{
+		"{\n  \"openapi\": \"3.1.0\",\n  \"info\": {\n    \"title\": \"Acme\",\n    \"version\": \"2.0.0\"\n  },\n",
+		"      \"UserProfile\": {\n        \"title\": \"UserProfile\",\n",
+		"\"id\": {\n            \"type\": \"integer\",\n            \"format\": \"int64\"\n",
+		"\"type\": [\n              \"integer\",\n              \"null\"\n            ],\n            \"format\": \"int32\"\n",
+		"\"$ref\": \"#/components/schemas/UserProfile\"",
+	} {
+		if !strings.Contains(string(content), s) {
+			t.Fatalf("expected openapi document to contain '%s'; got:\n%s\n", s, string(content))
+		}
+	}
+
+	if err = GenerateOpenAPIComponents(tables, openAPIDir, "", GenerateConfig{}); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	if content, err = os.ReadFile(filepath.Join(openAPIDir, "openapi.json")); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if s := `"title": "Models"`; !strings.Contains(string(content), s) {
+		t.Fatalf("expected openapi document to contain '%s'; got:\n%s\n", s, string(content))
+	}
+}
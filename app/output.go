@@ -0,0 +1,374 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultQueryOutPath is the directory query code is generated to when none is given
+	DefaultQueryOutPath = "./query"
+
+	// DefaultModelOutPath is the directory model code is generated to when none is given
+	DefaultModelOutPath = "./model"
+
+	// stagePrefix is the prefix of every staging directory
+	//
+	// The underscore makes the go tool ignore staged packages when running
+	// commands like "go build ./..." while a generation is in progress
+	stagePrefix = "_model_gen_stage_"
+)
+
+var (
+	ErrStageOutput  = errors.New("model-gen: stage output error")
+	ErrCommitOutput = errors.New("model-gen: commit output error")
+)
+
+// OutputStage stages generated output in temporary directories and only moves it
+// into its final destination once every generator has succeeded
+//
+// Each staging directory is created next to the directory it stands in for so the
+// final move is a rename on the same filesystem and the staged directory keeps the
+// same base name, which gorm/gen uses as the package name
+type OutputStage struct {
+	dirs  map[string]*stagedDir
+	order []string
+}
+
+type stagedDir struct {
+	finalDir  string
+	stageRoot string
+	stagedDir string
+}
+
+type stagedMove struct {
+	stagedPath string
+	finalPath  string
+	backupPath string
+}
+
+// NewOutputStage returns an empty OutputStage
+func NewOutputStage() *OutputStage {
+	return &OutputStage{
+		dirs: make(map[string]*stagedDir),
+	}
+}
+
+// Dir returns the staged directory that stands in for finalDir, creating it on first use
+func (o *OutputStage) Dir(finalDir string) (string, error) {
+	absDir, err := filepath.Abs(finalDir)
+
+	if err != nil {
+		return "", fmt.Errorf(packageErr, ErrStageOutput, err.Error())
+	}
+
+	if sd, ok := o.dirs[absDir]; ok {
+		return sd.stagedDir, nil
+	}
+
+	if err = os.MkdirAll(filepath.Dir(absDir), os.ModePerm); err != nil {
+		return "", fmt.Errorf(packageErr, ErrStageOutput, err.Error())
+	}
+
+	stageRoot, err := os.MkdirTemp(filepath.Dir(absDir), stagePrefix)
+
+	if err != nil {
+		return "", fmt.Errorf(packageErr, ErrStageOutput, err.Error())
+	}
+
+	sd := &stagedDir{
+		finalDir:  absDir,
+		stageRoot: stageRoot,
+		stagedDir: filepath.Join(stageRoot, filepath.Base(absDir)),
+	}
+
+	if err = os.MkdirAll(sd.stagedDir, os.ModePerm); err != nil {
+		os.RemoveAll(stageRoot)
+		return "", fmt.Errorf(packageErr, ErrStageOutput, err.Error())
+	}
+
+	o.dirs[absDir] = sd
+	o.order = append(o.order, absDir)
+
+	return sd.stagedDir, nil
+}
+
+// Discard removes the staged copy of finalDir so it is never committed
+func (o *OutputStage) Discard(finalDir string) error {
+	absDir, err := filepath.Abs(finalDir)
+
+	if err != nil {
+		return fmt.Errorf(packageErr, ErrStageOutput, err.Error())
+	}
+
+	sd, ok := o.dirs[absDir]
+
+	if !ok {
+		return nil
+	}
+
+	delete(o.dirs, absDir)
+
+	for i, v := range o.order {
+		if v == absDir {
+			o.order = append(o.order[:i], o.order[i+1:]...)
+			break
+		}
+	}
+
+	if err = os.RemoveAll(sd.stageRoot); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+// CommitResult reports what a commit did with each final destination
+type CommitResult struct {
+	// Created holds files that did not exist before
+	Created []string
+
+	// Updated holds files whose content changed
+	Updated []string
+
+	// Unchanged holds files whose content was already up to date and were not touched
+	Unchanged []string
+
+	// Deleted holds previously generated files that were not generated again
+	Deleted []string
+
+	// Kept holds previously generated files that were not generated again but were
+	// edited since, so they were not deleted
+	Kept []string
+}
+
+// Commit moves every staged file into its final destination
+//
+// Staged files with the same content as their destination are skipped so they keep
+// their modification time.  When manifest is given, files it recorded in a staged
+// directory that were not generated again are deleted, unless they were edited since,
+// and the manifest is updated with the outcome
+//
+// Files that already exist at the destination are backed up first and if any move
+// fails, every file moved so far is removed and the backups are restored so the
+// previous output is left untouched
+func (o *OutputStage) Commit(manifest *Manifest) (CommitResult, error) {
+	var result CommitResult
+	var moves []stagedMove
+	var importSegments [][]byte
+
+	for _, absDir := range o.order {
+		importSegments = append(importSegments, []byte("/"+filepath.Base(o.dirs[absDir].stageRoot)+"/"))
+	}
+
+	for _, absDir := range o.order {
+		sd := o.dirs[absDir]
+		generated := make(map[string]bool)
+
+		if err := filepath.Walk(sd.stagedDir, func(path string, info fs.FileInfo, err error) error {
+			if err != nil {
+				return errors.WithStack(err)
+			}
+
+			if info.IsDir() {
+				return nil
+			}
+
+			rel, err := filepath.Rel(sd.stagedDir, path)
+
+			if err != nil {
+				return errors.WithStack(err)
+			}
+
+			content, err := os.ReadFile(path)
+
+			if err != nil {
+				return errors.WithStack(err)
+			}
+
+			// gorm/gen resolves the model package import path from where the model
+			// code was written so any reference to a staging directory has to be
+			// removed before the code is moved into place
+			if strings.HasSuffix(path, ".go") {
+				rewritten := content
+
+				for _, segment := range importSegments {
+					rewritten = bytes.ReplaceAll(rewritten, segment, []byte("/"))
+				}
+
+				if !bytes.Equal(rewritten, content) {
+					if err = os.WriteFile(path, rewritten, info.Mode()); err != nil {
+						return errors.WithStack(err)
+					}
+
+					content = rewritten
+				}
+			}
+
+			finalPath := filepath.Join(sd.finalDir, rel)
+			generated[finalPath] = true
+
+			existing, err := os.ReadFile(finalPath)
+
+			switch {
+			case err == nil && bytes.Equal(existing, content):
+				result.Unchanged = append(result.Unchanged, finalPath)
+				return nil
+			case err == nil:
+				result.Updated = append(result.Updated, finalPath)
+			case os.IsNotExist(err):
+				result.Created = append(result.Created, finalPath)
+			default:
+				return errors.WithStack(err)
+			}
+
+			moves = append(moves, stagedMove{
+				stagedPath: path,
+				finalPath:  finalPath,
+				backupPath: filepath.Join(sd.stageRoot, "_backup", rel),
+			})
+
+			return nil
+		}); err != nil {
+			return CommitResult{}, fmt.Errorf(packageErr, ErrCommitOutput, err.Error())
+		}
+
+		if manifest == nil {
+			continue
+		}
+
+		recorded, err := manifest.FilesWithin(sd.finalDir)
+
+		if err != nil {
+			return CommitResult{}, fmt.Errorf(packageErr, ErrCommitOutput, err.Error())
+		}
+
+		for _, file := range recorded {
+			// Only files directly within the directory are considered as nested
+			// directories can be the destination of another generator
+			if generated[file] || filepath.Dir(file) != sd.finalDir {
+				continue
+			}
+
+			unmodified, err := manifest.Unmodified(file)
+
+			if err != nil {
+				if os.IsNotExist(errors.Cause(err)) {
+					manifest.Forget(file)
+					continue
+				}
+
+				return CommitResult{}, fmt.Errorf(packageErr, ErrCommitOutput, err.Error())
+			}
+
+			if !unmodified {
+				result.Kept = append(result.Kept, file)
+				continue
+			}
+
+			rel, err := filepath.Rel(sd.finalDir, file)
+
+			if err != nil {
+				return CommitResult{}, fmt.Errorf(packageErr, ErrCommitOutput, err.Error())
+			}
+
+			result.Deleted = append(result.Deleted, file)
+			moves = append(moves, stagedMove{
+				finalPath:  file,
+				backupPath: filepath.Join(sd.stageRoot, "_backup", rel),
+			})
+		}
+	}
+
+	for i := range moves {
+		if err := commitMove(&moves[i]); err != nil {
+			restoreMoves(moves[:i+1])
+			return CommitResult{}, fmt.Errorf(packageErr, ErrCommitOutput, err.Error())
+		}
+	}
+
+	if manifest != nil {
+		for _, files := range [][]string{result.Created, result.Updated, result.Unchanged} {
+			if err := manifest.Record(files...); err != nil {
+				return result, err
+			}
+		}
+		for _, file := range result.Deleted {
+			manifest.Forget(file)
+		}
+	}
+
+	return result, o.Rollback()
+}
+
+// Rollback removes all staged output without touching any final destination
+func (o *OutputStage) Rollback() error {
+	var err error
+
+	for _, absDir := range o.order {
+		if rmErr := os.RemoveAll(o.dirs[absDir].stageRoot); rmErr != nil && err == nil {
+			err = errors.WithStack(rmErr)
+		}
+	}
+
+	o.dirs = make(map[string]*stagedDir)
+	o.order = nil
+
+	return err
+}
+
+// commitMove moves a single staged file into place, backing up any existing file
+//
+// A move without a staged path deletes the destination by only backing it up.
+// backupPath is cleared when there was nothing to back up so restoreMoves knows
+// whether the destination existed before
+func commitMove(m *stagedMove) error {
+	var err error
+
+	if err = os.MkdirAll(filepath.Dir(m.finalPath), os.ModePerm); err != nil {
+		return err
+	}
+
+	if _, err = os.Lstat(m.finalPath); err == nil {
+		if err = os.MkdirAll(filepath.Dir(m.backupPath), os.ModePerm); err != nil {
+			return err
+		}
+		if err = os.Rename(m.finalPath, m.backupPath); err != nil {
+			return err
+		}
+	} else if os.IsNotExist(err) {
+		m.backupPath = ""
+	} else {
+		return err
+	}
+
+	if m.stagedPath == "" {
+		return nil
+	}
+
+	return os.Rename(m.stagedPath, m.finalPath)
+}
+
+// restoreMoves undoes moves in reverse order, restoring backed up files
+func restoreMoves(moves []stagedMove) {
+	for i := len(moves) - 1; i >= 0; i-- {
+		m := moves[i]
+
+		if m.stagedPath != "" {
+			if _, err := os.Lstat(m.stagedPath); os.IsNotExist(err) {
+				os.Remove(m.finalPath)
+			}
+		}
+		if m.backupPath != "" {
+			if _, err := os.Lstat(m.backupPath); err == nil {
+				os.Rename(m.backupPath, m.finalPath)
+			}
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOutputStage(t *testing.T) {
+	var err error
+
+	root := t.TempDir()
+	modelDir := filepath.Join(root, "model")
+
+	if err = os.MkdirAll(modelDir, os.ModePerm); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if err = os.WriteFile(filepath.Join(modelDir, "hooks.go"), []byte("package model\n"), 0640); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if err = os.WriteFile(filepath.Join(modelDir, "user.gen.go"), []byte("old"), 0640); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	stage := NewOutputStage()
+	stagedModelDir, err := stage.Dir(modelDir)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if filepath.Base(stagedModelDir) != "model" {
+		t.Fatalf("staged dir should keep base name 'model'; got '%s'\n", filepath.Base(stagedModelDir))
+	}
+
+	stageSegment := filepath.Base(filepath.Dir(stagedModelDir))
+	stagedContent := `import "example.com/app/` + stageSegment + `/model"`
+
+	if err = os.WriteFile(filepath.Join(stagedModelDir, "user.gen.go"), []byte(stagedContent), 0640); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	result, err := stage.Commit(nil)
+
+	if err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	if len(result.Updated) != 1 || result.Updated[0] != filepath.Join(modelDir, "user.gen.go") {
+		t.Fatalf("only the staged file should be updated; got %v\n", result.Updated)
+	}
+
+	content, err := os.ReadFile(filepath.Join(modelDir, "user.gen.go"))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if string(content) != `import "example.com/app/model"` {
+		t.Fatalf("staging segment should be removed from import path; got '%s'\n", string(content))
+	}
+
+	if _, err = os.Stat(filepath.Join(modelDir, "hooks.go")); err != nil {
+		t.Fatalf("files not generated should be left alone; %s\n", err.Error())
+	}
+
+	entries, err := os.ReadDir(root)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), stagePrefix) {
+			t.Fatalf("staging directory '%s' should be removed after commit\n", entry.Name())
+		}
+	}
+}
+
+func TestOutputStageCommitFailure(t *testing.T) {
+	var err error
+
+	root := t.TempDir()
+	modelDir := filepath.Join(root, "model")
+
+	if err = os.MkdirAll(modelDir, os.ModePerm); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if err = os.WriteFile(filepath.Join(modelDir, "a.gen.go"), []byte("old"), 0640); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// A file where the stage expects a directory makes the second move fail
+	if err = os.WriteFile(filepath.Join(modelDir, "sub"), []byte("file"), 0640); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	stage := NewOutputStage()
+	stagedModelDir, err := stage.Dir(modelDir)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if err = os.WriteFile(filepath.Join(stagedModelDir, "a.gen.go"), []byte("new"), 0640); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if err = os.MkdirAll(filepath.Join(stagedModelDir, "sub"), os.ModePerm); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if err = os.WriteFile(filepath.Join(stagedModelDir, "sub", "b.gen.go"), []byte("new"), 0640); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if _, err = stage.Commit(nil); err == nil {
+		t.Fatalf("should have error\n")
+	}
+
+	if !errors.Is(err, ErrCommitOutput) {
+		t.Fatalf("should have error %v; got %v\n", ErrCommitOutput, err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(modelDir, "a.gen.go"))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if string(content) != "old" {
+		t.Fatalf("previous output should be restored; got '%s'\n", string(content))
+	}
+
+	if err = stage.Rollback(); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	entries, err := os.ReadDir(root)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(entries) != 1 {
+		t.Fatalf("only the model directory should remain; got %d entries\n", len(entries))
+	}
+}
+
+func TestOutputStageIncremental(t *testing.T) {
+	var err error
+
+	root := t.TempDir()
+	modelDir := filepath.Join(root, "model")
+	unchanged := filepath.Join(modelDir, "user.gen.go")
+	updated := filepath.Join(modelDir, "phone.gen.go")
+	created := filepath.Join(modelDir, "order.gen.go")
+	stale := filepath.Join(modelDir, "address.gen.go")
+	edited := filepath.Join(modelDir, "company.gen.go")
+
+	if err = os.MkdirAll(modelDir, os.ModePerm); err != nil {
+		t.Fatalf(err.Error())
+	}
+	for _, file := range []string{unchanged, updated, stale, edited} {
+		if err = os.WriteFile(file, []byte(filepath.Base(file)), 0640); err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+
+	manifest, err := ReadManifest(filepath.Join(root, DefaultManifestFile))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if err = manifest.Record(unchanged, updated, stale, edited); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if err = os.WriteFile(edited, []byte("edited"), 0640); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	oldTime := time.Now().Add(-time.Hour)
+
+	if err = os.Chtimes(unchanged, oldTime, oldTime); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	stage := NewOutputStage()
+	stagedModelDir, err := stage.Dir(modelDir)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	staged := map[string]string{
+		unchanged: filepath.Base(unchanged),
+		updated:   "new",
+		created:   "new",
+	}
+
+	for file, content := range staged {
+		if err = os.WriteFile(filepath.Join(stagedModelDir, filepath.Base(file)), []byte(content), 0640); err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+
+	result, err := stage.Commit(manifest)
+
+	if err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	expected := map[string][]string{
+		"created":   {created},
+		"updated":   {updated},
+		"unchanged": {unchanged},
+		"deleted":   {stale},
+		"kept":      {edited},
+	}
+	got := map[string][]string{
+		"created":   result.Created,
+		"updated":   result.Updated,
+		"unchanged": result.Unchanged,
+		"deleted":   result.Deleted,
+		"kept":      result.Kept,
+	}
+
+	for key, files := range expected {
+		if len(got[key]) != len(files) || got[key][0] != files[0] {
+			t.Fatalf("expected %s files %v; got %v\n", key, files, got[key])
+		}
+	}
+
+	info, err := os.Stat(unchanged)
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if !info.ModTime().Equal(oldTime) {
+		t.Fatalf("unchanged file should not be rewritten\n")
+	}
+
+	if _, err = os.Stat(stale); !os.IsNotExist(err) {
+		t.Fatalf("stale generated file should be deleted\n")
+	}
+
+	if _, err = os.Stat(edited); err != nil {
+		t.Fatalf("edited generated file should be kept; %s\n", err.Error())
+	}
+
+	if _, ok := manifest.Files["model/address.gen.go"]; ok {
+		t.Fatalf("deleted file should be removed from manifest\n")
+	}
+
+	if _, ok := manifest.Files["model/order.gen.go"]; !ok {
+		t.Fatalf("created file should be recorded in manifest\n")
+	}
+}
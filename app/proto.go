@@ -0,0 +1,451 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kenshaw/snaker"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultProtoLockFile is the file protobuf field numbers are kept in when none is given
+	DefaultProtoLockFile = ".model_gen_proto_lock.json"
+
+	protoTimestamp = "google.protobuf.Timestamp"
+	protoValue     = "google.protobuf.Value"
+)
+
+var (
+	ErrReadProtoLock  = errors.New("model-gen: read proto lock error")
+	ErrWriteProtoLock = errors.New("model-gen: write proto lock error")
+)
+
+// protoShapeTypes is the protobuf scalar or well known type of every json shape
+var protoShapeTypes = map[jsonShape]string{
+	jsonAny:    protoValue,
+	jsonString: "string",
+	jsonInt:    "int32",
+	jsonBigint: "int64",
+	jsonFloat:  "double",
+	jsonBool:   "bool",
+	jsonTime:   protoTimestamp,
+	jsonBytes:  "bytes",
+}
+
+// protoWrapperTypes is the wrapper of every scalar so nullable columns can be
+// told apart from their zero value
+var protoWrapperTypes = map[string]string{
+	"string": "google.protobuf.StringValue",
+	"int32":  "google.protobuf.Int32Value",
+	"int64":  "google.protobuf.Int64Value",
+	"float":  "google.protobuf.FloatValue",
+	"double": "google.protobuf.DoubleValue",
+	"bool":   "google.protobuf.BoolValue",
+	"bytes":  "google.protobuf.BytesValue",
+}
+
+// protoImports is the file every well known type is imported from
+var protoImports = map[string]string{
+	protoTimestamp: "google/protobuf/timestamp.proto",
+	protoValue:     "google/protobuf/struct.proto",
+}
+
+// protoFloatTypes are the floating point types that fit into a float
+var protoFloatTypes = map[string]bool{
+	"real":   true,
+	"float4": true,
+}
+
+// ProtoLock records the field number of every message field and enum value ever
+// generated so numbers stay the same across runs
+//
+// Numbers of removed columns and enum values are kept as well so they are
+// reserved instead of being reused
+type ProtoLock struct {
+	path string
+
+	// Messages are keyed by table name
+	Messages map[string]*ProtoNumbers `json:"messages"`
+
+	// Enums are keyed by "table.column" rather than by enum name so renaming a
+	// struct doesn't renumber the values of its enums
+	Enums map[string]*ProtoNumbers `json:"enums"`
+}
+
+// ProtoNumbers holds the numbers of the fields of a message or values of an enum
+type ProtoNumbers struct {
+	Numbers map[string]int `json:"numbers"`
+	Removed map[string]int `json:"removed,omitempty"`
+}
+
+// ReadProtoLock reads the proto lock at path, returning an empty lock if the file
+// does not exist yet
+func ReadProtoLock(path string) (*ProtoLock, error) {
+	if path == "" {
+		path = DefaultProtoLockFile
+	}
+
+	absPath, err := filepath.Abs(path)
+
+	if err != nil {
+		return nil, fmt.Errorf(packageErr, ErrReadProtoLock, err.Error())
+	}
+
+	l := &ProtoLock{path: absPath}
+
+	content, err := os.ReadFile(absPath)
+
+	if err == nil {
+		err = json.Unmarshal(content, l)
+	} else if os.IsNotExist(err) {
+		err = nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf(packageErr, ErrReadProtoLock, err.Error())
+	}
+
+	if l.Messages == nil {
+		l.Messages = make(map[string]*ProtoNumbers)
+	}
+	if l.Enums == nil {
+		l.Enums = make(map[string]*ProtoNumbers)
+	}
+
+	return l, nil
+}
+
+// Save writes the proto lock back to the file it was read from
+func (l *ProtoLock) Save() error {
+	content, err := json.MarshalIndent(l, "", "\t")
+
+	if err != nil {
+		return fmt.Errorf(packageErr, ErrWriteProtoLock, err.Error())
+	}
+
+	if err = os.WriteFile(l.path, append(content, '\n'), 0640); err != nil {
+		return fmt.Errorf(packageErr, ErrWriteProtoLock, err.Error())
+	}
+
+	return nil
+}
+
+// numbers returns the numbers of names within locked, creating it if needed
+//
+// Names seen before keep their number, names removed earlier get their old
+// number back and new names are numbered after the highest number ever used
+// starting at first.  Names no longer given are moved to the removed numbers
+func numbers(locked map[string]*ProtoNumbers, key string, names []string, first int) *ProtoNumbers {
+	n := locked[key]
+
+	if n == nil {
+		n = &ProtoNumbers{}
+		locked[key] = n
+	}
+	if n.Numbers == nil {
+		n.Numbers = make(map[string]int)
+	}
+
+	next := first
+
+	for _, m := range []map[string]int{n.Numbers, n.Removed} {
+		for _, number := range m {
+			if number >= next {
+				next = number + 1
+			}
+		}
+	}
+
+	current := make(map[string]bool, len(names))
+
+	for _, name := range names {
+		current[name] = true
+
+		if _, ok := n.Numbers[name]; ok {
+			continue
+		}
+
+		if number, ok := n.Removed[name]; ok {
+			n.Numbers[name] = number
+			delete(n.Removed, name)
+			continue
+		}
+
+		// 19000 through 19999 are reserved for the protobuf implementation
+		if next >= 19000 && next <= 19999 {
+			next = 20000
+		}
+
+		n.Numbers[name] = next
+		next++
+	}
+
+	for name, number := range n.Numbers {
+		if !current[name] {
+			if n.Removed == nil {
+				n.Removed = make(map[string]int)
+			}
+
+			n.Removed[name] = number
+			delete(n.Numbers, name)
+		}
+	}
+
+	if len(n.Removed) == 0 {
+		n.Removed = nil
+	}
+
+	return n
+}
+
+// reserved returns the reserved statements of the removed numbers indented by
+// indent, or nothing if none were removed
+func (n *ProtoNumbers) reserved(indent string, name func(string) string) string {
+	if len(n.Removed) == 0 {
+		return ""
+	}
+
+	var numbers, names []string
+
+	for _, removed := range sortedKeys(n.Removed) {
+		names = append(names, fmt.Sprintf("%q", name(removed)))
+	}
+
+	sorted := make([]int, 0, len(n.Removed))
+
+	for _, number := range n.Removed {
+		sorted = append(sorted, number)
+	}
+
+	sort.Ints(sorted)
+
+	for _, number := range sorted {
+		numbers = append(numbers, fmt.Sprint(number))
+	}
+
+	return fmt.Sprintf(
+		"%sreserved %s;\n%sreserved %s;\n",
+		indent,
+		strings.Join(numbers, ", "),
+		indent,
+		strings.Join(names, ", "),
+	)
+}
+
+// GenerateProtoFiles generates a .proto file with a message for every table
+// within protoDir
+//
+// Field and enum value numbers are taken from lock and every new number is
+// recorded in it, so lock has to be saved once the files are written.  Files
+// declare the package cfg.Package
+func GenerateProtoFiles(tables []Table, protoDir string, lock *ProtoLock, cfg GenerateConfig) error {
+	if protoDir == "" {
+		return errors.WithStack(fmt.Errorf("model-gen: protoDir parameter can't be empty"))
+	}
+	if lock == nil {
+		return errors.WithStack(fmt.Errorf("model-gen: lock parameter can't be nil"))
+	}
+
+	if err := os.MkdirAll(protoDir, os.ModePerm); err != nil {
+		return errors.WithStack(err)
+	}
+
+	for _, table := range tables {
+		content := protoFile(table, lock, cfg)
+
+		if err := os.WriteFile(filepath.Join(protoDir, table.Name+".proto"), []byte(content), 0644); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	return nil
+}
+
+// protoFile returns the content of the .proto file of table
+func protoFile(table Table, lock *ProtoLock, cfg GenerateConfig) string {
+	var b, fields, enums strings.Builder
+	var columns []Column
+
+	imports := map[string]bool{}
+
+	for _, col := range table.Columns {
+		// Fields hidden from json are never part of the api contract
+		if _, _, ok := cfg.Model.jsonField(table.Name, col); ok {
+			columns = append(columns, col)
+		}
+	}
+
+	names := make([]string, 0, len(columns))
+
+	for _, col := range columns {
+		names = append(names, col.Name)
+	}
+
+	message := numbers(lock.Messages, table.Name, names, 1)
+
+	for _, col := range columns {
+		jsonName, _, _ := cfg.Model.jsonField(table.Name, col)
+		fieldType := protoType(table, col, cfg)
+		name := protoIdentifier(col.Name)
+
+		if col.Nullable {
+			switch {
+			case cfg.Model.enumColumn(table, col):
+				fieldType = "optional " + fieldType
+			case protoWrapperTypes[fieldType] != "":
+				fieldType = protoWrapperTypes[fieldType]
+				imports["google/protobuf/wrappers.proto"] = true
+			}
+		}
+
+		if importPath, ok := protoImports[fieldType]; ok {
+			imports[importPath] = true
+		}
+
+		fields.WriteString(protoComment(col.Comment, "  "))
+		fields.WriteString(fmt.Sprintf("  %s %s = %d", fieldType, name, message.Numbers[col.Name]))
+
+		// The json of protobuf uses lowerCamelCase field names unless told otherwise
+		if jsonName != protoJSONName(name) {
+			fields.WriteString(fmt.Sprintf(" [json_name = %q]", jsonName))
+		}
+
+		fields.WriteString(";\n")
+
+		if cfg.Model.enumColumn(table, col) {
+			enums.WriteString(protoEnum(cfg.Model.enumName(table.Name, col), table.Name+"."+col.Name, col.EnumValues, lock))
+		}
+	}
+
+	b.WriteString("syntax = \"proto3\";\n")
+
+	if cfg.Package != "" {
+		b.WriteString(fmt.Sprintf("\npackage %s;\n", cfg.Package))
+	}
+
+	if len(imports) > 0 {
+		b.WriteString("\n")
+	}
+
+	for _, importPath := range sortedKeys(imports) {
+		b.WriteString(fmt.Sprintf("import %q;\n", importPath))
+	}
+
+	b.WriteString("\n")
+	b.WriteString(protoComment(table.Comment, ""))
+	b.WriteString(fmt.Sprintf("message %s {\n", cfg.Model.structName(table.Name)))
+
+	if reserved := message.reserved("  ", protoIdentifier); reserved != "" {
+		b.WriteString(reserved)
+		b.WriteString("\n")
+	}
+
+	b.WriteString(fields.String())
+	b.WriteString("}\n")
+	b.WriteString(enums.String())
+
+	return b.String()
+}
+
+// protoEnum returns an enum named name with a value per enum value, numbered
+// after the values locked for key
+//
+// Values are prefixed with the enum name as enum values share the scope of the
+// enum and the zero value is left unspecified as proto3 requires it
+func protoEnum(name, key string, values []string, lock *ProtoLock) string {
+	var b strings.Builder
+
+	prefix := strings.ToUpper(snaker.CamelToSnake(name)) + "_"
+	constant := func(value string) string {
+		return prefix + screamingEnumConstant(value)
+	}
+	enum := numbers(lock.Enums, key, values, 1)
+
+	b.WriteString(fmt.Sprintf("\nenum %s {\n", name))
+
+	if reserved := enum.reserved("  ", constant); reserved != "" {
+		b.WriteString(reserved)
+		b.WriteString("\n")
+	}
+
+	b.WriteString(fmt.Sprintf("  %sUNSPECIFIED = 0;\n", prefix))
+
+	for i, c := range enumConstants(values, screamingEnumConstant) {
+		b.WriteString(fmt.Sprintf("  %s%s = %d;\n", prefix, c, enum.Numbers[values[i]]))
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// protoType returns the protobuf type of col of table without wrappers
+func protoType(table Table, col Column, cfg GenerateConfig) string {
+	if cfg.Model.enumColumn(table, col) {
+		return cfg.Model.enumName(table.Name, col)
+	}
+
+	shape := cfg.Model.jsonShape(table, col)
+
+	if shape == jsonFloat && protoFloatTypes[col.DataType] {
+		return "float"
+	}
+
+	return protoShapeTypes[shape]
+}
+
+// protoIdentifier returns the snake_case field name of a column
+func protoIdentifier(columnName string) string {
+	name := strings.ToLower(snaker.CamelToSnake(strings.Join(enumWords(columnName), "_")))
+
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		return "field_" + name
+	}
+
+	return name
+}
+
+// protoJSONName returns the json name protobuf gives a field named name by
+// default
+func protoJSONName(name string) string {
+	var b strings.Builder
+
+	upper := false
+
+	for _, r := range name {
+		switch {
+		case r == '_':
+			upper = true
+		case upper:
+			b.WriteString(strings.ToUpper(string(r)))
+			upper = false
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+// protoComment returns comment as line comments indented by indent, or nothing
+// if comment is empty
+func protoComment(comment, indent string) string {
+	comment = strings.TrimSpace(comment)
+
+	if comment == "" {
+		return ""
+	}
+
+	var doc strings.Builder
+
+	for _, line := range strings.Split(comment, "\n") {
+		doc.WriteString(strings.TrimRight(indent+"// "+strings.TrimSpace(line), " ") + "\n")
+	}
+
+	return doc.String()
+}
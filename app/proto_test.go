@@ -0,0 +1,158 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadProtoLock(t *testing.T) {
+	var err error
+
+	lockFile := filepath.Join(t.TempDir(), "proto.lock.json")
+
+	lock, err := ReadProtoLock(lockFile)
+
+	if err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	numbers(lock.Messages, "user_profile", []string{"id", "name"}, 1)
+
+	if err = lock.Save(); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	if lock, err = ReadProtoLock(lockFile); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	if lock.Messages["user_profile"].Numbers["name"] != 2 {
+		t.Fatalf("expected saved numbers; got %+v\n", lock.Messages["user_profile"])
+	}
+
+	if err = os.WriteFile(lockFile, []byte("{"), 0644); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if _, err = ReadProtoLock(lockFile); err == nil {
+		t.Fatalf("should have error\n")
+	}
+}
+
+func TestGenerateProtoFiles(t *testing.T) {
+	var err error
+
+	protoDir := filepath.Join(t.TempDir(), "proto")
+	lock, _ := ReadProtoLock(filepath.Join(t.TempDir(), "proto.lock.json"))
+
+	if err = GenerateProtoFiles(nil, "", lock, GenerateConfig{}); err == nil {
+		t.Fatalf("should have error\n")
+	}
+
+	table := Table{
+		Name:    "user_profile",
+		Comment: "Person using the app",
+		Columns: []Column{
+			{Name: "id", DataType: "int8"},
+			{Name: "name", DataType: "varchar", Nullable: true},
+			{Name: "status", DataType: "user_status", EnumValues: []string{"active", "on-hold"}},
+			{Name: "settings", DataType: "jsonb", Comment: "Preferences of the user"},
+			{Name: "created_at", DataType: "timestamptz"},
+		},
+	}
+	cfg := GenerateConfig{
+		Model: ModelConfig{
+			TypeMap: TypeMap{{DBType: "jsonb", GoType: "datatypes.JSON"}},
+			Tags:    []TagGenerator{{Name: "json", Case: SnakeCase}},
+		},
+		Package: "acme.models",
+	}
+
+	if err = GenerateProtoFiles([]Table{table}, protoDir, lock, cfg); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	content, err := os.ReadFile(filepath.Join(protoDir, "user_profile.proto"))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expected := "syntax = \"proto3\";\n\n" +
+		"package acme.models;\n\n" +
+		"import \"google/protobuf/struct.proto\";\n" +
+		"import \"google/protobuf/timestamp.proto\";\n" +
+		"import \"google/protobuf/wrappers.proto\";\n\n" +
+		"// Person using the app\n" +
+		"message UserProfile {\n" +
+		"  int64 id = 1;\n" +
+		"  google.protobuf.StringValue name = 2;\n" +
+		"  UserProfileStatus status = 3;\n" +
+		"  // Preferences of the user\n" +
+		"  google.protobuf.Value settings = 4;\n" +
+		"  google.protobuf.Timestamp created_at = 5 [json_name = \"created_at\"];\n" +
+		"}\n\n" +
+		"enum UserProfileStatus {\n" +
+		"  USER_PROFILE_STATUS_UNSPECIFIED = 0;\n" +
+		"  USER_PROFILE_STATUS_ACTIVE = 1;\n" +
+		"  USER_PROFILE_STATUS_ON_HOLD = 2;\n" +
+		"}\n"
+
+	if string(content) != expected {
+		t.Fatalf("expected proto output:\n%s\ngot:\n%s\n", expected, string(content))
+	}
+
+	// Removing a column and an enum value must never renumber the others
+	table.Columns = []Column{
+		{Name: "id", DataType: "int8"},
+		{Name: "status", DataType: "user_status", EnumValues: []string{"pending", "on-hold"}, Nullable: true},
+		{Name: "settings", DataType: "jsonb"},
+		{Name: "created_at", DataType: "timestamptz"},
+		{Name: "email", DataType: "text"},
+	}
+
+	if err = GenerateProtoFiles([]Table{table}, protoDir, lock, GenerateConfig{}); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	if content, err = os.ReadFile(filepath.Join(protoDir, "user_profile.proto")); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	for _, s := range []string{
+		"  reserved 2;\n  reserved \"name\";\n\n  int64 id = 1;\n",
+		"  optional UserProfileStatus status = 3;\n",
+		"  google.protobuf.Timestamp created_at = 5;\n",
+		"  string email = 6;\n",
+		"  reserved 1;\n  reserved \"USER_PROFILE_STATUS_ACTIVE\";\n\n",
+		"  USER_PROFILE_STATUS_PENDING = 3;\n  USER_PROFILE_STATUS_ON_HOLD = 2;\n",
+	} {
+		if !strings.Contains(string(content), s) {
+			t.Fatalf("expected proto to contain '%s'; got:\n%s\n", s, string(content))
+		}
+	}
+
+	// Enums keep their numbers when the struct they belong to is renamed
+	cfg = GenerateConfig{Model: ModelConfig{Tables: map[string]TableConfig{"user_profile": {StructName: "Member"}}}}
+
+	if err = GenerateProtoFiles([]Table{table}, protoDir, lock, cfg); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	if content, err = os.ReadFile(filepath.Join(protoDir, "user_profile.proto")); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if s := "  MEMBER_STATUS_PENDING = 3;\n  MEMBER_STATUS_ON_HOLD = 2;\n"; !strings.Contains(string(content), s) {
+		t.Fatalf("expected proto to contain '%s'; got:\n%s\n", s, string(content))
+	}
+
+	// Columns that come back get their old number
+	numbers(lock.Messages, "user_profile", []string{"id", "name"}, 1)
+
+	if n := lock.Messages["user_profile"].Numbers["name"]; n != 2 {
+		t.Fatalf("expected restored column to keep number 2; got %d\n", n)
+	}
+}
@@ -0,0 +1,366 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// pythonShapeTypes is the python type of every json shape along with the
+// module it's imported from, if any
+var pythonShapeTypes = map[jsonShape][2]string{
+	jsonAny:    {"Any", "typing"},
+	jsonString: {"str", ""},
+	jsonInt:    {"int", ""},
+	jsonBigint: {"int", ""},
+	jsonFloat:  {"float", ""},
+	jsonBool:   {"bool", ""},
+	jsonTime:   {"datetime", "datetime"},
+	jsonBytes:  {"str", ""},
+}
+
+// pythonKeywords are the keywords of python which can't be used as field names
+var pythonKeywords = map[string]bool{
+	"False": true, "None": true, "True": true, "and": true, "as": true,
+	"assert": true, "async": true, "await": true, "break": true, "class": true,
+	"continue": true, "def": true, "del": true, "elif": true, "else": true,
+	"except": true, "finally": true, "for": true, "from": true, "global": true,
+	"if": true, "import": true, "in": true, "is": true, "lambda": true,
+	"nonlocal": true, "not": true, "or": true, "pass": true, "raise": true,
+	"return": true, "try": true, "while": true, "with": true, "yield": true,
+}
+
+const pythonInitFile = "__init__.py"
+
+// GeneratePythonModels generates a pydantic v2 model for every table in a single
+// module named pythonFile within pythonDir along with an __init__.py exporting
+// every model
+//
+// When pythonFile is empty every table is generated into its own module named
+// after the table instead.  Fields are validated and serialized by the json
+// names of the go models through aliases so models have to be dumped with
+// by_alias=True to match them
+func GeneratePythonModels(tables []Table, pythonDir, pythonFile string, cfg GenerateConfig) error {
+	if pythonDir == "" {
+		return errors.WithStack(fmt.Errorf("model-gen: pythonDir parameter can't be empty"))
+	}
+
+	if err := os.MkdirAll(pythonDir, os.ModePerm); err != nil {
+		return errors.WithStack(err)
+	}
+
+	var init, rebuild strings.Builder
+	var exports []string
+
+	if pythonFile != "" {
+		module := strings.TrimSuffix(pythonFile, ".py")
+
+		if err := writePythonFile(filepath.Join(pythonDir, module+".py"), tables, cfg, false); err != nil {
+			return err
+		}
+
+		for _, table := range tables {
+			exports = append(exports, pythonExports(table, cfg)...)
+		}
+
+		init.WriteString(fmt.Sprintf("from .%s import %s\n", module, strings.Join(exports, ", ")))
+	} else {
+		for _, table := range tables {
+			if err := writePythonFile(filepath.Join(pythonDir, table.Name+".py"), []Table{table}, cfg, true); err != nil {
+				return err
+			}
+
+			names := pythonExports(table, cfg)
+			exports = append(exports, names...)
+
+			init.WriteString(fmt.Sprintf("from .%s import %s\n", table.Name, strings.Join(names, ", ")))
+
+			// Relations to models of other modules are only imported for type
+			// checking so they are resolved once every model is imported
+			if len(table.ForeignKeys) > 0 {
+				rebuild.WriteString(fmt.Sprintf("%s.model_rebuild()\n", cfg.Model.structName(table.Name)))
+			}
+		}
+	}
+
+	if rebuild.Len() > 0 {
+		init.WriteString("\n")
+		init.WriteString(rebuild.String())
+	}
+
+	init.WriteString("\n__all__ = [\n")
+
+	for _, name := range exports {
+		init.WriteString(fmt.Sprintf("    %s,\n", pythonString(name)))
+	}
+
+	init.WriteString("]\n")
+
+	return errors.WithStack(os.WriteFile(filepath.Join(pythonDir, pythonInitFile), []byte(init.String()), 0644))
+}
+
+// writePythonFile writes the models of tables into fileName along with the
+// imports they need
+//
+// Models of other tables are imported from their own modules when perTable is set
+func writePythonFile(fileName string, tables []Table, cfg GenerateConfig, perTable bool) error {
+	var b, models strings.Builder
+
+	imports := map[string]map[string]bool{}
+	addImport := func(module, name string) {
+		if module == "" {
+			return
+		}
+		if imports[module] == nil {
+			imports[module] = map[string]bool{}
+		}
+
+		imports[module][name] = true
+	}
+
+	relations := map[string]bool{}
+
+	for _, table := range tables {
+		for _, col := range table.Columns {
+			_, omitEmpty, ok := cfg.Model.jsonField(table.Name, col)
+
+			if !ok {
+				continue
+			}
+			if col.Nullable || omitEmpty {
+				addImport("typing", "Optional")
+			}
+
+			switch {
+			case cfg.Model.enumColumn(table, col) && cfg.LiteralEnums:
+				addImport("typing", "Literal")
+			case cfg.Model.enumColumn(table, col):
+				addImport("enum", "Enum")
+			default:
+				t := pythonType(table, col, cfg)
+				addImport(t[1], t[0])
+			}
+		}
+
+		for _, fk := range table.ForeignKeys {
+			addImport("typing", "Optional")
+
+			if perTable && fk.ForeignTableName != table.Name {
+				relations[fk.ForeignTableName] = true
+			}
+		}
+
+		models.WriteString("\n\n")
+		models.WriteString(pythonModel(table, cfg))
+	}
+
+	if len(relations) > 0 {
+		addImport("typing", "TYPE_CHECKING")
+	}
+
+	b.WriteString("from __future__ import annotations\n\n")
+
+	for _, module := range sortedKeys(imports) {
+		b.WriteString(fmt.Sprintf("from %s import %s\n", module, strings.Join(sortedKeys(imports[module]), ", ")))
+	}
+
+	b.WriteString("\nfrom pydantic import BaseModel, ConfigDict, Field\n")
+
+	if len(relations) > 0 {
+		b.WriteString("\nif TYPE_CHECKING:\n")
+
+		for _, tableName := range sortedKeys(relations) {
+			b.WriteString(fmt.Sprintf("    from .%s import %s\n", tableName, cfg.Model.structName(tableName)))
+		}
+	}
+
+	b.WriteString(models.String())
+
+	return errors.WithStack(os.WriteFile(fileName, []byte(b.String()), 0644))
+}
+
+// pythonExports returns the names of the model and enums generated for table
+func pythonExports(table Table, cfg GenerateConfig) []string {
+	names := []string{cfg.Model.structName(table.Name)}
+
+	if cfg.LiteralEnums {
+		return names
+	}
+
+	for _, col := range table.Columns {
+		if _, _, ok := cfg.Model.jsonField(table.Name, col); ok && cfg.Model.enumColumn(table, col) {
+			names = append(names, cfg.Model.enumName(table.Name, col))
+		}
+	}
+
+	return names
+}
+
+// pythonModel returns the enums of the enum columns of table followed by its
+// model
+func pythonModel(table Table, cfg GenerateConfig) string {
+	var b, fields strings.Builder
+
+	for _, col := range table.Columns {
+		jsonName, omitEmpty, ok := cfg.Model.jsonField(table.Name, col)
+
+		// Fields hidden from json are never part of the api contract
+		if !ok {
+			continue
+		}
+
+		if cfg.Model.enumColumn(table, col) && !cfg.LiteralEnums {
+			b.WriteString(pythonEnum(cfg.Model.enumName(table.Name, col), col.EnumValues))
+			b.WriteString("\n\n")
+		}
+
+		fields.WriteString(pythonField(
+			pythonIdentifier(col.Name),
+			jsonName,
+			pythonType(table, col, cfg)[0],
+			col.Comment,
+			col.Nullable || omitEmpty,
+		))
+	}
+
+	// Relations are only set when they are loaded so they are always optional
+	for _, fk := range table.ForeignKeys {
+		jsonName, _ := cfg.Model.relationJSONField(table, fk)
+
+		fields.WriteString(pythonField(
+			pythonIdentifier(cfg.Model.relationName(table, fk)),
+			jsonName,
+			cfg.Model.structName(fk.ForeignTableName),
+			"",
+			true,
+		))
+	}
+
+	b.WriteString(fmt.Sprintf("class %s(BaseModel):\n", cfg.Model.structName(table.Name)))
+	b.WriteString(pythonDocstring(table.Comment, "    "))
+	b.WriteString("    model_config = ConfigDict(populate_by_name=True)\n\n")
+	b.WriteString(fields.String())
+
+	return b.String()
+}
+
+// pythonField returns the annotated field name of a model serialized as jsonName
+//
+// Optional fields default to None so missing fields validate as well
+func pythonField(name, jsonName, fieldType, comment string, optional bool) string {
+	var args []string
+
+	if optional {
+		fieldType = fmt.Sprintf("Optional[%s]", fieldType)
+		args = append(args, "default=None")
+	}
+	if jsonName != name {
+		args = append(args, "alias="+pythonString(jsonName))
+	}
+	if comment = strings.TrimSpace(comment); comment != "" {
+		args = append(args, "description="+pythonString(comment))
+	}
+
+	switch {
+	case len(args) == 1 && optional:
+		return fmt.Sprintf("    %s: %s = None\n", name, fieldType)
+	case len(args) == 0:
+		return fmt.Sprintf("    %s: %s\n", name, fieldType)
+	}
+
+	return fmt.Sprintf("    %s: %s = Field(%s)\n", name, fieldType, strings.Join(args, ", "))
+}
+
+// pythonEnum returns a str enum named name with a member per value
+func pythonEnum(name string, values []string) string {
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("class %s(str, Enum):\n", name))
+
+	for i, constant := range enumConstants(values, screamingEnumConstant) {
+		b.WriteString(fmt.Sprintf("    %s = %s\n", constant, pythonString(values[i])))
+	}
+
+	return b.String()
+}
+
+// pythonType returns the python type of col of table without optionality along
+// with the module it's imported from
+func pythonType(table Table, col Column, cfg GenerateConfig) [2]string {
+	if cfg.Model.enumColumn(table, col) {
+		if !cfg.LiteralEnums {
+			return [2]string{cfg.Model.enumName(table.Name, col), ""}
+		}
+
+		values := make([]string, 0, len(col.EnumValues))
+
+		for _, value := range col.EnumValues {
+			values = append(values, pythonString(value))
+		}
+
+		return [2]string{fmt.Sprintf("Literal[%s]", strings.Join(values, ", ")), "typing"}
+	}
+
+	// Decimal and UUID validate the numbers and strings the go models encode
+	// them as
+	switch shape := cfg.Model.jsonShape(table, col); {
+	case (shape == jsonFloat || shape == jsonString) && col.kind() == decimalKind:
+		return [2]string{"Decimal", "decimal"}
+	case shape == jsonString && col.kind() == uuidKind:
+		return [2]string{"UUID", "uuid"}
+	default:
+		return pythonShapeTypes[shape]
+	}
+}
+
+// pythonIdentifier returns the snake_case field name of a column, suffixing
+// keywords with an underscore
+func pythonIdentifier(columnName string) string {
+	name := strings.Join(enumWords(columnName), "_")
+
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "field_" + name
+	}
+	if pythonKeywords[name] {
+		name += "_"
+	}
+
+	return name
+}
+
+// pythonDocstring returns comment as a docstring indented by indent followed by
+// a blank line, or nothing if comment is empty
+func pythonDocstring(comment, indent string) string {
+	comment = strings.TrimSpace(comment)
+
+	if comment == "" {
+		return ""
+	}
+
+	comment = strings.ReplaceAll(strings.ReplaceAll(comment, `\`, `\\`), `"""`, `\"\"\"`)
+	lines := strings.Split(comment, "\n")
+
+	if len(lines) == 1 {
+		return fmt.Sprintf("%s\"\"\"%s\"\"\"\n\n", indent, lines[0])
+	}
+
+	var doc strings.Builder
+
+	doc.WriteString(indent + `"""` + "\n")
+
+	for _, line := range lines {
+		doc.WriteString(strings.TrimRight(indent+strings.TrimSpace(line), " ") + "\n")
+	}
+
+	doc.WriteString(indent + `"""` + "\n\n")
+
+	return doc.String()
+}
+
+// pythonString returns value as a python string literal
+func pythonString(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
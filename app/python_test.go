@@ -0,0 +1,209 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGeneratePythonModels(t *testing.T) {
+	var err error
+
+	pythonDir := filepath.Join(t.TempDir(), "models")
+
+	if err = GeneratePythonModels(nil, "", "", GenerateConfig{}); err == nil {
+		t.Fatalf("should have error\n")
+	}
+
+	tables := []Table{
+		{
+			Name: "user_profile",
+			Columns: []Column{
+				{Name: "id", DataType: "uuid"},
+				{Name: "name", DataType: "varchar", Nullable: true},
+				{Name: "status", DataType: "user_status", EnumValues: []string{"active", "on-hold"}},
+				{Name: "balance", DataType: "numeric"},
+				{Name: "created_at", DataType: "timestamptz"},
+			},
+		},
+		{
+			Name: "orders",
+			Columns: []Column{
+				{Name: "id", DataType: "int8"},
+				{Name: "buyer", DataType: "uuid"},
+			},
+			ForeignKeys: []ForeignKey{
+				{ColumnName: "buyer", ForeignTableName: "user_profile"},
+			},
+		},
+	}
+
+	if err = GeneratePythonModels(tables, pythonDir, "models", GenerateConfig{}); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	content, err := os.ReadFile(filepath.Join(pythonDir, "models.py"))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expected := "from __future__ import annotations\n\n" +
+		"from datetime import datetime\n" +
+		"from decimal import Decimal\n" +
+		"from enum import Enum\n" +
+		"from typing import Optional\n" +
+		"from uuid import UUID\n\n" +
+		"from pydantic import BaseModel, ConfigDict, Field\n\n\n" +
+		"class UserProfileStatus(str, Enum):\n"
+
+	if !strings.HasPrefix(string(content), expected) {
+		t.Fatalf("expected python output to start with:\n%s\ngot:\n%s\n", expected, string(content))
+	}
+
+	content, err = os.ReadFile(filepath.Join(pythonDir, pythonInitFile))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expected = "from .models import UserProfile, UserProfileStatus, Order\n\n" +
+		"__all__ = [\n" +
+		"    \"UserProfile\",\n" +
+		"    \"UserProfileStatus\",\n" +
+		"    \"Order\",\n" +
+		"]\n"
+
+	if string(content) != expected {
+		t.Fatalf("expected __init__.py:\n%s\ngot:\n%s\n", expected, string(content))
+	}
+
+	cfg := GenerateConfig{LiteralEnums: true}
+
+	if err = GeneratePythonModels(tables, pythonDir, "", cfg); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	content, err = os.ReadFile(filepath.Join(pythonDir, "orders.py"))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if !strings.Contains(string(content), "if TYPE_CHECKING:\n    from .user_profile import UserProfile\n") {
+		t.Fatalf("expected orders.py to import the models it relates to; got:\n%s\n", string(content))
+	}
+
+	content, err = os.ReadFile(filepath.Join(pythonDir, "user_profile.py"))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if !strings.Contains(string(content), "status: Literal[\"active\", \"on-hold\"]\n") {
+		t.Fatalf("expected literal enum; got:\n%s\n", string(content))
+	}
+
+	content, err = os.ReadFile(filepath.Join(pythonDir, pythonInitFile))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if !strings.Contains(string(content), "from .orders import Order\n\nOrder.model_rebuild()\n") {
+		t.Fatalf("expected models with relations to be rebuilt; got:\n%s\n", string(content))
+	}
+}
+
+func TestPythonModel(t *testing.T) {
+	snakeCase := GenerateConfig{
+		Model: ModelConfig{Tags: []TagGenerator{{Name: "json", Case: SnakeCase}}},
+	}
+
+	tests := []struct {
+		name     string
+		table    Table
+		cfg      GenerateConfig
+		expected string
+	}{
+		{
+			name: "columns",
+			table: Table{
+				Name:    "user_profile",
+				Comment: "Person using the app",
+				Columns: []Column{
+					{Name: "id", DataType: "uuid"},
+					{Name: "name", DataType: "varchar", Nullable: true},
+					{Name: "class", DataType: "text", Comment: "Class of service"},
+					{Name: "balance", DataType: "numeric"},
+					{Name: "created_at", DataType: "timestamptz"},
+				},
+			},
+			cfg: snakeCase,
+			expected: "class UserProfile(BaseModel):\n" +
+				"    \"\"\"Person using the app\"\"\"\n\n" +
+				"    model_config = ConfigDict(populate_by_name=True)\n\n" +
+				"    id: UUID\n" +
+				"    name: Optional[str] = None\n" +
+				"    class_: str = Field(alias=\"class\", description=\"Class of service\")\n" +
+				"    balance: Decimal\n" +
+				"    created_at: datetime\n",
+		},
+		{
+			name: "enum",
+			table: Table{
+				Name: "user_profile",
+				Columns: []Column{
+					{Name: "status", DataType: "user_status", EnumValues: []string{"active", "on-hold"}},
+				},
+			},
+			expected: "class UserProfileStatus(str, Enum):\n" +
+				"    ACTIVE = \"active\"\n" +
+				"    ON_HOLD = \"on-hold\"\n\n\n" +
+				"class UserProfile(BaseModel):\n" +
+				"    model_config = ConfigDict(populate_by_name=True)\n\n" +
+				"    status: UserProfileStatus\n",
+		},
+		{
+			name: "plural table",
+			table: Table{
+				Name:    "orders",
+				Columns: []Column{{Name: "id", DataType: "int8"}},
+			},
+			expected: "class Order(BaseModel):\n" +
+				"    model_config = ConfigDict(populate_by_name=True)\n\n" +
+				"    id: int\n",
+		},
+		{
+			name: "self relation",
+			table: Table{
+				Name:        "employee",
+				Columns:     []Column{{Name: "manager_id", DataType: "int8", Nullable: true}},
+				ForeignKeys: []ForeignKey{{ColumnName: "manager_id", ForeignTableName: "employee"}},
+			},
+			expected: "class Employee(BaseModel):\n" +
+				"    model_config = ConfigDict(populate_by_name=True)\n\n" +
+				"    manager_id: Optional[int] = Field(default=None, alias=\"managerID\")\n" +
+				"    manager: Optional[Employee] = None\n",
+		},
+		{
+			name: "relation without id suffix",
+			table: Table{
+				Name:        "orders",
+				Columns:     []Column{{Name: "buyer", DataType: "int8"}},
+				ForeignKeys: []ForeignKey{{ColumnName: "buyer", ForeignTableName: "user_profile"}},
+			},
+			expected: "class Order(BaseModel):\n" +
+				"    model_config = ConfigDict(populate_by_name=True)\n\n" +
+				"    buyer: int\n" +
+				"    buyer_user_profile: Optional[UserProfile] = Field(default=None, alias=\"buyerUserProfile\")\n",
+		},
+	}
+
+	for _, test := range tests {
+		if model := pythonModel(test.table, test.cfg); model != test.expected {
+			t.Fatalf("%s: expected python model:\n%s\ngot:\n%s\n", test.name, test.expected, model)
+		}
+	}
+}
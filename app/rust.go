@@ -0,0 +1,458 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kenshaw/snaker"
+	"github.com/pkg/errors"
+)
+
+// rustShapeTypes is the rust type of every json shape, fully qualified so no
+// imports besides serde's are needed
+//
+// Bytes are base64 strings in the json of the go models so they are kept as a
+// String rather than decoded into a Vec<u8>, unless sqlx reads them
+var rustShapeTypes = map[jsonShape]string{
+	jsonAny:    "serde_json::Value",
+	jsonString: "String",
+	jsonInt:    "i32",
+	jsonBigint: "i64",
+	jsonFloat:  "f64",
+	jsonBool:   "bool",
+	jsonTime:   "chrono::DateTime<chrono::Utc>",
+	jsonBytes:  "String",
+}
+
+// rustSqlxTypes are the rust types sqlx reads columns as, keyed by data type,
+// used instead of the type of their json shape when structs derive sqlx::FromRow
+var rustSqlxTypes = map[string]string{
+	"timestamp": "chrono::NaiveDateTime",
+	"datetime":  "chrono::NaiveDateTime",
+	"date":      "chrono::NaiveDate",
+	"time":      "chrono::NaiveTime",
+	"real":      "f32",
+	"float4":    "f32",
+	"float":     "f32",
+}
+
+// rustKeywords are the keywords of rust which have to be written as raw
+// identifiers when used as field names
+var rustKeywords = map[string]bool{
+	"as": true, "async": true, "await": true, "break": true, "const": true,
+	"continue": true, "dyn": true, "else": true, "enum": true, "extern": true,
+	"false": true, "fn": true, "for": true, "if": true, "impl": true,
+	"in": true, "let": true, "loop": true, "match": true, "mod": true,
+	"move": true, "mut": true, "pub": true, "ref": true, "return": true,
+	"static": true, "struct": true, "trait": true, "true": true, "type": true,
+	"unsafe": true, "use": true, "where": true, "while": true, "abstract": true,
+	"become": true, "box": true, "do": true, "final": true, "macro": true,
+	"override": true, "priv": true, "try": true, "typeof": true, "unsized": true,
+	"virtual": true, "yield": true,
+}
+
+// rustReservedIdentifiers are keywords that can't be raw identifiers either
+var rustReservedIdentifiers = map[string]bool{
+	"crate": true, "self": true, "Self": true, "super": true,
+}
+
+const (
+	rustModFile = "mod.rs"
+
+	// rustRfc3339Module is the module of the serde helpers reading and writing
+	// naive dates and date times as the RFC 3339 strings of the go models
+	rustRfc3339Module = "rfc3339"
+)
+
+// rustRfc3339 is the content of rustRfc3339Module
+const rustRfc3339 = `use chrono::{DateTime, NaiveDate, NaiveDateTime, NaiveTime};
+use serde::{de::Error, Deserialize, Deserializer, Serializer};
+
+pub trait Rfc3339: Sized {
+    fn to_rfc3339(&self) -> String;
+    fn from_rfc3339(value: &str) -> Result<Self, chrono::ParseError>;
+}
+
+impl Rfc3339 for NaiveDateTime {
+    fn to_rfc3339(&self) -> String {
+        self.and_utc().to_rfc3339()
+    }
+
+    fn from_rfc3339(value: &str) -> Result<Self, chrono::ParseError> {
+        DateTime::parse_from_rfc3339(value).map(|t| t.naive_utc())
+    }
+}
+
+impl Rfc3339 for NaiveDate {
+    fn to_rfc3339(&self) -> String {
+        self.and_time(NaiveTime::MIN).and_utc().to_rfc3339()
+    }
+
+    fn from_rfc3339(value: &str) -> Result<Self, chrono::ParseError> {
+        DateTime::parse_from_rfc3339(value).map(|t| t.naive_utc().date())
+    }
+}
+
+pub fn serialize<T: Rfc3339, S: Serializer>(value: &T, serializer: S) -> Result<S::Ok, S::Error> {
+    serializer.serialize_str(&value.to_rfc3339())
+}
+
+pub fn deserialize<'de, T: Rfc3339, D: Deserializer<'de>>(deserializer: D) -> Result<T, D::Error> {
+    T::from_rfc3339(&String::deserialize(deserializer)?).map_err(D::Error::custom)
+}
+
+pub mod option {
+    use super::Rfc3339;
+    use serde::{de::Error, Deserialize, Deserializer, Serializer};
+
+    pub fn serialize<T: Rfc3339, S: Serializer>(value: &Option<T>, serializer: S) -> Result<S::Ok, S::Error> {
+        match value {
+            Some(value) => serializer.serialize_some(&value.to_rfc3339()),
+            None => serializer.serialize_none(),
+        }
+    }
+
+    pub fn deserialize<'de, T: Rfc3339, D: Deserializer<'de>>(deserializer: D) -> Result<Option<T>, D::Error> {
+        Option::<String>::deserialize(deserializer)?
+            .map(|value| T::from_rfc3339(&value).map_err(D::Error::custom))
+            .transpose()
+    }
+}
+`
+
+// GenerateRustModels generates a serde struct for every table in a single file
+// named rustFile within rustDir
+//
+// When rustFile is empty every table is generated into its own module named
+// after the table along with a mod.rs re-exporting every module instead.  When
+// cfg.FromRow is set structs also derive sqlx::FromRow and enums sqlx::Type, and
+// fields are typed as sqlx reads their column with serde helpers keeping the json
+// of the go models
+func GenerateRustModels(tables []Table, rustDir, rustFile string, cfg GenerateConfig) error {
+	if rustDir == "" {
+		return errors.WithStack(fmt.Errorf("model-gen: rustDir parameter can't be empty"))
+	}
+
+	if err := os.MkdirAll(rustDir, os.ModePerm); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if rustFile != "" {
+		if !strings.HasSuffix(rustFile, ".rs") {
+			rustFile += ".rs"
+		}
+
+		return writeRustFile(filepath.Join(rustDir, rustFile), tables, cfg, false)
+	}
+
+	var mods, uses strings.Builder
+
+	for _, table := range tables {
+		if err := writeRustFile(filepath.Join(rustDir, table.Name+".rs"), []Table{table}, cfg, true); err != nil {
+			return err
+		}
+
+		mods.WriteString(fmt.Sprintf("pub mod %s;\n", table.Name))
+		uses.WriteString(fmt.Sprintf("pub use %s::*;\n", table.Name))
+	}
+
+	// The serde helpers are declared once for every module using them
+	if rustUsesRfc3339(tables, cfg) {
+		if err := os.WriteFile(filepath.Join(rustDir, rustRfc3339Module+".rs"), []byte(rustRfc3339), 0644); err != nil {
+			return errors.WithStack(err)
+		}
+
+		mods.WriteString(fmt.Sprintf("pub mod %s;\n", rustRfc3339Module))
+	}
+
+	return errors.WithStack(os.WriteFile(
+		filepath.Join(rustDir, rustModFile),
+		[]byte(mods.String()+"\n"+uses.String()),
+		0644,
+	))
+}
+
+// writeRustFile writes the structs of tables into fileName
+//
+// Structs of other tables are used from their sibling modules when perTable is set
+func writeRustFile(fileName string, tables []Table, cfg GenerateConfig, perTable bool) error {
+	var b, structs strings.Builder
+
+	relations := map[string]bool{}
+
+	for _, table := range tables {
+		for _, fk := range table.ForeignKeys {
+			if perTable && fk.ForeignTableName != table.Name {
+				relations[fk.ForeignTableName] = true
+			}
+		}
+
+		structs.WriteString("\n")
+		structs.WriteString(rustStruct(table, cfg))
+	}
+
+	usesRfc3339 := rustUsesRfc3339(tables, cfg)
+
+	b.WriteString("use serde::{Deserialize, Serialize};\n")
+
+	if len(relations) > 0 || (perTable && usesRfc3339) {
+		b.WriteString("\n")
+	}
+
+	if perTable && usesRfc3339 {
+		b.WriteString(fmt.Sprintf("use super::%s;\n", rustRfc3339Module))
+	}
+
+	for _, tableName := range sortedKeys(relations) {
+		b.WriteString(fmt.Sprintf("use super::%s::%s;\n", tableName, cfg.Model.structName(tableName)))
+	}
+
+	b.WriteString(structs.String())
+
+	if !perTable && usesRfc3339 {
+		b.WriteString("\n" + rustModule(rustRfc3339Module, rustRfc3339))
+	}
+
+	return errors.WithStack(os.WriteFile(fileName, []byte(b.String()), 0644))
+}
+
+// rustStruct returns the struct of table followed by the enums of its enum columns
+func rustStruct(table Table, cfg GenerateConfig) string {
+	var b, enums strings.Builder
+
+	b.WriteString(docLines(table.Comment, ""))
+
+	if cfg.FromRow {
+		b.WriteString("#[derive(Debug, Clone, Serialize, Deserialize, sqlx::FromRow)]\n")
+	} else {
+		b.WriteString("#[derive(Debug, Clone, Serialize, Deserialize)]\n")
+	}
+
+	b.WriteString(fmt.Sprintf("pub struct %s {\n", cfg.Model.structName(table.Name)))
+
+	for _, col := range table.Columns {
+		jsonName, omitEmpty, ok := cfg.Model.jsonField(table.Name, col)
+
+		// Fields hidden from json are never part of the api contract
+		if !ok {
+			continue
+		}
+
+		name := rustIdentifier(col.Name)
+		fieldType := rustType(table, col, cfg)
+		optional := col.Nullable || omitEmpty
+		with := rustSerdeWith(fieldType, cfg.Model.jsonShape(table, col), optional)
+
+		if optional {
+			fieldType = fmt.Sprintf("Option<%s>", fieldType)
+		}
+
+		b.WriteString(docLines(col.Comment, "    "))
+		b.WriteString(rustSerde(name, jsonName, omitEmpty, with))
+
+		// sqlx reads columns by field name so renamed fields need the column name
+		if cfg.FromRow && name != col.Name {
+			b.WriteString(fmt.Sprintf("    #[sqlx(rename = %s)]\n", rustString(col.Name)))
+		}
+
+		b.WriteString(fmt.Sprintf("    pub %s: %s,\n", name, fieldType))
+
+		if cfg.Model.enumColumn(table, col) {
+			enums.WriteString(rustEnum(cfg.Model.enumName(table.Name, col), col, cfg))
+		}
+	}
+
+	// Relations are only set when they are loaded so they are always optional and
+	// boxed as structs may reference themselves
+	for _, fk := range table.ForeignKeys {
+		jsonName, omitEmpty := cfg.Model.relationJSONField(table, fk)
+		name := rustIdentifier(cfg.Model.relationName(table, fk))
+
+		b.WriteString(rustSerde(name, jsonName, omitEmpty, ""))
+
+		if cfg.FromRow {
+			b.WriteString("    #[sqlx(skip)]\n")
+		}
+
+		b.WriteString(fmt.Sprintf("    pub %s: Option<Box<%s>>,\n", name, cfg.Model.structName(fk.ForeignTableName)))
+	}
+
+	b.WriteString("}\n")
+	b.WriteString(enums.String())
+
+	return b.String()
+}
+
+// rustSerde returns the serde attribute of a field serialized as jsonName with
+// the serde helper module with, or nothing if the field needs none
+func rustSerde(name, jsonName string, omitEmpty bool, with string) string {
+	var args []string
+
+	if strings.TrimPrefix(name, "r#") != jsonName {
+		args = append(args, "rename = "+rustString(jsonName))
+	}
+	if omitEmpty {
+		args = append(args, `default, skip_serializing_if = "Option::is_none"`)
+	}
+	if with != "" {
+		args = append(args, "with = "+rustString(with))
+	}
+
+	if len(args) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("    #[serde(%s)]\n", strings.Join(args, ", "))
+}
+
+// rustEnum returns an enum named name for col with a variant per value
+//
+// Enums also map to the database type of col when cfg.FromRow is set
+func rustEnum(name string, col Column, cfg GenerateConfig) string {
+	var b strings.Builder
+
+	if cfg.FromRow {
+		b.WriteString("\n#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize, Deserialize, sqlx::Type)]\n")
+		b.WriteString(fmt.Sprintf("#[sqlx(type_name = %s)]\n", rustString(col.DataType)))
+	} else {
+		b.WriteString("\n#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize, Deserialize)]\n")
+	}
+
+	b.WriteString(fmt.Sprintf("pub enum %s {\n", name))
+
+	for i, variant := range enumConstants(col.EnumValues, pascalEnumConstant) {
+		b.WriteString(fmt.Sprintf("    #[serde(rename = %s)]\n", rustString(col.EnumValues[i])))
+
+		if cfg.FromRow {
+			b.WriteString(fmt.Sprintf("    #[sqlx(rename = %s)]\n", rustString(col.EnumValues[i])))
+		}
+
+		b.WriteString(fmt.Sprintf("    %s,\n", variant))
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// rustType returns the rust type of col of table without optionality
+//
+// rust_decimal and uuid deserialize the numbers and strings the go models encode
+// them as.  When cfg.FromRow is set col is typed as sqlx reads it instead, which
+// rustSerdeWith picks the serde helpers for
+func rustType(table Table, col Column, cfg GenerateConfig) string {
+	if cfg.Model.enumColumn(table, col) {
+		return cfg.Model.enumName(table.Name, col)
+	}
+
+	if cfg.FromRow {
+		switch col.kind() {
+		case bytesKind:
+			return "Vec<u8>"
+		case decimalKind:
+			return "rust_decimal::Decimal"
+		case uuidKind:
+			return "uuid::Uuid"
+		}
+
+		if rustSqlxType, ok := rustSqlxTypes[col.DataType]; ok {
+			return rustSqlxType
+		}
+	}
+
+	switch shape := cfg.Model.jsonShape(table, col); {
+	case (shape == jsonFloat || shape == jsonString) && col.kind() == decimalKind:
+		return "rust_decimal::Decimal"
+	case shape == jsonString && col.kind() == uuidKind:
+		return "uuid::Uuid"
+	case shape == jsonInt && smallintTypes[col.DataType]:
+		return "i16"
+	default:
+		return rustShapeTypes[shape]
+	}
+}
+
+// rustSerdeWith returns the serde helper module reading and writing rustType as
+// the json shape the go models encode it as, or nothing if serde's own does
+func rustSerdeWith(rustType string, shape jsonShape, optional bool) string {
+	var with, optionWith string
+
+	switch {
+	case rustType == "Vec<u8>" && shape == jsonBytes:
+		with = "serde_with::As::<serde_with::base64::Base64>"
+		optionWith = "serde_with::As::<Option<serde_with::base64::Base64>>"
+	case (rustType == "chrono::NaiveDateTime" || rustType == "chrono::NaiveDate") && shape == jsonTime:
+		with = rustRfc3339Module
+		optionWith = rustRfc3339Module + "::option"
+	case rustType == "rust_decimal::Decimal" && (shape == jsonFloat || shape == jsonInt || shape == jsonBigint):
+		with = "rust_decimal::serde::float"
+		optionWith = "rust_decimal::serde::float_option"
+	}
+
+	if optional {
+		return optionWith
+	}
+
+	return with
+}
+
+// rustUsesRfc3339 returns whether a field of tables uses the serde helpers of
+// rustRfc3339Module
+func rustUsesRfc3339(tables []Table, cfg GenerateConfig) bool {
+	for _, table := range tables {
+		for _, col := range table.Columns {
+			if _, _, ok := cfg.Model.jsonField(table.Name, col); !ok {
+				continue
+			}
+
+			if rustSerdeWith(rustType(table, col, cfg), cfg.Model.jsonShape(table, col), false) == rustRfc3339Module {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// rustModule returns an inline module named name holding content
+func rustModule(name, content string) string {
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("pub mod %s {\n", name))
+
+	for _, line := range strings.Split(strings.TrimSuffix(content, "\n"), "\n") {
+		if line != "" {
+			line = "    " + line
+		}
+
+		b.WriteString(line + "\n")
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// rustIdentifier returns the snake_case field name of a column, written as a raw
+// identifier if it is a keyword
+func rustIdentifier(columnName string) string {
+	name := strings.ToLower(snaker.CamelToSnake(strings.Join(enumWords(columnName), "_")))
+
+	switch {
+	case name == "" || (name[0] >= '0' && name[0] <= '9'):
+		return "field_" + name
+	case rustReservedIdentifiers[name]:
+		return name + "_"
+	case rustKeywords[name]:
+		return "r#" + name
+	}
+
+	return name
+}
+
+// rustString returns value as a rust string literal
+func rustString(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
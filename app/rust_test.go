@@ -0,0 +1,259 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateRustModels(t *testing.T) {
+	var err error
+
+	rustDir := filepath.Join(t.TempDir(), "models")
+
+	if err = GenerateRustModels(nil, "", "", GenerateConfig{}); err == nil {
+		t.Fatalf("should have error\n")
+	}
+
+	tables := []Table{
+		{
+			Name:    "user_profile",
+			Columns: []Column{{Name: "id", DataType: "int8"}},
+		},
+		{
+			Name: "orders",
+			Columns: []Column{
+				{Name: "id", DataType: "int8"},
+				{Name: "buyer", DataType: "int8"},
+			},
+			ForeignKeys: []ForeignKey{
+				{ColumnName: "buyer", ForeignTableName: "user_profile"},
+			},
+		},
+	}
+
+	if err = GenerateRustModels(tables, rustDir, "models", GenerateConfig{}); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	content, err := os.ReadFile(filepath.Join(rustDir, "models.rs"))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expected := "use serde::{Deserialize, Serialize};\n\n" +
+		"#[derive(Debug, Clone, Serialize, Deserialize)]\n" +
+		"pub struct UserProfile {\n"
+
+	if !strings.HasPrefix(string(content), expected) {
+		t.Fatalf("expected rust output to start with:\n%s\ngot:\n%s\n", expected, string(content))
+	}
+
+	if err = GenerateRustModels(tables, rustDir, "", GenerateConfig{}); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	content, err = os.ReadFile(filepath.Join(rustDir, "orders.rs"))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if !strings.Contains(string(content), "use super::user_profile::UserProfile;\n") {
+		t.Fatalf("expected orders.rs to use the structs it relates to; got:\n%s\n", string(content))
+	}
+
+	content, err = os.ReadFile(filepath.Join(rustDir, rustModFile))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expected = "pub mod user_profile;\npub mod orders;\n\npub use user_profile::*;\npub use orders::*;\n"
+
+	if string(content) != expected {
+		t.Fatalf("expected mod.rs:\n%s\ngot:\n%s\n", expected, string(content))
+	}
+
+	if rustType(Table{}, Column{Name: "avatar", DataType: "bytea"}, GenerateConfig{}) != "String" {
+		t.Fatalf("bytes should be a base64 String like the go models encode them\n")
+	}
+
+	// Naive dates need serde helpers declared once for every module
+	tables[0].Columns = append(tables[0].Columns, Column{Name: "birthday", DataType: "date"})
+
+	if err = GenerateRustModels(tables, rustDir, "", GenerateConfig{FromRow: true}); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	content, err = os.ReadFile(filepath.Join(rustDir, "user_profile.rs"))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if !strings.Contains(string(content), "use super::rfc3339;\n") {
+		t.Fatalf("expected user_profile.rs to use the rfc3339 helpers; got:\n%s\n", string(content))
+	}
+
+	if content, err = os.ReadFile(filepath.Join(rustDir, "rfc3339.rs")); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if !strings.HasPrefix(string(content), "use chrono::{DateTime, NaiveDate, NaiveDateTime, NaiveTime};\n") {
+		t.Fatalf("expected rfc3339.rs to hold the helpers themselves; got:\n%s\n", string(content))
+	}
+
+	if err = GenerateRustModels(tables, rustDir, "models", GenerateConfig{FromRow: true}); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	if content, err = os.ReadFile(filepath.Join(rustDir, "models.rs")); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if !strings.Contains(string(content), "}\n\npub mod rfc3339 {\n    use chrono::{DateTime, NaiveDate, NaiveDateTime, NaiveTime};\n") {
+		t.Fatalf("expected models.rs to declare the rfc3339 helpers inline; got:\n%s\n", string(content))
+	}
+}
+
+func TestRustStruct(t *testing.T) {
+	tests := []struct {
+		name     string
+		table    Table
+		cfg      GenerateConfig
+		expected string
+	}{
+		{
+			name: "columns",
+			table: Table{
+				Name:    "user_profile",
+				Comment: "Person using the app",
+				Columns: []Column{
+					{Name: "id", DataType: "uuid"},
+					{Name: "name", DataType: "varchar", Nullable: true},
+					{Name: "type", DataType: "int2", Comment: "Kind of profile"},
+					{Name: "created_at", DataType: "timestamptz"},
+				},
+			},
+			cfg: GenerateConfig{
+				Model:   ModelConfig{Tags: []TagGenerator{{Name: "json", Case: SnakeCase}}},
+				FromRow: true,
+			},
+			expected: "/// Person using the app\n" +
+				"#[derive(Debug, Clone, Serialize, Deserialize, sqlx::FromRow)]\n" +
+				"pub struct UserProfile {\n" +
+				"    pub id: uuid::Uuid,\n" +
+				"    pub name: Option<String>,\n" +
+				"    /// Kind of profile\n" +
+				"    #[sqlx(rename = \"type\")]\n" +
+				"    pub r#type: i16,\n" +
+				"    pub created_at: chrono::DateTime<chrono::Utc>,\n" +
+				"}\n",
+		},
+		{
+			name: "sqlx types",
+			table: Table{
+				Name: "user_profile",
+				Columns: []Column{
+					{Name: "avatar", DataType: "bytea", Nullable: true},
+					{Name: "birthday", DataType: "date"},
+					{Name: "updated_at", DataType: "timestamp", Nullable: true},
+					{Name: "starts_at", DataType: "time"},
+					{Name: "rating", DataType: "real"},
+					{Name: "balance", DataType: "numeric"},
+				},
+			},
+			cfg: GenerateConfig{
+				Model:   ModelConfig{Tags: []TagGenerator{{Name: "json", Case: SnakeCase}}},
+				FromRow: true,
+			},
+			expected: "#[derive(Debug, Clone, Serialize, Deserialize, sqlx::FromRow)]\n" +
+				"pub struct UserProfile {\n" +
+				"    #[serde(with = \"serde_with::As::<Option<serde_with::base64::Base64>>\")]\n" +
+				"    pub avatar: Option<Vec<u8>>,\n" +
+				"    #[serde(with = \"rfc3339\")]\n" +
+				"    pub birthday: chrono::NaiveDate,\n" +
+				"    #[serde(with = \"rfc3339::option\")]\n" +
+				"    pub updated_at: Option<chrono::NaiveDateTime>,\n" +
+				"    pub starts_at: chrono::NaiveTime,\n" +
+				"    pub rating: f32,\n" +
+				"    #[serde(with = \"rust_decimal::serde::float\")]\n" +
+				"    pub balance: rust_decimal::Decimal,\n" +
+				"}\n",
+		},
+		{
+			name: "enum",
+			table: Table{
+				Name: "user_profile",
+				Columns: []Column{
+					{Name: "status", DataType: "user_status", EnumValues: []string{"active", "on-hold"}},
+				},
+			},
+			cfg: GenerateConfig{FromRow: true},
+			expected: "#[derive(Debug, Clone, Serialize, Deserialize, sqlx::FromRow)]\n" +
+				"pub struct UserProfile {\n" +
+				"    pub status: UserProfileStatus,\n" +
+				"}\n\n" +
+				"#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize, Deserialize, sqlx::Type)]\n" +
+				"#[sqlx(type_name = \"user_status\")]\n" +
+				"pub enum UserProfileStatus {\n" +
+				"    #[serde(rename = \"active\")]\n" +
+				"    #[sqlx(rename = \"active\")]\n" +
+				"    Active,\n" +
+				"    #[serde(rename = \"on-hold\")]\n" +
+				"    #[sqlx(rename = \"on-hold\")]\n" +
+				"    OnHold,\n" +
+				"}\n",
+		},
+		{
+			name: "plural table",
+			table: Table{
+				Name:    "orders",
+				Columns: []Column{{Name: "id", DataType: "int8"}},
+			},
+			expected: "#[derive(Debug, Clone, Serialize, Deserialize)]\n" +
+				"pub struct Order {\n" +
+				"    pub id: i64,\n" +
+				"}\n",
+		},
+		{
+			name: "self relation",
+			table: Table{
+				Name:        "employee",
+				Columns:     []Column{{Name: "manager_id", DataType: "int8", Nullable: true}},
+				ForeignKeys: []ForeignKey{{ColumnName: "manager_id", ForeignTableName: "employee"}},
+			},
+			cfg: GenerateConfig{FromRow: true},
+			expected: "#[derive(Debug, Clone, Serialize, Deserialize, sqlx::FromRow)]\n" +
+				"pub struct Employee {\n" +
+				"    #[serde(rename = \"managerID\")]\n" +
+				"    pub manager_id: Option<i64>,\n" +
+				"    #[sqlx(skip)]\n" +
+				"    pub manager: Option<Box<Employee>>,\n" +
+				"}\n",
+		},
+		{
+			name: "relation without id suffix",
+			table: Table{
+				Name:        "orders",
+				Columns:     []Column{{Name: "buyer", DataType: "int8"}},
+				ForeignKeys: []ForeignKey{{ColumnName: "buyer", ForeignTableName: "user_profile"}},
+			},
+			expected: "#[derive(Debug, Clone, Serialize, Deserialize)]\n" +
+				"pub struct Order {\n" +
+				"    pub buyer: i64,\n" +
+				"    #[serde(rename = \"buyerUserProfile\")]\n" +
+				"    pub buyer_user_profile: Option<Box<UserProfile>>,\n" +
+				"}\n",
+		},
+	}
+
+	for _, test := range tests {
+		if s := rustStruct(test.table, test.cfg); s != test.expected {
+			t.Fatalf("%s: expected rust struct:\n%s\ngot:\n%s\n", test.name, test.expected, s)
+		}
+	}
+}
@@ -0,0 +1,597 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrQueryCheckConstraints = errors.New("model-gen: query check constraint error")
+	ErrQueryEnumValues       = errors.New("model-gen: query enum value error")
+)
+
+// Table is the introspected representation of a database table every generator
+// works from
+type Table struct {
+	Name        string
+	Comment     string
+	Columns     []Column
+	ForeignKeys []ForeignKey
+
+	// ExternalForeignKeys reference tables of other schemas, which models have
+	// no relation to as they are only generated for tables of a single schema
+	ExternalForeignKeys []ForeignKey
+}
+
+// Column is the introspected representation of a table column
+type Column struct {
+	Name string
+
+	// DataType is the database type of the column without any length or
+	// precision, eg. "varchar" or "int8"
+	DataType string
+
+	Comment    string
+	Nullable   bool
+	HasDefault bool
+	PrimaryKey bool
+
+	// MaxLength is the maximum character length of the column, 0 if unbounded
+	MaxLength int
+
+	// NumericPrecision and NumericScale are only set for numeric and decimal columns
+	NumericPrecision int
+	NumericScale     int
+
+	// EnumValues holds every value the column accepts, either from an enum type
+	// or an "in" check constraint
+	EnumValues []string
+
+	// Checks holds the simple comparisons of check constraints on the column
+	Checks []Check
+}
+
+// Check is a comparison of a column against a constant from a check constraint
+type Check struct {
+	Operator string
+	Value    string
+}
+
+// ForeignKey is a column referencing a column of another table
+type ForeignKey struct {
+	ColumnName string
+
+	// ForeignSchema is the schema of the referenced table when it isn't the
+	// schema of the table itself, empty otherwise
+	ForeignSchema string
+
+	ForeignTableName string
+
+	// ForeignColumnName is empty when sqlite foreign keys reference the primary
+	// key implicitly
+	ForeignColumnName string
+}
+
+// dataKind groups the database types of every driver by what they hold
+type dataKind int
+
+const (
+	otherKind dataKind = iota
+	stringKind
+	integerKind
+	decimalKind
+	floatKind
+	boolKind
+	timeKind
+	dateKind
+	uuidKind
+	jsonKind
+	bytesKind
+)
+
+var dataKindMap = map[string]dataKind{
+	"varchar":           stringKind,
+	"character varying": stringKind,
+	"char":              stringKind,
+	"character":         stringKind,
+	"bpchar":            stringKind,
+	"text":              stringKind,
+	"tinytext":          stringKind,
+	"mediumtext":        stringKind,
+	"longtext":          stringKind,
+	"citext":            stringKind,
+	"enum":              stringKind,
+	"int":               integerKind,
+	"integer":           integerKind,
+	"int2":              integerKind,
+	"int4":              integerKind,
+	"int8":              integerKind,
+	"smallint":          integerKind,
+	"mediumint":         integerKind,
+	"bigint":            integerKind,
+	"tinyint":           integerKind,
+	"serial":            integerKind,
+	"bigserial":         integerKind,
+	"year":              integerKind,
+	"numeric":           decimalKind,
+	"decimal":           decimalKind,
+	"money":             decimalKind,
+	"real":              floatKind,
+	"float":             floatKind,
+	"float4":            floatKind,
+	"float8":            floatKind,
+	"double":            floatKind,
+	"double precision":  floatKind,
+	"bool":              boolKind,
+	"boolean":           boolKind,
+	"timestamp":         timeKind,
+	"timestamptz":       timeKind,
+	"datetime":          timeKind,
+	"time":              timeKind,
+	"timetz":            timeKind,
+	"date":              dateKind,
+	"uuid":              uuidKind,
+	"json":              jsonKind,
+	"jsonb":             jsonKind,
+	"bytea":             bytesKind,
+	"blob":              bytesKind,
+	"binary":            bytesKind,
+	"varbinary":         bytesKind,
+	"tinyblob":          bytesKind,
+	"mediumblob":        bytesKind,
+	"longblob":          bytesKind,
+}
+
+// smallintTypes are the integer types that fit into 16 bits, for languages with
+// a 16 bit integer type
+var smallintTypes = map[string]bool{
+	"int2":     true,
+	"smallint": true,
+	"tinyint":  true,
+}
+
+var (
+	typeModifierReg = regexp.MustCompile(`^[^(]*\(\s*(\d+)\s*(?:,\s*(\d+)\s*)?\)`)
+	enumTypeReg     = regexp.MustCompile(`(?i)^enum\s*\((.*)\)$`)
+	castReg         = regexp.MustCompile(`::[a-zA-Z_ ]+(\[\])?`)
+	introducerReg   = regexp.MustCompile(`_[a-zA-Z0-9]+'`)
+	comparisonReg   = regexp.MustCompile(`^\(*\s*(\w+)\s*\)*\s*(>=|<=|<>|!=|>|<|=)\s*\(*\s*(-?\d+(?:\.\d+)?)\s*\)*$`)
+	inListReg       = regexp.MustCompile(`(?i)^\(*\s*(\w+)\s*\)*\s+in\s*\((.*)\)$`)
+	anyArrayReg     = regexp.MustCompile(`(?i)^\(*\s*(\w+)\s*\)*\s*=\s*any\s*\(\s*\(*\s*array\s*\[(.*)\]\s*\)*\s*\)$`)
+)
+
+// kind returns what the column holds based on its data type
+func (c Column) kind() dataKind {
+	if len(c.EnumValues) > 0 {
+		return stringKind
+	}
+
+	return dataKindMap[c.DataType]
+}
+
+// enumWords splits an enum value into its alphanumeric words so languages can
+// build identifiers from it in their own case, eg. "on-hold" into "on", "hold"
+func enumWords(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// enumConstants returns the identifier of every enum value built by constant,
+// prefixing identifiers starting with a digit and numbering duplicates
+func enumConstants(values []string, constant func(string) string) []string {
+	constants := make([]string, 0, len(values))
+	seen := make(map[string]int)
+
+	for _, value := range values {
+		c := constant(value)
+
+		if c == "" {
+			c = constant("empty")
+		}
+		if c[0] >= '0' && c[0] <= '9' {
+			c = "_" + c
+		}
+
+		if seen[c]++; seen[c] > 1 {
+			c = fmt.Sprintf("%s%d", c, seen[c])
+		}
+
+		constants = append(constants, c)
+	}
+
+	return constants
+}
+
+// camelEnumConstant returns the lowerCamelCase name of an enum value
+func camelEnumConstant(value string) string {
+	words := enumWords(value)
+
+	for i := range words {
+		words[i] = strings.ToLower(words[i])
+
+		if i > 0 {
+			words[i] = strings.ToUpper(words[i][:1]) + words[i][1:]
+		}
+	}
+
+	return strings.Join(words, "")
+}
+
+// screamingEnumConstant returns the SCREAMING_SNAKE_CASE name of an enum value
+func screamingEnumConstant(value string) string {
+	return strings.ToUpper(strings.Join(enumWords(value), "_"))
+}
+
+// pascalEnumConstant returns the PascalCase name of an enum value
+func pascalEnumConstant(value string) string {
+	words := enumWords(value)
+
+	for i := range words {
+		words[i] = strings.ToUpper(words[i][:1]) + strings.ToLower(words[i][1:])
+	}
+
+	return strings.Join(words, "")
+}
+
+// docLines returns comment as /// documentation comments indented by indent, as
+// swift, rust and dart take them, or nothing if comment is empty
+func docLines(comment, indent string) string {
+	comment = strings.TrimSpace(comment)
+
+	if comment == "" {
+		return ""
+	}
+
+	var doc strings.Builder
+
+	for _, line := range strings.Split(comment, "\n") {
+		doc.WriteString(strings.TrimRight(indent+"/// "+strings.TrimSpace(line), " ") + "\n")
+	}
+
+	return doc.String()
+}
+
+// docBlock returns comment as a /** */ documentation block indented by indent,
+// as TSDoc and KDoc take it, or nothing if comment is empty
+func docBlock(comment, indent string) string {
+	comment = strings.TrimSpace(strings.ReplaceAll(comment, "*/", `*\/`))
+
+	if comment == "" {
+		return ""
+	}
+
+	lines := strings.Split(comment, "\n")
+
+	if len(lines) == 1 {
+		return indent + "/** " + lines[0] + " */\n"
+	}
+
+	var doc strings.Builder
+
+	doc.WriteString(indent + "/**\n")
+
+	for _, line := range lines {
+		doc.WriteString(strings.TrimRight(indent+" * "+strings.TrimSpace(line), " ") + "\n")
+	}
+
+	doc.WriteString(indent + " */\n")
+
+	return doc.String()
+}
+
+// LoadSchema introspects every table of schema along with its columns, constraints
+// and foreign keys
+//
+// Check constraints are only loaded when withChecks is set as only validate tags
+// and the schemas generated for the models are built from them
+func LoadSchema(gormDB *gorm.DB, driver DBDriver, schema string, withChecks bool) ([]Table, error) {
+	var err error
+	var tableRows []tableRow
+
+	if driver == PostgresDriver && schema == "" {
+		return nil, ErrMustSetSchema
+	}
+
+	// Servers without a table of check constraints have no checks to load
+	if query := getCheckConstraintTableQuery(driver); withChecks && query != "" {
+		var count int
+
+		if err = gormDB.Raw(query).Scan(&count).Error; err != nil {
+			return nil, fmt.Errorf(packageErr, ErrQueryCheckConstraints, err.Error())
+		}
+
+		withChecks = count > 0
+	}
+
+	if err = gormDB.Raw(
+		getTableNamesQuery(driver, schema),
+	).Scan(&tableRows).Error; err != nil {
+		return nil, fmt.Errorf(packageErr, ErrQueryTableNames, err.Error())
+	}
+
+	tables := make([]Table, 0, len(tableRows))
+
+	for _, row := range tableRows {
+		var cols []tableColumn
+		var fks []ForeignKey
+		var checks []string
+		var enums []enumValue
+
+		if err = gormDB.Raw(
+			getColumnNameQuery(driver, schema, row.TableName),
+		).Scan(&cols).Error; err != nil {
+			return nil, fmt.Errorf(packageErr, ErrQueryColumnNames, err.Error())
+		}
+
+		if err = gormDB.Raw(
+			getForeignKeyQuery(driver, schema, row.TableName),
+		).Scan(&fks).Error; err != nil {
+			return nil, fmt.Errorf(packageErr, ErrQueryForeignKeys, err.Error())
+		}
+
+		if query := getCheckConstraintQuery(driver, schema, row.TableName); withChecks && query != "" {
+			if err = gormDB.Raw(query).Scan(&checks).Error; err != nil {
+				return nil, fmt.Errorf(packageErr, ErrQueryCheckConstraints, err.Error())
+			}
+		}
+
+		if query := getEnumValueQuery(driver, schema, row.TableName); query != "" {
+			if err = gormDB.Raw(query).Scan(&enums).Error; err != nil {
+				return nil, fmt.Errorf(packageErr, ErrQueryEnumValues, err.Error())
+			}
+		}
+
+		table := Table{
+			Name:    row.TableName,
+			Comment: row.Comment,
+		}
+
+		for _, fk := range fks {
+			if fk.ForeignSchema != "" {
+				table.ExternalForeignKeys = append(table.ExternalForeignKeys, fk)
+			} else {
+				table.ForeignKeys = append(table.ForeignKeys, fk)
+			}
+		}
+
+		for _, col := range cols {
+			table.Columns = append(table.Columns, col.toColumn())
+		}
+
+		for _, enum := range enums {
+			if col := table.column(enum.ColumnName); col != nil {
+				col.EnumValues = append(col.EnumValues, enum.EnumValue)
+			}
+		}
+
+		for _, check := range checks {
+			table.applyCheck(check)
+		}
+
+		tables = append(tables, table)
+	}
+
+	return tables, nil
+}
+
+// allForeignKeys returns the foreign keys of the table followed by its external
+// foreign keys
+func (t Table) allForeignKeys() []ForeignKey {
+	fks := make([]ForeignKey, 0, len(t.ForeignKeys)+len(t.ExternalForeignKeys))
+
+	return append(append(fks, t.ForeignKeys...), t.ExternalForeignKeys...)
+}
+
+// column returns a pointer to the column named name, nil if there is none
+func (t *Table) column(name string) *Column {
+	for i := range t.Columns {
+		if t.Columns[i].Name == name {
+			return &t.Columns[i]
+		}
+	}
+
+	return nil
+}
+
+// primaryKey returns the primary key column of the table, nil if the table has
+// none or a composite one
+func (t Table) primaryKey() *Column {
+	var pk *Column
+
+	for i := range t.Columns {
+		if !t.Columns[i].PrimaryKey {
+			continue
+		}
+
+		if pk != nil {
+			return nil
+		}
+
+		pk = &t.Columns[i]
+	}
+
+	return pk
+}
+
+// referencedColumn returns the column foreign keys to table reference when they
+// don't name one, which is its first primary key column or "id" if it has none
+func referencedColumn(table Table) string {
+	for _, col := range table.Columns {
+		if col.PrimaryKey {
+			return col.Name
+		}
+	}
+
+	return "id"
+}
+
+// applyCheck attributes a check constraint definition to the column it restricts
+//
+// Only comparisons of a single column against a number and lists of allowed
+// values are understood, anything else is ignored
+func (t *Table) applyCheck(definition string) {
+	expr := strings.TrimSpace(definition)
+
+	if strings.HasPrefix(strings.ToUpper(expr), "CHECK") {
+		expr = strings.TrimSpace(expr[len("CHECK"):])
+	}
+
+	expr = castReg.ReplaceAllString(expr, "")
+	expr = introducerReg.ReplaceAllString(expr, "'")
+	expr = strings.NewReplacer("`", "", `"`, "").Replace(expr)
+
+	for strings.HasPrefix(expr, "(") && strings.HasSuffix(expr, ")") && balanced(expr[1:len(expr)-1]) {
+		expr = strings.TrimSpace(expr[1 : len(expr)-1])
+	}
+
+	if m := comparisonReg.FindStringSubmatch(expr); m != nil {
+		if col := t.column(m[1]); col != nil {
+			col.Checks = append(col.Checks, Check{Operator: m[2], Value: m[3]})
+		}
+
+		return
+	}
+
+	var m []string
+
+	if m = inListReg.FindStringSubmatch(expr); m == nil {
+		m = anyArrayReg.FindStringSubmatch(expr)
+	}
+
+	if m == nil {
+		return
+	}
+
+	if col := t.column(m[1]); col != nil && len(col.EnumValues) == 0 {
+		col.EnumValues = splitQuotedList(m[2])
+	}
+}
+
+type tableRow struct {
+	TableName string
+	Comment   string
+}
+
+type tableColumn struct {
+	ColumnName       string
+	DataType         string
+	ColumnType       string
+	Comment          string
+	Nullable         bool
+	HasDefault       bool
+	PrimaryKey       bool
+	MaxLength        *int
+	NumericPrecision *int
+	NumericScale     *int
+}
+
+type enumValue struct {
+	ColumnName string
+	EnumValue  string
+}
+
+// toColumn converts the raw query result into a Column
+//
+// Lengths and precisions not reported by the driver are parsed from the column
+// type, which is how sqlite and mysql enums declare them
+func (tc tableColumn) toColumn() Column {
+	col := Column{
+		Name:       tc.ColumnName,
+		DataType:   normalizeDataType(tc.DataType),
+		Comment:    tc.Comment,
+		Nullable:   tc.Nullable,
+		HasDefault: tc.HasDefault,
+		PrimaryKey: tc.PrimaryKey,
+	}
+
+	columnType := tc.ColumnType
+
+	if columnType == "" {
+		columnType = tc.DataType
+	}
+
+	// mysql stores booleans as tinyint(1), which gorm/gen generates as bool
+	if strings.HasPrefix(strings.ToLower(strings.TrimSpace(tc.ColumnType)), "tinyint(1)") {
+		col.DataType = "boolean"
+	}
+
+	if m := enumTypeReg.FindStringSubmatch(strings.TrimSpace(columnType)); m != nil {
+		col.EnumValues = splitQuotedList(m[1])
+	}
+
+	var modifiers []int
+
+	if m := typeModifierReg.FindStringSubmatch(columnType); m != nil {
+		for _, v := range m[1:] {
+			if n, err := strconv.Atoi(v); err == nil {
+				modifiers = append(modifiers, n)
+			}
+		}
+	}
+
+	switch dataKindMap[col.DataType] {
+	case stringKind:
+		if tc.MaxLength != nil {
+			col.MaxLength = *tc.MaxLength
+		} else if len(modifiers) > 0 {
+			col.MaxLength = modifiers[0]
+		}
+	case decimalKind:
+		if tc.NumericPrecision != nil {
+			col.NumericPrecision = *tc.NumericPrecision
+		} else if len(modifiers) > 0 {
+			col.NumericPrecision = modifiers[0]
+		}
+
+		if tc.NumericScale != nil {
+			col.NumericScale = *tc.NumericScale
+		} else if len(modifiers) > 1 {
+			col.NumericScale = modifiers[1]
+		}
+	}
+
+	return col
+}
+
+// balanced returns whether every parenthesis in expr is closed in order
+func balanced(expr string) bool {
+	depth := 0
+
+	for _, r := range expr {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+
+		if depth < 0 {
+			return false
+		}
+	}
+
+	return depth == 0
+}
+
+// splitQuotedList splits a comma separated list of quoted values like 'a','b'
+func splitQuotedList(list string) []string {
+	var values []string
+
+	for _, v := range strings.Split(list, ",") {
+		v = strings.TrimSpace(v)
+		v = strings.TrimPrefix(strings.TrimSuffix(v, "'"), "'")
+
+		if v != "" {
+			values = append(values, strings.ReplaceAll(v, "''", "'"))
+		}
+	}
+
+	return values
+}
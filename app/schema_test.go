@@ -0,0 +1,304 @@
+package app
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestLoadSchema(t *testing.T) {
+	var err error
+	var gormDB *gorm.DB
+	var db *sql.DB
+	var mockDB sqlmock.Sqlmock
+
+	initNewMockDB := func() {
+		var innerErr error
+
+		if db != nil {
+			db.Close()
+		}
+
+		db, mockDB, innerErr = sqlmock.New(sqlmock.QueryMatcherOption(sqlAnyMatcher))
+
+		if innerErr != nil {
+			t.Fatalf(innerErr.Error())
+		}
+
+		if gormDB, innerErr = gorm.Open(postgres.New(postgres.Config{Conn: db})); innerErr != nil {
+			t.Fatalf(innerErr.Error())
+		}
+	}
+
+	columnNames := []string{
+		"column_name", "data_type", "nullable", "has_default", "primary_key", "max_length", "numeric_precision", "numeric_scale",
+		"comment",
+	}
+
+	initNewMockDB()
+
+	if _, err = LoadSchema(gormDB, PostgresDriver, "", true); !errors.Is(err, ErrMustSetSchema) {
+		t.Fatalf("should have error %v; got %v\n", ErrMustSetSchema, err)
+	}
+
+	sqlErr := errors.New("error")
+
+	mockDB.ExpectQuery("select table names").WillReturnError(sqlErr)
+
+	if _, err = LoadSchema(gormDB, PostgresDriver, "public", true); !errors.Is(err, ErrQueryTableNames) {
+		t.Fatalf("should have error %v; got %v\n", ErrQueryTableNames, err)
+	}
+
+	initNewMockDB()
+	mockDB.ExpectQuery("select table names").WillReturnRows(mockDB.NewRows([]string{"table_name", "comment"}).AddRow("phone", ""))
+	mockDB.ExpectQuery("select columns").WillReturnError(sqlErr)
+
+	if _, err = LoadSchema(gormDB, PostgresDriver, "public", true); !errors.Is(err, ErrQueryColumnNames) {
+		t.Fatalf("should have error %v; got %v\n", ErrQueryColumnNames, err)
+	}
+
+	initNewMockDB()
+	mockDB.ExpectQuery("select table names").WillReturnRows(mockDB.NewRows([]string{"table_name", "comment"}).AddRow("phone", ""))
+	mockDB.ExpectQuery("select columns").WillReturnRows(mockDB.NewRows(columnNames))
+	mockDB.ExpectQuery("select foreign keys").WillReturnError(sqlErr)
+
+	if _, err = LoadSchema(gormDB, PostgresDriver, "public", true); !errors.Is(err, ErrQueryForeignKeys) {
+		t.Fatalf("should have error %v; got %v\n", ErrQueryForeignKeys, err)
+	}
+
+	initNewMockDB()
+	mockDB.ExpectQuery("select table names").WillReturnRows(mockDB.NewRows([]string{"table_name", "comment"}).AddRow("phone", ""))
+	mockDB.ExpectQuery("select columns").WillReturnRows(mockDB.NewRows(columnNames))
+	mockDB.ExpectQuery("select foreign keys").WillReturnRows(mockDB.NewRows([]string{"column_name", "foreign_table_name"}))
+	mockDB.ExpectQuery("select check constraints").WillReturnError(sqlErr)
+
+	if _, err = LoadSchema(gormDB, PostgresDriver, "public", true); !errors.Is(err, ErrQueryCheckConstraints) {
+		t.Fatalf("should have error %v; got %v\n", ErrQueryCheckConstraints, err)
+	}
+
+	initNewMockDB()
+	mockDB.ExpectQuery("select table names").WillReturnRows(mockDB.NewRows([]string{"table_name", "comment"}).AddRow("phone", ""))
+	mockDB.ExpectQuery("select columns").WillReturnRows(mockDB.NewRows(columnNames))
+	mockDB.ExpectQuery("select foreign keys").WillReturnRows(mockDB.NewRows([]string{"column_name", "foreign_table_name"}))
+	mockDB.ExpectQuery("select check constraints").WillReturnRows(mockDB.NewRows([]string{"pg_get_constraintdef"}))
+	mockDB.ExpectQuery("select enum values").WillReturnError(sqlErr)
+
+	if _, err = LoadSchema(gormDB, PostgresDriver, "public", true); !errors.Is(err, ErrQueryEnumValues) {
+		t.Fatalf("should have error %v; got %v\n", ErrQueryEnumValues, err)
+	}
+
+	initNewMockDB()
+	mockDB.ExpectQuery("select table names").WillReturnRows(mockDB.NewRows([]string{"table_name", "comment"}).AddRow("product", "Product sold in the store"))
+	mockDB.ExpectQuery("select columns").WillReturnRows(
+		mockDB.NewRows(columnNames).
+			AddRow("id", "int8", false, true, true, nil, 64, 0, "").
+			AddRow("name", "varchar", false, false, false, 255, nil, nil, "Display name").
+			AddRow("price", "numeric", true, false, false, nil, 10, 2, "").
+			AddRow("quantity", "int4", false, false, false, nil, 32, 0, "").
+			AddRow("size", "text", false, false, false, nil, nil, nil, "").
+			AddRow("status", "product_status", false, true, false, nil, nil, nil, "").
+			AddRow("category_id", "int8", false, false, false, nil, 64, 0, ""),
+	)
+	mockDB.ExpectQuery("select foreign keys").WillReturnRows(
+		mockDB.NewRows([]string{"column_name", "foreign_schema", "foreign_table_name", "foreign_column_name"}).
+			AddRow("category_id", "", "category", "id").
+			AddRow("category_id", "catalog", "category", "id"),
+	)
+	mockDB.ExpectQuery("select check constraints").WillReturnRows(
+		mockDB.NewRows([]string{"pg_get_constraintdef"}).
+			AddRow("CHECK ((quantity > 0))").
+			AddRow("CHECK ((size = ANY (ARRAY['small'::text, 'extra large'::text])))").
+			AddRow("CHECK ((length(name) > 2))"),
+	)
+	mockDB.ExpectQuery("select enum values").WillReturnRows(
+		mockDB.NewRows([]string{"column_name", "enum_value"}).
+			AddRow("status", "active").
+			AddRow("status", "archived"),
+	)
+
+	tables, err := LoadSchema(gormDB, PostgresDriver, "public", true)
+
+	if err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	expected := []Table{
+		{
+			Name:    "product",
+			Comment: "Product sold in the store",
+			Columns: []Column{
+				{Name: "id", DataType: "int8", HasDefault: true, PrimaryKey: true},
+				{Name: "name", DataType: "varchar", Comment: "Display name", MaxLength: 255},
+				{Name: "price", DataType: "numeric", Nullable: true, NumericPrecision: 10, NumericScale: 2},
+				{Name: "quantity", DataType: "int4", Checks: []Check{{Operator: ">", Value: "0"}}},
+				{Name: "size", DataType: "text", EnumValues: []string{"small", "extra large"}},
+				{Name: "status", DataType: "product_status", HasDefault: true, EnumValues: []string{"active", "archived"}},
+				{Name: "category_id", DataType: "int8"},
+			},
+			ForeignKeys: []ForeignKey{
+				{ColumnName: "category_id", ForeignTableName: "category", ForeignColumnName: "id"},
+			},
+			ExternalForeignKeys: []ForeignKey{
+				{ColumnName: "category_id", ForeignSchema: "catalog", ForeignTableName: "category", ForeignColumnName: "id"},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(tables, expected) {
+		t.Fatalf("expected tables %+v; got %+v\n", expected, tables)
+	}
+}
+
+func TestLoadSchemaChecks(t *testing.T) {
+	columnNames := []string{
+		"column_name", "data_type", "nullable", "has_default", "primary_key", "max_length", "numeric_precision", "numeric_scale",
+		"comment",
+	}
+
+	newMockDB := func(driver DBDriver) (*gorm.DB, sqlmock.Sqlmock) {
+		db, mockDB, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlAnyMatcher))
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		t.Cleanup(func() { db.Close() })
+
+		dialector := postgres.New(postgres.Config{Conn: db})
+
+		if driver == MysqlDriver {
+			dialector = mysql.New(mysql.Config{Conn: db, SkipInitializeWithVersion: true})
+		}
+
+		gormDB, err := gorm.Open(dialector)
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		return gormDB, mockDB
+	}
+
+	expectTable := func(mockDB sqlmock.Sqlmock) {
+		mockDB.ExpectQuery("select table names").WillReturnRows(mockDB.NewRows([]string{"table_name", "comment"}).AddRow("product", ""))
+		mockDB.ExpectQuery("select columns").WillReturnRows(
+			mockDB.NewRows(columnNames).AddRow("quantity", "int", false, false, false, nil, 10, 0, ""),
+		)
+		mockDB.ExpectQuery("select foreign keys").WillReturnRows(mockDB.NewRows([]string{"column_name", "foreign_table_name"}))
+	}
+
+	// Check constraints aren't queried unless they are needed
+	gormDB, mockDB := newMockDB(PostgresDriver)
+	expectTable(mockDB)
+	mockDB.ExpectQuery("select enum values").WillReturnRows(mockDB.NewRows([]string{"column_name", "enum_value"}))
+
+	if _, err := LoadSchema(gormDB, PostgresDriver, "public", false); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+	if err := mockDB.ExpectationsWereMet(); err != nil {
+		t.Fatalf("should not query check constraints; %s\n", err.Error())
+	}
+
+	// mysql servers before 8.0.16 have no table of check constraints
+	gormDB, mockDB = newMockDB(MysqlDriver)
+	mockDB.ExpectQuery("count check constraint tables").WillReturnRows(mockDB.NewRows([]string{"count"}).AddRow(0))
+	expectTable(mockDB)
+
+	tables, err := LoadSchema(gormDB, MysqlDriver, "", true)
+
+	if err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+	if err = mockDB.ExpectationsWereMet(); err != nil {
+		t.Fatalf("should not query check constraints; %s\n", err.Error())
+	}
+	if len(tables) != 1 || tables[0].Columns[0].Checks != nil {
+		t.Fatalf("expected a table without checks; got %+v\n", tables)
+	}
+
+	gormDB, mockDB = newMockDB(MysqlDriver)
+	mockDB.ExpectQuery("count check constraint tables").WillReturnRows(mockDB.NewRows([]string{"count"}).AddRow(1))
+	expectTable(mockDB)
+	mockDB.ExpectQuery("select check constraints").WillReturnRows(
+		mockDB.NewRows([]string{"check_clause"}).AddRow("(`quantity` > 0)"),
+	)
+
+	if tables, err = LoadSchema(gormDB, MysqlDriver, "", true); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	expected := []Check{{Operator: ">", Value: "0"}}
+
+	if !reflect.DeepEqual(tables[0].Columns[0].Checks, expected) {
+		t.Fatalf("expected checks %+v; got %+v\n", expected, tables[0].Columns[0].Checks)
+	}
+}
+
+func TestTableColumnToColumn(t *testing.T) {
+	tests := []struct {
+		tableColumn tableColumn
+		expected    Column
+	}{
+		{
+			tableColumn{ColumnName: "email", DataType: "VARCHAR(255)"},
+			Column{Name: "email", DataType: "varchar", MaxLength: 255},
+		},
+		{
+			tableColumn{ColumnName: "price", DataType: "decimal", ColumnType: "decimal(8,2)"},
+			Column{Name: "price", DataType: "decimal", NumericPrecision: 8, NumericScale: 2},
+		},
+		{
+			tableColumn{ColumnName: "size", DataType: "enum", ColumnType: "enum('small','it''s large')"},
+			Column{Name: "size", DataType: "enum", EnumValues: []string{"small", "it's large"}},
+		},
+		{
+			tableColumn{ColumnName: "active", DataType: "tinyint", ColumnType: "tinyint(1)"},
+			Column{Name: "active", DataType: "boolean"},
+		},
+	}
+
+	for _, test := range tests {
+		if col := test.tableColumn.toColumn(); !reflect.DeepEqual(col, test.expected) {
+			t.Fatalf("expected column %+v; got %+v\n", test.expected, col)
+		}
+	}
+}
+
+func TestTableApplyCheck(t *testing.T) {
+	table := Table{
+		Columns: []Column{
+			{Name: "qty", DataType: "int"},
+			{Name: "size", DataType: "varchar"},
+		},
+	}
+
+	table.applyCheck("(`qty` >= 1)")
+	table.applyCheck("(`size` in (_utf8mb4'small',_utf8mb4'large'))")
+	table.applyCheck("CHECK ((qty > 0) AND (qty < 10))")
+
+	expected := []Column{
+		{Name: "qty", DataType: "int", Checks: []Check{{Operator: ">=", Value: "1"}}},
+		{Name: "size", DataType: "varchar", EnumValues: []string{"small", "large"}},
+	}
+
+	if !reflect.DeepEqual(table.Columns, expected) {
+		t.Fatalf("expected columns %+v; got %+v\n", expected, table.Columns)
+	}
+}
+
+func TestEnumConstants(t *testing.T) {
+	constants := enumConstants([]string{"a b", "a-b", "", "9"}, screamingEnumConstant)
+	expected := []string{"A_B", "A_B2", "EMPTY", "_9"}
+
+	for i := range expected {
+		if constants[i] != expected[i] {
+			t.Fatalf("expected constants %v; got %v\n", expected, constants)
+		}
+	}
+}
@@ -0,0 +1,373 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kenshaw/snaker"
+	"github.com/pkg/errors"
+)
+
+// swiftShapeTypes is the swift type of every json shape
+//
+// Bytes decode as Data since JSONDecoder expects base64 for it by default
+var swiftShapeTypes = map[jsonShape]string{
+	jsonAny:    swiftJSONValue,
+	jsonString: "String",
+	jsonInt:    "Int",
+	jsonBigint: "Int64",
+	jsonFloat:  "Double",
+	jsonBool:   "Bool",
+	jsonTime:   "Date",
+	jsonBytes:  "Data",
+}
+
+const swiftJSONValue = "JSONValue"
+
+// swiftJSONValueDeclaration is the Codable enum free form json is decoded into
+// as Foundation has no type for it
+const swiftJSONValueDeclaration = `enum JSONValue: Codable {
+    case null
+    case bool(Bool)
+    case number(Double)
+    case string(String)
+    case array([JSONValue])
+    case object([String: JSONValue])
+
+    init(from decoder: Decoder) throws {
+        let container = try decoder.singleValueContainer()
+
+        if container.decodeNil() {
+            self = .null
+        } else if let value = try? container.decode(Bool.self) {
+            self = .bool(value)
+        } else if let value = try? container.decode(Double.self) {
+            self = .number(value)
+        } else if let value = try? container.decode(String.self) {
+            self = .string(value)
+        } else if let value = try? container.decode([JSONValue].self) {
+            self = .array(value)
+        } else {
+            self = .object(try container.decode([String: JSONValue].self))
+        }
+    }
+
+    func encode(to encoder: Encoder) throws {
+        var container = encoder.singleValueContainer()
+
+        switch self {
+        case .null:
+            try container.encodeNil()
+        case .bool(let value):
+            try container.encode(value)
+        case .number(let value):
+            try container.encode(value)
+        case .string(let value):
+            try container.encode(value)
+        case .array(let value):
+            try container.encode(value)
+        case .object(let value):
+            try container.encode(value)
+        }
+    }
+}
+`
+
+const swiftIndirect = "Indirect"
+
+// swiftIndirectDeclaration is the Codable class relations leading back to the
+// struct they belong to are boxed in, as structs can't contain themselves, not
+// even optionally.  It encodes as the value it holds
+const swiftIndirectDeclaration = `final class Indirect<Wrapped: Codable>: Codable {
+    let value: Wrapped
+
+    init(_ value: Wrapped) {
+        self.value = value
+    }
+
+    init(from decoder: Decoder) throws {
+        value = try Wrapped(from: decoder)
+    }
+
+    func encode(to encoder: Encoder) throws {
+        try value.encode(to: encoder)
+    }
+}
+`
+
+// swiftDeclarations are the declarations of the helper types structs may use
+// keyed by type name
+var swiftDeclarations = map[string]string{
+	swiftJSONValue: swiftJSONValueDeclaration,
+	swiftIndirect:  swiftIndirectDeclaration,
+}
+
+// swiftKeywords are the keywords of swift which have to be escaped with
+// backticks when used as identifiers
+var swiftKeywords = map[string]bool{
+	"associatedtype": true, "break": true, "case": true, "catch": true, "class": true,
+	"continue": true, "default": true, "defer": true, "deinit": true, "do": true,
+	"else": true, "enum": true, "extension": true, "fallthrough": true, "false": true,
+	"fileprivate": true, "for": true, "func": true, "guard": true, "if": true,
+	"import": true, "in": true, "init": true, "inout": true, "internal": true,
+	"is": true, "let": true, "nil": true, "open": true, "operator": true,
+	"private": true, "protocol": true, "public": true, "repeat": true, "return": true,
+	"self": true, "static": true, "struct": true, "subscript": true, "super": true,
+	"switch": true, "throw": true, "throws": true, "true": true, "try": true,
+	"typealias": true, "var": true, "where": true, "while": true,
+}
+
+// GenerateSwiftModels generates a Codable struct for every table in a single
+// file named swiftFile within swiftDir
+//
+// When swiftFile is empty every table is generated into its own file named after
+// its struct instead.  Timestamps are decoded as Date so the JSONDecoder needs a
+// date decoding strategy matching the go models, eg. ISO 8601 with fractional
+// seconds
+func GenerateSwiftModels(tables []Table, swiftDir, swiftFile string, cfg GenerateConfig) error {
+	if swiftDir == "" {
+		return errors.WithStack(fmt.Errorf("model-gen: swiftDir parameter can't be empty"))
+	}
+
+	if err := os.MkdirAll(swiftDir, os.ModePerm); err != nil {
+		return errors.WithStack(err)
+	}
+
+	indirect := swiftIndirectRelations(tables)
+
+	// Helper types are declared once for every struct using them
+	var declarations []string
+
+	if swiftUsesJSONValue(tables, cfg) {
+		declarations = append(declarations, swiftJSONValue)
+	}
+	if len(indirect) > 0 {
+		declarations = append(declarations, swiftIndirect)
+	}
+
+	if swiftFile != "" {
+		if !strings.HasSuffix(swiftFile, ".swift") {
+			swiftFile += ".swift"
+		}
+
+		return writeSwiftFile(filepath.Join(swiftDir, swiftFile), tables, indirect, declarations, cfg)
+	}
+
+	for _, table := range tables {
+		fileName := filepath.Join(swiftDir, cfg.Model.structName(table.Name)+".swift")
+
+		if err := writeSwiftFile(fileName, []Table{table}, indirect, nil, cfg); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range declarations {
+		fileName := filepath.Join(swiftDir, name+".swift")
+		content := "import Foundation\n\n" + swiftDeclarations[name]
+
+		if err := os.WriteFile(fileName, []byte(content), 0644); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	return nil
+}
+
+// writeSwiftFile writes the structs of tables into fileName, followed by the
+// helper types named by declarations
+func writeSwiftFile(fileName string, tables []Table, indirect map[string]map[string]bool, declarations []string, cfg GenerateConfig) error {
+	var b strings.Builder
+
+	b.WriteString("import Foundation\n")
+
+	for _, table := range tables {
+		b.WriteString("\n")
+		b.WriteString(swiftStruct(table, indirect[table.Name], cfg))
+	}
+
+	for _, name := range declarations {
+		b.WriteString("\n" + swiftDeclarations[name])
+	}
+
+	return errors.WithStack(os.WriteFile(fileName, []byte(b.String()), 0644))
+}
+
+// swiftUsesJSONValue returns whether any field of tables is typed as JSONValue
+func swiftUsesJSONValue(tables []Table, cfg GenerateConfig) bool {
+	for _, table := range tables {
+		for _, col := range table.Columns {
+			if _, _, ok := cfg.Model.jsonField(table.Name, col); ok && swiftType(table, col, cfg) == swiftJSONValue {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// swiftIndirectRelations returns the foreign key columns of every table whose
+// relation leads back to the table, keyed by table name
+//
+// Structs can't contain themselves, not even optionally, so these relations are
+// boxed in Indirect
+func swiftIndirectRelations(tables []Table) map[string]map[string]bool {
+	references := make(map[string][]string)
+
+	for _, table := range tables {
+		for _, fk := range table.ForeignKeys {
+			references[table.Name] = append(references[table.Name], fk.ForeignTableName)
+		}
+	}
+
+	var reaches func(from, to string, visited map[string]bool) bool
+
+	reaches = func(from, to string, visited map[string]bool) bool {
+		if from == to {
+			return true
+		}
+		if visited[from] {
+			return false
+		}
+
+		visited[from] = true
+
+		for _, next := range references[from] {
+			if reaches(next, to, visited) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	indirect := make(map[string]map[string]bool)
+
+	for _, table := range tables {
+		for _, fk := range table.ForeignKeys {
+			if !reaches(fk.ForeignTableName, table.Name, make(map[string]bool)) {
+				continue
+			}
+
+			if indirect[table.Name] == nil {
+				indirect[table.Name] = make(map[string]bool)
+			}
+
+			indirect[table.Name][fk.ColumnName] = true
+		}
+	}
+
+	return indirect
+}
+
+// swiftStruct returns the Codable struct of table followed by the enums of its
+// enum columns
+//
+// Relations of the foreign key columns set in indirect are boxed in Indirect
+func swiftStruct(table Table, indirect map[string]bool, cfg GenerateConfig) string {
+	var b, keys, enums strings.Builder
+
+	b.WriteString(docLines(table.Comment, ""))
+	b.WriteString(fmt.Sprintf("struct %s: Codable {\n", cfg.Model.structName(table.Name)))
+
+	for _, col := range table.Columns {
+		jsonName, omitEmpty, ok := cfg.Model.jsonField(table.Name, col)
+
+		// Fields hidden from json are never part of the api contract
+		if !ok {
+			continue
+		}
+
+		name := swiftIdentifier(snaker.ForceLowerCamelIdentifier(col.Name))
+		propertyType := swiftType(table, col, cfg)
+
+		if col.Nullable || omitEmpty {
+			propertyType += "?"
+		}
+
+		b.WriteString(docLines(col.Comment, "    "))
+		b.WriteString(fmt.Sprintf("    let %s: %s\n", name, propertyType))
+		keys.WriteString("    " + swiftCase(name, jsonName))
+
+		if cfg.Model.enumColumn(table, col) {
+			enums.WriteString(swiftEnum(cfg.Model.enumName(table.Name, col), col.EnumValues))
+		}
+	}
+
+	// Relations are only set when they are loaded so they are always optional
+	for _, fk := range table.ForeignKeys {
+		jsonName, _ := cfg.Model.relationJSONField(table, fk)
+		name := swiftIdentifier(snaker.ForceLowerCamelIdentifier(cfg.Model.relationName(table, fk)))
+		propertyType := cfg.Model.structName(fk.ForeignTableName)
+
+		if indirect[fk.ColumnName] {
+			propertyType = fmt.Sprintf("%s<%s>", swiftIndirect, propertyType)
+		}
+
+		b.WriteString(fmt.Sprintf("    let %s: %s?\n", name, propertyType))
+		keys.WriteString("    " + swiftCase(name, jsonName))
+	}
+
+	b.WriteString("\n    enum CodingKeys: String, CodingKey {\n")
+	b.WriteString(keys.String())
+	b.WriteString("    }\n")
+	b.WriteString("}\n")
+	b.WriteString(enums.String())
+
+	return b.String()
+}
+
+// swiftEnum returns a String backed enum named name with a case per value
+func swiftEnum(name string, values []string) string {
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("\nenum %s: String, Codable {\n", name))
+
+	for i, constant := range enumConstants(values, camelEnumConstant) {
+		b.WriteString(swiftCase(swiftIdentifier(constant), values[i]))
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// swiftCase returns an enum case named name with rawValue as its raw value, left
+// out when it matches the name
+func swiftCase(name, rawValue string) string {
+	if strings.Trim(name, "`") == rawValue {
+		return fmt.Sprintf("    case %s\n", name)
+	}
+
+	return fmt.Sprintf("    case %s = %s\n", name, swiftString(rawValue))
+}
+
+// swiftType returns the swift type of col of table without optionality
+func swiftType(table Table, col Column, cfg GenerateConfig) string {
+	if cfg.Model.enumColumn(table, col) {
+		return cfg.Model.enumName(table.Name, col)
+	}
+
+	shape := cfg.Model.jsonShape(table, col)
+
+	if shape == jsonString && col.kind() == uuidKind {
+		return "UUID"
+	}
+
+	return swiftShapeTypes[shape]
+}
+
+// swiftIdentifier escapes name with backticks if it is a keyword
+func swiftIdentifier(name string) string {
+	if swiftKeywords[name] {
+		return "`" + name + "`"
+	}
+
+	return name
+}
+
+// swiftString returns value as a swift string literal
+func swiftString(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
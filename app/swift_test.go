@@ -0,0 +1,232 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateSwiftModels(t *testing.T) {
+	var err error
+
+	swiftDir := filepath.Join(t.TempDir(), "swift")
+
+	if err = GenerateSwiftModels(nil, "", "", GenerateConfig{}); err == nil {
+		t.Fatalf("should have error\n")
+	}
+
+	tables := []Table{
+		{
+			Name:    "user_profile",
+			Columns: []Column{{Name: "id", DataType: "int8"}},
+		},
+		{
+			Name: "orders",
+			Columns: []Column{
+				{Name: "id", DataType: "uuid"},
+				{Name: "details", DataType: "jsonb"},
+			},
+		},
+		{
+			Name:        "employee",
+			Columns:     []Column{{Name: "manager_id", DataType: "int8", Nullable: true}},
+			ForeignKeys: []ForeignKey{{ColumnName: "manager_id", ForeignTableName: "employee"}},
+		},
+	}
+
+	if err = GenerateSwiftModels(tables, swiftDir, "Models", GenerateConfig{}); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	content, err := os.ReadFile(filepath.Join(swiftDir, "Models.swift"))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if !strings.HasPrefix(string(content), "import Foundation\n\nstruct UserProfile: Codable {\n") {
+		t.Fatalf("expected swift output to start with the import and first struct; got:\n%s\n", string(content))
+	}
+
+	if !strings.HasSuffix(string(content), "\n"+swiftIndirectDeclaration) {
+		t.Fatalf("expected Indirect declaration for the self relation; got:\n%s\n", string(content))
+	}
+
+	if err = GenerateSwiftModels(tables, swiftDir, "", GenerateConfig{}); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	for _, name := range []string{"UserProfile.swift", "Order.swift", "Employee.swift", "Indirect.swift"} {
+		if _, err = os.Stat(filepath.Join(swiftDir, name)); err != nil {
+			t.Fatalf("expected file %s; %s\n", name, err.Error())
+		}
+	}
+
+	// Free form json is decoded into a JSONValue declared once for every file
+	cfg := GenerateConfig{
+		Model: ModelConfig{
+			Tables: map[string]TableConfig{
+				"orders": {Columns: map[string]ColumnConfig{"details": {GoType: "datatypes.JSON"}}},
+			},
+		},
+	}
+
+	if err = GenerateSwiftModels(tables, swiftDir, "", cfg); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	if content, err = os.ReadFile(filepath.Join(swiftDir, "JSONValue.swift")); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if !strings.Contains(string(content), "enum JSONValue: Codable {") {
+		t.Fatalf("expected JSONValue declaration; got:\n%s\n", string(content))
+	}
+}
+
+func TestSwiftStruct(t *testing.T) {
+	snakeCase := GenerateConfig{
+		Model: ModelConfig{Tags: []TagGenerator{{Name: "json", Case: SnakeCase}}},
+	}
+
+	tests := []struct {
+		name     string
+		table    Table
+		cfg      GenerateConfig
+		expected string
+	}{
+		{
+			name: "columns",
+			table: Table{
+				Name:    "user_profile",
+				Comment: "Person using the app",
+				Columns: []Column{
+					{Name: "id", DataType: "uuid"},
+					{Name: "name", DataType: "varchar", Nullable: true},
+					{Name: "default", DataType: "bool"},
+					{Name: "created_at", DataType: "timestamptz", Comment: "Time of sign up"},
+				},
+			},
+			cfg: snakeCase,
+			expected: "/// Person using the app\n" +
+				"struct UserProfile: Codable {\n" +
+				"    let id: UUID\n" +
+				"    let name: String?\n" +
+				"    let `default`: Bool\n" +
+				"    /// Time of sign up\n" +
+				"    let createdAt: Date\n\n" +
+				"    enum CodingKeys: String, CodingKey {\n" +
+				"        case id\n" +
+				"        case name\n" +
+				"        case `default`\n" +
+				"        case createdAt = \"created_at\"\n" +
+				"    }\n" +
+				"}\n",
+		},
+		{
+			name: "enum",
+			table: Table{
+				Name: "user_profile",
+				Columns: []Column{
+					{Name: "status", DataType: "user_status", EnumValues: []string{"active", "on-hold"}},
+				},
+			},
+			expected: "struct UserProfile: Codable {\n" +
+				"    let status: UserProfileStatus\n\n" +
+				"    enum CodingKeys: String, CodingKey {\n" +
+				"        case status\n" +
+				"    }\n" +
+				"}\n\n" +
+				"enum UserProfileStatus: String, Codable {\n" +
+				"    case active\n" +
+				"    case onHold = \"on-hold\"\n" +
+				"}\n",
+		},
+		{
+			name: "plural table",
+			table: Table{
+				Name:    "orders",
+				Columns: []Column{{Name: "id", DataType: "int8"}},
+			},
+			expected: "struct Order: Codable {\n" +
+				"    let id: Int64\n\n" +
+				"    enum CodingKeys: String, CodingKey {\n" +
+				"        case id\n" +
+				"    }\n" +
+				"}\n",
+		},
+		{
+			name: "self relation",
+			table: Table{
+				Name:        "employee",
+				Columns:     []Column{{Name: "manager_id", DataType: "int8", Nullable: true}},
+				ForeignKeys: []ForeignKey{{ColumnName: "manager_id", ForeignTableName: "employee"}},
+			},
+			expected: "struct Employee: Codable {\n" +
+				"    let managerID: Int64?\n" +
+				"    let manager: Indirect<Employee>?\n\n" +
+				"    enum CodingKeys: String, CodingKey {\n" +
+				"        case managerID\n" +
+				"        case manager\n" +
+				"    }\n" +
+				"}\n",
+		},
+		{
+			name: "relation without id suffix",
+			table: Table{
+				Name:        "orders",
+				Columns:     []Column{{Name: "buyer", DataType: "int8"}},
+				ForeignKeys: []ForeignKey{{ColumnName: "buyer", ForeignTableName: "user_profile"}},
+			},
+			cfg: snakeCase,
+			expected: "struct Order: Codable {\n" +
+				"    let buyer: Int64\n" +
+				"    let buyerUserProfile: UserProfile?\n\n" +
+				"    enum CodingKeys: String, CodingKey {\n" +
+				"        case buyer\n" +
+				"        case buyerUserProfile = \"buyer_user_profile\"\n" +
+				"    }\n" +
+				"}\n",
+		},
+	}
+
+	for _, test := range tests {
+		if s := swiftStruct(test.table, swiftIndirectRelations([]Table{test.table})[test.table.Name], test.cfg); s != test.expected {
+			t.Fatalf("%s: expected swift struct:\n%s\ngot:\n%s\n", test.name, test.expected, s)
+		}
+	}
+}
+
+func TestSwiftIndirectRelations(t *testing.T) {
+	tables := []Table{
+		{
+			Name:        "employee",
+			ForeignKeys: []ForeignKey{{ColumnName: "manager_id", ForeignTableName: "employee"}},
+		},
+		{
+			Name:        "team",
+			ForeignKeys: []ForeignKey{{ColumnName: "lead_id", ForeignTableName: "member"}},
+		},
+		{
+			Name:        "member",
+			ForeignKeys: []ForeignKey{{ColumnName: "team_id", ForeignTableName: "team"}},
+		},
+		{
+			Name:        "orders",
+			ForeignKeys: []ForeignKey{{ColumnName: "buyer", ForeignTableName: "employee"}},
+		},
+	}
+
+	indirect := swiftIndirectRelations(tables)
+
+	if !indirect["employee"]["manager_id"] {
+		t.Fatalf("expected self relation to be indirect; got %v\n", indirect)
+	}
+	if !indirect["team"]["lead_id"] || !indirect["member"]["team_id"] {
+		t.Fatalf("expected relations of a cycle to be indirect; got %v\n", indirect)
+	}
+	if len(indirect["orders"]) != 0 {
+		t.Fatalf("expected relation of orders to be held directly; got %v\n", indirect["orders"])
+	}
+}
@@ -0,0 +1,230 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kenshaw/snaker"
+	"github.com/pkg/errors"
+)
+
+// NamingCase is the case a column name is converted to when used as a tag value
+type NamingCase string
+
+// OmitEmptyPolicy decides which fields get ",omitempty" appended to their tag
+type OmitEmptyPolicy string
+
+var (
+	SnakeCase  NamingCase = "snake"
+	CamelCase  NamingCase = "camel"
+	PascalCase NamingCase = "pascal"
+	KebabCase  NamingCase = "kebab"
+	AsIsCase   NamingCase = "as-is"
+)
+
+var (
+	OmitEmptyNever    OmitEmptyPolicy = "never"
+	OmitEmptyAlways   OmitEmptyPolicy = "always"
+	OmitEmptyNullable OmitEmptyPolicy = "nullable"
+)
+
+var (
+	ErrInvalidTagGenerator = errors.New("model-gen: invalid tag generator")
+)
+
+var namingCaseMap = map[NamingCase]bool{
+	SnakeCase:  true,
+	CamelCase:  true,
+	PascalCase: true,
+	KebabCase:  true,
+	AsIsCase:   true,
+}
+
+var omitEmptyPolicyMap = map[OmitEmptyPolicy]bool{
+	OmitEmptyNever:    true,
+	OmitEmptyAlways:   true,
+	OmitEmptyNullable: true,
+}
+
+// TagGenerator generates a single struct tag for every generated field
+//
+// The value of the tag is the column name converted to Case, except for the
+// "validate" tag whose value is built from the column's constraints instead
+type TagGenerator struct {
+	// Name is the name of the tag, eg. "json" or "yaml"
+	Name string
+
+	// Case is the case the column name is converted to
+	Case NamingCase
+
+	// OmitEmpty decides which fields get ",omitempty" appended
+	OmitEmpty OmitEmptyPolicy
+}
+
+// DefaultTagGenerators returns the tag generators used when none are configured,
+// a "db" tag with the column name as is and a lower camel case "json" tag
+func DefaultTagGenerators() []TagGenerator {
+	return []TagGenerator{
+		{Name: "db", Case: AsIsCase, OmitEmpty: OmitEmptyNever},
+		{Name: "json", Case: CamelCase, OmitEmpty: OmitEmptyNever},
+	}
+}
+
+// Validate checks the tag generator has a name and a known case and omitempty policy
+func (t TagGenerator) Validate() error {
+	if t.Name == "" {
+		return fmt.Errorf(packageErr, ErrInvalidTagGenerator, "tag generator must set a name")
+	}
+
+	if t.Case != "" {
+		if _, ok := namingCaseMap[t.Case]; !ok {
+			return fmt.Errorf(
+				packageErr,
+				ErrInvalidTagGenerator,
+				fmt.Sprintf("unknown case '%s' for tag '%s'.  Options are 'snake', 'camel', 'pascal', 'kebab', 'as-is'", t.Case, t.Name),
+			)
+		}
+	}
+
+	if t.OmitEmpty != "" {
+		if _, ok := omitEmptyPolicyMap[t.OmitEmpty]; !ok {
+			return fmt.Errorf(
+				packageErr,
+				ErrInvalidTagGenerator,
+				fmt.Sprintf("unknown omitempty policy '%s' for tag '%s'.  Options are 'never', 'always', 'nullable'", t.OmitEmpty, t.Name),
+			)
+		}
+	}
+
+	return nil
+}
+
+// Value returns the tag value for a field generated from col
+func (t TagGenerator) Value(col Column) string {
+	if t.Name == "validate" {
+		return validateValue(col)
+	}
+
+	value := convertCase(col.Name, t.Case)
+
+	if t.OmitEmpty == OmitEmptyAlways || (t.OmitEmpty == OmitEmptyNullable && col.Nullable) {
+		value += ",omitempty"
+	}
+
+	return value
+}
+
+// Tag returns the full tag for a field generated from col, eg. `yaml:"user_id"`
+func (t TagGenerator) Tag(col Column) string {
+	value := t.Value(col)
+
+	if value == "" {
+		return ""
+	}
+
+	return t.Name + `:"` + value + `"`
+}
+
+// relationTag returns the full tag for a relation field
+//
+// Relations are always nullable and have no column constraints so the "validate"
+// tag is skipped for them
+func (t TagGenerator) relationTag(name string) string {
+	if t.Name == "validate" {
+		return ""
+	}
+
+	return t.Tag(Column{Name: name, Nullable: true})
+}
+
+// validateValue builds a go-playground/validator rule list from the constraints
+// of col, eg. "required,max=255"
+//
+// Not null columns without a default are required, except booleans where false
+// is a valid value.  Numeric rules are only generated for numeric columns as
+// validator applies them to the length of strings
+func validateValue(col Column) string {
+	var rules []string
+
+	kind := col.kind()
+	numeric := kind == integerKind || kind == decimalKind || kind == floatKind
+
+	if col.Nullable {
+		rules = append(rules, "omitempty")
+	} else if !col.HasDefault && kind != boolKind {
+		rules = append(rules, "required")
+	}
+
+	if kind == stringKind && col.MaxLength > 0 {
+		rules = append(rules, fmt.Sprintf("max=%d", col.MaxLength))
+	}
+
+	if kind == decimalKind && col.NumericPrecision > 0 && col.NumericPrecision >= col.NumericScale {
+		bound := "1" + strings.Repeat("0", col.NumericPrecision-col.NumericScale)
+		rules = append(rules, "gt=-"+bound, "lt="+bound)
+	}
+
+	if numeric {
+		for _, check := range col.Checks {
+			if rule, ok := checkRuleMap[check.Operator]; ok {
+				rules = append(rules, rule+"="+check.Value)
+			}
+		}
+	}
+
+	if oneOf := oneOfValue(col.EnumValues); oneOf != "" {
+		rules = append(rules, "oneof="+oneOf)
+	}
+
+	return strings.Join(rules, ",")
+}
+
+// checkRuleMap maps check constraint operators to validator rules
+var checkRuleMap = map[string]string{
+	">":  "gt",
+	">=": "gte",
+	"<":  "lt",
+	"<=": "lte",
+	"<>": "ne",
+	"!=": "ne",
+	"=":  "eq",
+}
+
+// oneOfValue joins values the way validator's "oneof" rule expects, quoting values
+// that contain spaces
+//
+// Values containing characters that can't be expressed within a struct tag
+// produce no rule at all rather than a rule that rejects valid values
+func oneOfValue(values []string) string {
+	quoted := make([]string, 0, len(values))
+
+	for _, v := range values {
+		if strings.ContainsAny(v, `,|"'`+"`") {
+			return ""
+		}
+
+		if strings.Contains(v, " ") {
+			v = "'" + v + "'"
+		}
+
+		quoted = append(quoted, v)
+	}
+
+	return strings.Join(quoted, " ")
+}
+
+// convertCase converts a snake case database name to namingCase
+func convertCase(name string, namingCase NamingCase) string {
+	switch namingCase {
+	case SnakeCase:
+		return snaker.CamelToSnake(name)
+	case CamelCase:
+		return snaker.ForceLowerCamelIdentifier(name)
+	case PascalCase:
+		return snaker.ForceCamelIdentifier(name)
+	case KebabCase:
+		return strings.ReplaceAll(snaker.CamelToSnake(name), "_", "-")
+	default:
+		return name
+	}
+}
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTagGenerator(t *testing.T) {
+	var err error
+
+	if err = (TagGenerator{}).Validate(); !errors.Is(err, ErrInvalidTagGenerator) {
+		t.Fatalf("should have error %v; got %v\n", ErrInvalidTagGenerator, err)
+	}
+
+	if err = (TagGenerator{Name: "json", Case: "upper"}).Validate(); !errors.Is(err, ErrInvalidTagGenerator) {
+		t.Fatalf("should have error %v; got %v\n", ErrInvalidTagGenerator, err)
+	}
+
+	if err = (TagGenerator{Name: "json", OmitEmpty: "sometimes"}).Validate(); !errors.Is(err, ErrInvalidTagGenerator) {
+		t.Fatalf("should have error %v; got %v\n", ErrInvalidTagGenerator, err)
+	}
+
+	tests := []struct {
+		tag      TagGenerator
+		nullable bool
+		expected string
+	}{
+		{TagGenerator{Name: "db", Case: AsIsCase}, false, `db:"user_profile_id"`},
+		{TagGenerator{Name: "json", Case: CamelCase}, false, `json:"userProfileID"`},
+		{TagGenerator{Name: "json", Case: SnakeCase, OmitEmpty: OmitEmptyNullable}, true, `json:"user_profile_id,omitempty"`},
+		{TagGenerator{Name: "json", Case: SnakeCase, OmitEmpty: OmitEmptyNullable}, false, `json:"user_profile_id"`},
+		{TagGenerator{Name: "yaml", Case: KebabCase}, false, `yaml:"user-profile-id"`},
+		{TagGenerator{Name: "mapstructure", Case: PascalCase}, false, `mapstructure:"UserProfileID"`},
+		{TagGenerator{Name: "bson", Case: SnakeCase, OmitEmpty: OmitEmptyAlways}, false, `bson:"user_profile_id,omitempty"`},
+		{TagGenerator{Name: "validate"}, false, `validate:"required"`},
+		{TagGenerator{Name: "validate"}, true, `validate:"omitempty"`},
+	}
+
+	for _, test := range tests {
+		if tag := test.tag.Tag(Column{Name: "user_profile_id", Nullable: test.nullable}); tag != test.expected {
+			t.Fatalf("expected tag '%s'; got '%s'\n", test.expected, tag)
+		}
+	}
+
+	if tag := (TagGenerator{Name: "validate"}).relationTag("user_profile"); tag != "" {
+		t.Fatalf("relation fields should not get validate tag; got '%s'\n", tag)
+	}
+}
+
+func TestValidateValue(t *testing.T) {
+	tests := []struct {
+		col      Column
+		expected string
+	}{
+		{Column{Name: "name", DataType: "varchar", MaxLength: 255}, "required,max=255"},
+		{Column{Name: "name", DataType: "text", Nullable: true}, "omitempty"},
+		{Column{Name: "id", DataType: "int8", HasDefault: true}, ""},
+		{Column{Name: "active", DataType: "bool"}, ""},
+		{Column{Name: "price", DataType: "numeric", NumericPrecision: 5, NumericScale: 2}, "required,gt=-1000,lt=1000"},
+		{
+			Column{Name: "qty", DataType: "int4", Checks: []Check{{Operator: ">", Value: "0"}, {Operator: "<=", Value: "100"}}},
+			"required,gt=0,lte=100",
+		},
+		{Column{Name: "code", DataType: "text", Checks: []Check{{Operator: ">", Value: "0"}}}, "required"},
+		{Column{Name: "status", DataType: "status", EnumValues: []string{"active", "on hold"}}, "required,oneof=active 'on hold'"},
+		{Column{Name: "status", DataType: "text", EnumValues: []string{"a,b"}}, "required"},
+	}
+
+	for _, test := range tests {
+		if value := validateValue(test.col); value != test.expected {
+			t.Fatalf("expected validate value '%s' for column '%s'; got '%s'\n", test.expected, test.col.Name, value)
+		}
+	}
+}
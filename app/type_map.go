@@ -0,0 +1,243 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrInvalidTypeMap = errors.New("model-gen: invalid type map")
+)
+
+// TypeMapping maps a database type to the types used in generated code
+type TypeMapping struct {
+	// DBType is the database type being mapped, eg. "timestamptz" or "int8"
+	DBType string
+
+	// Driver limits the mapping to a single database driver
+	//
+	// An empty driver applies to every driver
+	Driver DBDriver
+
+	// Nullable limits the mapping to nullable (true) or not null (false) columns
+	//
+	// A nil value applies to both
+	Nullable *bool
+
+	// ColumnPattern is a regular expression limiting the mapping to columns with a
+	// matching name
+	ColumnPattern string
+
+	// GoType is the type used in generated go models
+	GoType string
+
+	// GoImport is the import path GoType requires, if any
+	GoImport string
+
+	// TsType is the type used in generated typescript interfaces
+	TsType string
+
+	columnReg *regexp.Regexp
+}
+
+// TypeMap is an ordered list of type mappings where the first matching mapping wins
+//
+// Mappings limited by nullability or column name always take precedence over
+// mappings that apply to every column of a type
+type TypeMap []TypeMapping
+
+// Validate checks every mapping has a database type, a type to map to and a valid
+// column pattern
+func (tm TypeMap) Validate() error {
+	for i := range tm {
+		if tm[i].DBType == "" {
+			return fmt.Errorf(packageErr, ErrInvalidTypeMap, "mapping must set a database type")
+		}
+
+		if tm[i].GoType == "" && tm[i].TsType == "" {
+			return fmt.Errorf(
+				packageErr,
+				ErrInvalidTypeMap,
+				fmt.Sprintf("mapping for '%s' must set a go or ts type", tm[i].DBType),
+			)
+		}
+
+		if tm[i].ColumnPattern != "" {
+			reg, err := regexp.Compile(tm[i].ColumnPattern)
+
+			if err != nil {
+				return fmt.Errorf(packageErr, ErrInvalidTypeMap, err.Error())
+			}
+
+			tm[i].columnReg = reg
+		}
+	}
+
+	return nil
+}
+
+// DataTypeMap returns the go type of every unconditional mapping that applies to
+// driver in the form gorm/gen's WithDataTypeMap expects
+//
+// Conditional mappings can't be expressed this way as gorm/gen only passes the
+// column type along so they are applied per column by GenerateModels instead
+func (tm TypeMap) DataTypeMap(driver DBDriver) map[string]func(detailType string) (dataType string) {
+	dataMap := map[string]func(detailType string) (dataType string){}
+
+	for i := len(tm) - 1; i >= 0; i-- {
+		m := tm[i]
+
+		if m.GoType == "" || m.conditional() || !m.appliesTo(driver) {
+			continue
+		}
+
+		goType := m.GoType
+		convert := func(detailType string) (dataType string) {
+			return goType
+		}
+
+		// gorm/gen looks custom types up by the exact name the driver reports, which
+		// is lower case for some drivers and upper case for others
+		dataMap[strings.ToLower(m.DBType)] = convert
+		dataMap[strings.ToUpper(m.DBType)] = convert
+	}
+
+	return dataMap
+}
+
+// GoImports returns the import path of every mapping that applies to driver
+func (tm TypeMap) GoImports(driver DBDriver) []string {
+	var imports []string
+
+	seen := make(map[string]bool)
+
+	for _, m := range tm {
+		if m.GoImport == "" || seen[m.GoImport] || !m.appliesTo(driver) {
+			continue
+		}
+
+		seen[m.GoImport] = true
+		imports = append(imports, m.GoImport)
+	}
+
+	return imports
+}
+
+// Lookup returns the mapping for a column of dbType
+//
+// An error is returned if the column pattern of a mapping that was never
+// validated doesn't compile
+func (tm TypeMap) Lookup(driver DBDriver, dbType string, nullable bool, columnName string) (TypeMapping, bool, error) {
+	return tm.lookup(driver, dbType, nullable, columnName, func(TypeMapping) bool { return true })
+}
+
+// TsType returns the typescript type of the first mapping for col that sets one
+func (tm TypeMap) TsType(driver DBDriver, col Column) (string, bool, error) {
+	m, ok, err := tm.lookup(driver, col.DataType, col.Nullable, col.Name, func(m TypeMapping) bool {
+		return m.TsType != ""
+	})
+
+	return m.TsType, ok, err
+}
+
+// TsTypes returns the typescript type of every mapping keyed by the go type it
+// maps to
+//
+// The go type stands in for the database type and conditions that led to it, so
+// columns given the type by their column config are typed the same
+func (tm TypeMap) TsTypes() map[string]string {
+	tsTypes := make(map[string]string)
+
+	for _, m := range tm {
+		if m.GoType == "" || m.TsType == "" {
+			continue
+		}
+
+		if _, ok := tsTypes[m.GoType]; !ok {
+			tsTypes[m.GoType] = m.TsType
+		}
+	}
+
+	return tsTypes
+}
+
+func (tm TypeMap) lookup(
+	driver DBDriver,
+	dbType string,
+	nullable bool,
+	columnName string,
+	accept func(TypeMapping) bool,
+) (TypeMapping, bool, error) {
+	dbType = normalizeDataType(dbType)
+
+	for _, conditional := range []bool{true, false} {
+		// Mappings are visited by index so compiled column patterns are kept
+		for i := range tm {
+			m := &tm[i]
+
+			if m.conditional() != conditional || !m.appliesTo(driver) || !accept(*m) {
+				continue
+			}
+
+			if normalizeDataType(m.DBType) != dbType {
+				continue
+			}
+
+			if m.Nullable != nil && *m.Nullable != nullable {
+				continue
+			}
+
+			if m.ColumnPattern != "" {
+				reg, err := m.columnRegexp()
+
+				if err != nil {
+					return TypeMapping{}, false, err
+				}
+				if !reg.MatchString(columnName) {
+					continue
+				}
+			}
+
+			return *m, true, nil
+		}
+	}
+
+	return TypeMapping{}, false, nil
+}
+
+func (m TypeMapping) conditional() bool {
+	return m.Nullable != nil || m.ColumnPattern != ""
+}
+
+func (m TypeMapping) appliesTo(driver DBDriver) bool {
+	return m.Driver == "" || m.Driver == driver
+}
+
+// columnRegexp returns the compiled column pattern, compiling and keeping it if
+// Validate wasn't called
+func (m *TypeMapping) columnRegexp() (*regexp.Regexp, error) {
+	if m.columnReg == nil {
+		reg, err := regexp.Compile(m.ColumnPattern)
+
+		if err != nil {
+			return nil, fmt.Errorf(packageErr, ErrInvalidTypeMap, err.Error())
+		}
+
+		m.columnReg = reg
+	}
+
+	return m.columnReg, nil
+}
+
+// normalizeDataType lower cases dataType and strips any length or precision
+// so "VARCHAR(255)" and "varchar" are treated the same
+func normalizeDataType(dataType string) string {
+	if i := strings.Index(dataType, "("); i != -1 {
+		dataType = dataType[:i]
+	}
+
+	return strings.ToLower(strings.TrimSpace(dataType))
+}
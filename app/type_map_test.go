@@ -0,0 +1,92 @@
+package app
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTypeMap(t *testing.T) {
+	var err error
+
+	notNull := false
+
+	typeMap := TypeMap{
+		{DBType: "numeric", ColumnPattern: "_amount$", GoType: "decimal.Decimal", GoImport: "github.com/shopspring/decimal", TsType: "string"},
+		{DBType: "jsonb", Driver: PostgresDriver, Nullable: &notNull, GoType: "datatypes.JSON", GoImport: "gorm.io/datatypes"},
+		{DBType: "timestamptz", GoType: "int64"},
+		{DBType: "TIMESTAMPTZ", GoType: "string"},
+	}
+
+	if err = typeMap.Validate(); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	if err = (TypeMap{{DBType: "uuid"}}).Validate(); !errors.Is(err, ErrInvalidTypeMap) {
+		t.Fatalf("should have error %v; got %v\n", ErrInvalidTypeMap, err)
+	}
+
+	if err = (TypeMap{{DBType: "uuid", GoType: "string", ColumnPattern: "("}}).Validate(); !errors.Is(err, ErrInvalidTypeMap) {
+		t.Fatalf("should have error %v; got %v\n", ErrInvalidTypeMap, err)
+	}
+
+	dataMap := typeMap.DataTypeMap(PostgresDriver)
+
+	if len(dataMap) != 2 {
+		t.Fatalf("only unconditional mappings should be in data type map; got %d entries\n", len(dataMap))
+	}
+
+	if convert, ok := dataMap["timestamptz"]; !ok || convert("timestamptz") != "int64" {
+		t.Fatalf("first mapping for timestamptz should win\n")
+	}
+
+	if imports := typeMap.GoImports(MysqlDriver); len(imports) != 1 || imports[0] != "github.com/shopspring/decimal" {
+		t.Fatalf("expected only decimal import for mysql; got %v\n", imports)
+	}
+
+	if m, ok, _ := typeMap.Lookup(PostgresDriver, "NUMERIC(10,2)", true, "total_amount"); !ok || m.GoType != "decimal.Decimal" {
+		t.Fatalf("numeric amount column should be mapped to decimal.Decimal; got %v\n", m)
+	}
+
+	if _, ok, _ := typeMap.Lookup(PostgresDriver, "numeric", true, "total"); ok {
+		t.Fatalf("numeric column not matching pattern should not be mapped\n")
+	}
+
+	if _, ok, _ := typeMap.Lookup(PostgresDriver, "jsonb", true, "settings"); ok {
+		t.Fatalf("nullable jsonb column should not be mapped\n")
+	}
+
+	if _, ok, _ := typeMap.Lookup(MysqlDriver, "jsonb", false, "settings"); ok {
+		t.Fatalf("jsonb mapping should only apply to postgres\n")
+	}
+
+	if m, ok, _ := typeMap.Lookup(PostgresDriver, "jsonb", false, "settings"); !ok || m.GoType != "datatypes.JSON" {
+		t.Fatalf("not null jsonb column should be mapped to datatypes.JSON; got %v\n", m)
+	}
+
+	if tsType, ok, _ := typeMap.TsType(PostgresDriver, Column{Name: "total_amount", DataType: "numeric"}); !ok || tsType != "string" {
+		t.Fatalf("expected ts type 'string' for numeric amount column; got '%s'\n", tsType)
+	}
+
+	if _, ok, _ := typeMap.TsType(PostgresDriver, Column{Name: "settings", DataType: "jsonb"}); ok {
+		t.Fatalf("jsonb mapping without ts type should not set ts type\n")
+	}
+}
+
+func TestTypeMapColumnPattern(t *testing.T) {
+	typeMap := TypeMap{{DBType: "numeric", ColumnPattern: "_amount$", GoType: "decimal.Decimal"}}
+
+	// Patterns are compiled on first use when Validate wasn't called and kept
+	if _, ok, err := typeMap.Lookup(PostgresDriver, "numeric", false, "total_amount"); err != nil || !ok {
+		t.Fatalf("numeric amount column should be mapped; got %v, %v\n", ok, err)
+	}
+
+	if typeMap[0].columnReg == nil {
+		t.Fatalf("compiled column pattern should be kept\n")
+	}
+
+	invalid := TypeMap{{DBType: "numeric", ColumnPattern: "(", GoType: "decimal.Decimal"}}
+
+	if _, _, err := invalid.Lookup(PostgresDriver, "numeric", false, "total"); !errors.Is(err, ErrInvalidTypeMap) {
+		t.Fatalf("should have error %v; got %v\n", ErrInvalidTypeMap, err)
+	}
+}
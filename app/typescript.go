@@ -0,0 +1,598 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// TsNullMode decides how nullable columns are represented in typescript
+type TsNullMode string
+
+var (
+	// TsNullModeNull types nullable fields as "name: T | null"
+	TsNullModeNull TsNullMode = "null"
+
+	// TsNullModeOptional types nullable fields as "name?: T"
+	TsNullModeOptional TsNullMode = "optional"
+
+	// TsNullModeOptionalNull types nullable fields as "name?: T | null"
+	TsNullModeOptionalNull TsNullMode = "optional-null"
+)
+
+// TsMode decides what is generated for every table
+type TsMode string
+
+var (
+	// TsModeInterface generates a typescript interface per table
+	TsModeInterface TsMode = "interface"
+
+	// TsModeZod generates a zod schema per table along with a type inferred from it
+	TsModeZod TsMode = "zod"
+)
+
+// TsTypes picks the typescript type of columns whose json representation is
+// ambiguous
+//
+// Empty options keep the type of the json the go models encode, eg. "number"
+// for bigint columns generated as int64
+type TsTypes struct {
+	// Timestamp is the type of date and time columns, either "string", "Date" or
+	// "ISODateString" which generates a branded string type
+	Timestamp string
+
+	// Bigint is the type of 64 bit integer columns, either "string", "number" or "bigint"
+	Bigint string
+
+	// Numeric is the type of numeric and decimal columns, either "string" or "number"
+	Numeric string
+
+	// JSON is the type of json columns, eg. "unknown" or a user defined type
+	JSON string
+
+	// JSONImport is the module JSON is imported from, relative to the ts directory
+	JSONImport string
+}
+
+const (
+	tsISODateString = "ISODateString"
+	tsSharedModule  = "types"
+)
+
+var (
+	ErrInvalidTsTypes = errors.New("model-gen: invalid ts types")
+)
+
+var tsTypeOptions = map[string]map[string]bool{
+	"timestamp": {"string": true, "Date": true, tsISODateString: true},
+	"bigint":    {"string": true, "number": true, "bigint": true},
+	"numeric":   {"string": true, "number": true},
+}
+
+// Validate checks every option is one of the supported types
+func (t TsTypes) Validate() error {
+	for _, option := range []struct {
+		name, value string
+	}{
+		{"timestamp", t.Timestamp},
+		{"bigint", t.Bigint},
+		{"numeric", t.Numeric},
+	} {
+		if option.value == "" || tsTypeOptions[option.name][option.value] {
+			continue
+		}
+
+		return fmt.Errorf(
+			packageErr,
+			ErrInvalidTsTypes,
+			fmt.Sprintf("unknown %s type '%s'.  Options are %s", option.name, option.value, quotedKeys(tsTypeOptions[option.name])),
+		)
+	}
+
+	if t.JSONImport != "" && t.JSON == "" {
+		return fmt.Errorf(packageErr, ErrInvalidTsTypes, "json import requires a json type")
+	}
+
+	return nil
+}
+
+// columnType returns the typescript type chosen for a column of kind whose go
+// type encodes to shape, if any
+func (t TsTypes) columnType(kind dataKind, shape jsonShape) string {
+	switch {
+	case shape == jsonTime:
+		return t.Timestamp
+	case shape == jsonBigint:
+		return t.Bigint
+	case kind == decimalKind:
+		return t.Numeric
+	case kind == jsonKind:
+		return t.JSON
+	default:
+		return ""
+	}
+}
+
+// tsShapeTypes is the typescript type of every json shape
+var tsShapeTypes = map[jsonShape]string{
+	jsonAny:    "unknown",
+	jsonString: "string",
+	jsonInt:    "number",
+	jsonBigint: "number",
+	jsonFloat:  "number",
+	jsonBool:   "boolean",
+	jsonTime:   "string",
+	jsonBytes:  "string",
+}
+
+// GenerateTsModels generates a typescript interface or zod schema for every table
+// in a single file named tsFile.tsOutFile within tsDir
+//
+// When cfg.PerTable is set every table is generated into its own file named
+// after the table instead along with an index.ts re-exporting all of them, in
+// which case tsFile is not used
+func GenerateTsModels(tables []Table, driver DBDriver, tsDir, tsFile, tsOutFile string, cfg GenerateConfig) error {
+	if tsDir == "" {
+		return errors.WithStack(fmt.Errorf("model-gen: tsDir parameter can't be empty"))
+	}
+	if tsFile == "" && !cfg.PerTable {
+		return errors.WithStack(fmt.Errorf("model-gen: tsFile parameter can't be empty"))
+	}
+	if tsOutFile == "" {
+		return errors.WithStack(fmt.Errorf("model-gen: tsOutFile parameter can't be empty"))
+	}
+
+	var err error
+
+	if err = cfg.Model.TypeMap.Validate(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err = os.MkdirAll(tsDir, os.ModePerm); err != nil {
+		return errors.WithStack(err)
+	}
+
+	// Typescript types follow the go types gorm/gen picks for driver
+	cfg.Model.Driver = driver
+
+	if cfg.BrandedIDs {
+		cfg.idTypes = tsIDTypes(tables, cfg)
+	}
+
+	if cfg.Mode == TsModeZod {
+		cfg.cyclicTables = tsCyclicTables(tables)
+	}
+
+	if cfg.PerTable {
+		return generateTsFiles(tables, tsDir, tsOutFile, cfg)
+	}
+
+	newFile, err := os.Create(filepath.Join(tsDir, tsFile) + "." + tsOutFile)
+
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	defer newFile.Close()
+
+	newFileWriter := bufio.NewWriter(newFile)
+	newFileWriter.WriteString(tsHeader(tables, cfg, ""))
+
+	for _, table := range tables {
+		if _, err = newFileWriter.WriteString(tsContent(table, cfg)); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	return errors.WithStack(newFileWriter.Flush())
+}
+
+// generateTsFiles generates a file per table importing the types of its
+// relations from their files, along with an index.ts barrel
+func generateTsFiles(tables []Table, tsDir, tsOutFile string, cfg GenerateConfig) error {
+	var index strings.Builder
+
+	module := func(tableName string) string {
+		return "./" + tableName + "." + strings.TrimSuffix(tsOutFile, ".ts")
+	}
+
+	shared := tsSharedDeclarations(tables, cfg)
+
+	if shared != "" {
+		if cfg.Mode == TsModeZod {
+			shared = "import { z } from 'zod'\n\n" + shared
+		}
+
+		err := os.WriteFile(filepath.Join(tsDir, tsSharedModule+".ts"), []byte(strings.TrimSuffix(shared, "\n")), 0644)
+
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	for _, table := range tables {
+		var header strings.Builder
+
+		header.WriteString(strings.TrimSuffix(tsHeader([]Table{table}, cfg, "./"+tsSharedModule), "\n"))
+
+		imported := map[string]bool{table.Name: true}
+
+		for _, fk := range table.ForeignKeys {
+			if imported[fk.ForeignTableName] {
+				continue
+			}
+
+			imported[fk.ForeignTableName] = true
+
+			// zod schemas are referenced as values so they can't be imported as types
+			if cfg.Mode == TsModeZod {
+				header.WriteString(fmt.Sprintf(
+					"import { %sSchema } from '%s'\n",
+					cfg.Model.structName(fk.ForeignTableName),
+					module(fk.ForeignTableName),
+				))
+			}
+
+			// The interfaces of cyclic zod schemas reference the types of relations
+			if cfg.Mode != TsModeZod || cfg.cyclicTables[table.Name] {
+				header.WriteString(fmt.Sprintf(
+					"import type { %s } from '%s'\n",
+					cfg.Model.structName(fk.ForeignTableName),
+					module(fk.ForeignTableName),
+				))
+			}
+		}
+
+		if header.Len() > 0 {
+			header.WriteString("\n")
+		}
+
+		content := header.String() + strings.TrimSuffix(tsContent(table, cfg), "\n")
+
+		if err := os.WriteFile(filepath.Join(tsDir, table.Name+"."+tsOutFile), []byte(content), 0644); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	tableNames := make([]string, 0, len(tables))
+
+	for _, table := range tables {
+		tableNames = append(tableNames, table.Name)
+	}
+
+	sort.Strings(tableNames)
+
+	if shared != "" {
+		index.WriteString(fmt.Sprintf("export * from './%s'\n", tsSharedModule))
+	}
+
+	for _, tableName := range tableNames {
+		index.WriteString(fmt.Sprintf("export * from '%s'\n", module(tableName)))
+	}
+
+	return errors.WithStack(os.WriteFile(filepath.Join(tsDir, "index.ts"), []byte(index.String()), 0644))
+}
+
+// tsContent returns the interface or zod schema of table depending on cfg.Mode
+func tsContent(table Table, cfg GenerateConfig) string {
+	switch cfg.Mode {
+	case TsModeZod:
+		return zodSchema(table, cfg)
+	default:
+		return tsInterface(table, cfg)
+	}
+}
+
+// tsInterface returns the typescript interface of table
+func tsInterface(table Table, cfg GenerateConfig) string {
+	var b strings.Builder
+
+	b.WriteString(docBlock(table.Comment, ""))
+	b.WriteString(fmt.Sprintf("export interface %s {\n", cfg.Model.structName(table.Name)))
+
+	for _, col := range table.Columns {
+		name, omitEmpty, ok := cfg.Model.jsonField(table.Name, col)
+
+		// Fields hidden from json are never part of the api contract
+		if !ok {
+			continue
+		}
+
+		b.WriteString(docBlock(col.Comment, "\t"))
+		fieldType := cfg.idType(table, col)
+
+		if fieldType == "" {
+			fieldType = tsType(table, col, cfg)
+		}
+
+		b.WriteString(tsField(name, fieldType, col.Nullable, omitEmpty, cfg.NullMode))
+	}
+
+	// Relations are only set when they are loaded so they are always nullable
+	for _, fk := range table.ForeignKeys {
+		name, omitEmpty := cfg.Model.relationJSONField(table, fk)
+
+		b.WriteString(tsField(name, cfg.Model.structName(fk.ForeignTableName), true, omitEmpty, cfg.NullMode))
+	}
+
+	b.WriteString("}\n\n")
+
+	return b.String()
+}
+
+// tsField returns the property declaration of a field
+//
+// Fields omitted from json when empty are always optional as they might be
+// missing entirely
+func tsField(name, fieldType string, nullable, omitEmpty bool, nullMode TsNullMode) string {
+	optional := omitEmpty
+
+	if nullable {
+		switch nullMode {
+		case TsNullModeOptional:
+			optional = true
+		case TsNullModeOptionalNull:
+			optional = true
+			fieldType += " | null"
+		default:
+			fieldType += " | null"
+		}
+	}
+
+	if optional {
+		return fmt.Sprintf("\t%s?: %s\n", name, fieldType)
+	}
+
+	return fmt.Sprintf("\t%s: %s\n", name, fieldType)
+}
+
+// tsType returns the typescript type of col of table, preferring a type map entry
+// for the column or for its go type
+func tsType(table Table, col Column, cfg GenerateConfig) string {
+	if t, ok := cfg.tsMappedType(table, col); ok {
+		return t
+	}
+
+	shape := cfg.Model.jsonShape(table, col)
+
+	if cfg.Model.enumColumn(table, col) {
+		values := make([]string, 0, len(col.EnumValues))
+
+		for _, v := range col.EnumValues {
+			values = append(values, tsString(v))
+		}
+
+		return strings.Join(values, " | ")
+	}
+
+	if t := cfg.Types.columnType(col.kind(), shape); t != "" {
+		return t
+	}
+
+	return tsShapeTypes[shape]
+}
+
+// tsMappedType returns the typescript type the type map sets for col of table,
+// either for its database type or for the go type it is generated with
+func (cfg GenerateConfig) tsMappedType(table Table, col Column) (string, bool) {
+	// Column patterns are compiled by GenerateTsModels so the lookup can't fail
+	if t, ok, _ := cfg.Model.TypeMap.TsType(cfg.Model.Driver, col); ok {
+		return t, true
+	}
+
+	goType, _ := cfg.Model.goType(table, col)
+	t, ok := cfg.Model.TypeMap.TsTypes()[goType]
+
+	return t, ok
+}
+
+// tsIDTypes returns the name of the branded id type of every table with a single
+// column primary key, keyed by table name
+func tsIDTypes(tables []Table, cfg GenerateConfig) map[string]string {
+	idTypes := make(map[string]string)
+
+	for _, table := range tables {
+		if table.primaryKey() != nil {
+			idTypes[table.Name] = cfg.Model.structName(table.Name) + "Id"
+		}
+	}
+
+	return idTypes
+}
+
+// tsCyclicTables returns the tables whose relations lead back to themselves,
+// either directly or through other tables, keyed by table name
+func tsCyclicTables(tables []Table) map[string]bool {
+	relations := make(map[string][]string, len(tables))
+
+	for _, table := range tables {
+		for _, fk := range table.ForeignKeys {
+			relations[table.Name] = append(relations[table.Name], fk.ForeignTableName)
+		}
+	}
+
+	cyclic := make(map[string]bool)
+
+	for _, table := range tables {
+		visited := map[string]bool{}
+		pending := append([]string{}, relations[table.Name]...)
+
+		for len(pending) > 0 {
+			name := pending[len(pending)-1]
+			pending = pending[:len(pending)-1]
+
+			if name == table.Name {
+				cyclic[table.Name] = true
+				break
+			}
+
+			if !visited[name] {
+				visited[name] = true
+				pending = append(pending, relations[name]...)
+			}
+		}
+	}
+
+	return cyclic
+}
+
+// idType returns the branded id type of col, either the one of table if col is
+// its primary key or the one of the referenced table if col is a foreign key
+//
+// Empty is returned if branded ids are not generated or the column has none
+func (cfg GenerateConfig) idType(table Table, col Column) string {
+	if pk := table.primaryKey(); pk != nil && pk.Name == col.Name {
+		return cfg.idTypes[table.Name]
+	}
+
+	for _, fk := range table.ForeignKeys {
+		if fk.ColumnName == col.Name {
+			return cfg.idTypes[fk.ForeignTableName]
+		}
+	}
+
+	return ""
+}
+
+// tsUsesISODate returns whether any column of tables is typed as ISODateString
+func tsUsesISODate(tables []Table, cfg GenerateConfig) bool {
+	for _, table := range tables {
+		for _, col := range table.Columns {
+			if tsType(table, col, cfg) == tsISODateString && cfg.Model.jsonShape(table, col) == jsonTime {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// tsSharedTypes returns the sorted names of the types declared once for every
+// file that the fields of tables use, along with whether they use the user
+// defined json type
+func tsSharedTypes(tables []Table, cfg GenerateConfig) ([]string, bool) {
+	var jsonType bool
+
+	shared := make(map[string]bool)
+
+	for _, table := range tables {
+		for _, col := range table.Columns {
+			if _, _, ok := cfg.Model.jsonField(table.Name, col); !ok {
+				continue
+			}
+
+			if id := cfg.idType(table, col); id != "" {
+				shared[id] = true
+				continue
+			}
+
+			switch t := tsType(table, col, cfg); {
+			case t == tsISODateString && cfg.Model.jsonShape(table, col) == jsonTime:
+				shared[tsISODateString] = true
+			case t == cfg.Types.JSON && col.kind() == jsonKind && cfg.Types.JSONImport != "":
+				jsonType = true
+			}
+		}
+	}
+
+	return sortedKeys(shared), jsonType
+}
+
+// tsHeader returns the imports and shared type declarations a file containing
+// tables needs
+//
+// The shared types are declared in the file itself unless sharedModule is set,
+// in which case they are imported from there
+func tsHeader(tables []Table, cfg GenerateConfig, sharedModule string) string {
+	var b strings.Builder
+
+	shared, jsonType := tsSharedTypes(tables, cfg)
+
+	if cfg.Mode == TsModeZod {
+		b.WriteString("import { z } from 'zod'\n")
+	}
+
+	if jsonType {
+		b.WriteString(fmt.Sprintf("import type { %s } from '%s'\n", cfg.Types.JSON, cfg.Types.JSONImport))
+	}
+
+	if len(shared) > 0 && sharedModule != "" {
+		// zod schemas are referenced as values so they can't be imported as types
+		if cfg.Mode == TsModeZod {
+			for i := range shared {
+				shared[i] += "Schema"
+			}
+
+			b.WriteString(fmt.Sprintf("import { %s } from '%s'\n", strings.Join(shared, ", "), sharedModule))
+		} else {
+			b.WriteString(fmt.Sprintf("import type { %s } from '%s'\n", strings.Join(shared, ", "), sharedModule))
+		}
+	}
+
+	if b.Len() > 0 {
+		b.WriteString("\n")
+	}
+
+	if sharedModule == "" {
+		b.WriteString(tsSharedDeclarations(tables, cfg))
+	}
+
+	return b.String()
+}
+
+// tsSharedDeclarations returns the declarations of the ISODateString brand and
+// the branded id types of tables, whichever are used
+//
+// Zod schemas are declared eagerly so they have to come before the schemas of
+// any table using them
+func tsSharedDeclarations(tables []Table, cfg GenerateConfig) string {
+	var b strings.Builder
+
+	if tsUsesISODate(tables, cfg) {
+		b.WriteString(tsBrandDeclaration(tsISODateString, "string", "z.string().datetime({ offset: true })", cfg.Mode))
+	}
+
+	for _, table := range tables {
+		id, ok := cfg.idTypes[table.Name]
+
+		if !ok {
+			continue
+		}
+
+		pk := table.primaryKey()
+
+		b.WriteString(tsBrandDeclaration(id, tsType(table, *pk, cfg), zodType(table, *pk, cfg), cfg.Mode))
+	}
+
+	return b.String()
+}
+
+// tsBrandDeclaration returns the declaration of a branded type named name, based
+// on tsBase or on the zod schema zodBase in zod mode
+func tsBrandDeclaration(name, tsBase, zodBase string, mode TsMode) string {
+	if mode == TsModeZod {
+		return fmt.Sprintf(
+			"export const %sSchema = %s.brand('%s')\n\n"+
+				"export type %s = z.infer<typeof %sSchema>\n\n",
+			name, zodBase, name, name, name,
+		)
+	}
+
+	return fmt.Sprintf("export type %s = %s & { readonly __brand: '%s' }\n\n", name, tsBase, name)
+}
+
+// quotedKeys returns the keys of m quoted and sorted, eg. 'a', 'b'
+func quotedKeys(m map[string]bool) string {
+	keys := sortedKeys(m)
+
+	for i := range keys {
+		keys[i] = "'" + keys[i] + "'"
+	}
+
+	return strings.Join(keys, ", ")
+}
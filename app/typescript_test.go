@@ -0,0 +1,455 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateTsModels(t *testing.T) {
+	var err error
+
+	tsDir := filepath.Join(t.TempDir(), "web")
+
+	if err = GenerateTsModels(nil, PostgresDriver, "", "model", "gen.ts", GenerateConfig{}); err == nil {
+		t.Fatalf("should have error\n")
+	}
+
+	tables := []Table{
+		{
+			Name:    "user_profile",
+			Comment: "Person using the app",
+			Columns: []Column{
+				{Name: "id", DataType: "int4"},
+				{Name: "name", DataType: "varchar", Nullable: true, Comment: "Full name\nas entered on sign up"},
+				{Name: "balance", DataType: "int8"},
+				{Name: "active", DataType: "bool"},
+				{Name: "created_at", DataType: "timestamptz"},
+				{Name: "password_hash", DataType: "text"},
+			},
+		},
+		{
+			Name: "phone",
+			Columns: []Column{
+				{Name: "id", DataType: "int4"},
+				{Name: "user_profile_id", DataType: "int4", Comment: "Owner of the phone"},
+			},
+			ForeignKeys: []ForeignKey{
+				{ColumnName: "user_profile_id", ForeignTableName: "user_profile"},
+			},
+		},
+	}
+
+	cfg := GenerateConfig{
+		Model: ModelConfig{
+			TypeMap: TypeMap{{DBType: "timestamptz", GoType: "time.Time", TsType: "Date"}},
+			Tables: map[string]TableConfig{
+				"user_profile": {
+					Columns: map[string]ColumnConfig{
+						"password_hash": {JSONOmit: true},
+					},
+				},
+			},
+		},
+	}
+
+	if err = GenerateTsModels(tables, PostgresDriver, tsDir, "model", "gen.ts", cfg); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	content, err := os.ReadFile(filepath.Join(tsDir, "model.gen.ts"))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expected := "/** Person using the app */\n" +
+		"export interface UserProfile {\n" +
+		"\tid: number\n" +
+		"\t/**\n" +
+		"\t * Full name\n" +
+		"\t * as entered on sign up\n" +
+		"\t */\n" +
+		"\tname: string | null\n" +
+		"\tbalance: number\n" +
+		"\tactive: boolean\n" +
+		"\tcreatedAt: Date\n" +
+		"}\n\n" +
+		"export interface Phone {\n" +
+		"\tid: number\n" +
+		"\t/** Owner of the phone */\n" +
+		"\tuserProfileID: number\n" +
+		"\tuserProfile: UserProfile | null\n" +
+		"}\n\n"
+
+	if string(content) != expected {
+		t.Fatalf("expected ts output:\n%s\ngot:\n%s\n", expected, string(content))
+	}
+}
+
+func TestTsField(t *testing.T) {
+	tests := []struct {
+		nullable  bool
+		omitEmpty bool
+		nullMode  TsNullMode
+		expected  string
+	}{
+		{false, false, TsNullModeNull, "\tname: string\n"},
+		{true, false, TsNullModeNull, "\tname: string | null\n"},
+		{true, false, TsNullModeOptional, "\tname?: string\n"},
+		{true, false, TsNullModeOptionalNull, "\tname?: string | null\n"},
+		{false, true, TsNullModeNull, "\tname?: string\n"},
+		{false, false, TsNullModeOptional, "\tname: string\n"},
+	}
+
+	for _, test := range tests {
+		if field := tsField("name", "string", test.nullable, test.omitEmpty, test.nullMode); field != test.expected {
+			t.Fatalf("expected field '%s'; got '%s'\n", test.expected, field)
+		}
+	}
+}
+
+func TestGenerateTsModelsPerTable(t *testing.T) {
+	var err error
+
+	tsDir := filepath.Join(t.TempDir(), "web")
+	tables := []Table{
+		{
+			Name:    "user_profile",
+			Columns: []Column{{Name: "id", DataType: "int4"}},
+		},
+		{
+			Name: "phone",
+			Columns: []Column{
+				{Name: "id", DataType: "int4"},
+				{Name: "user_profile_id", DataType: "int4"},
+				{Name: "backup_user_profile_id", DataType: "int4", Nullable: true},
+			},
+			ForeignKeys: []ForeignKey{
+				{ColumnName: "user_profile_id", ForeignTableName: "user_profile"},
+				{ColumnName: "backup_user_profile_id", ForeignTableName: "user_profile"},
+			},
+		},
+	}
+
+	if err = GenerateTsModels(tables, PostgresDriver, tsDir, "", "gen.ts", GenerateConfig{PerTable: true}); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	expectedFiles := map[string]string{
+		"index.ts": "export * from './phone.gen'\n" +
+			"export * from './user_profile.gen'\n",
+		"user_profile.gen.ts": "export interface UserProfile {\n" +
+			"\tid: number\n" +
+			"}\n",
+		"phone.gen.ts": "import type { UserProfile } from './user_profile.gen'\n\n" +
+			"export interface Phone {\n" +
+			"\tid: number\n" +
+			"\tuserProfileID: number\n" +
+			"\tbackupUserProfileID: number | null\n" +
+			"\tuserProfile: UserProfile | null\n" +
+			"\tbackupUserProfile: UserProfile | null\n" +
+			"}\n",
+	}
+
+	for file, expected := range expectedFiles {
+		content, err := os.ReadFile(filepath.Join(tsDir, file))
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		if string(content) != expected {
+			t.Fatalf("expected %s:\n%s\ngot:\n%s\n", file, expected, string(content))
+		}
+	}
+
+	zodDir := filepath.Join(t.TempDir(), "web")
+
+	if err = GenerateTsModels(tables, PostgresDriver, zodDir, "", "gen.ts", GenerateConfig{PerTable: true, Mode: TsModeZod}); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	content, err := os.ReadFile(filepath.Join(zodDir, "phone.gen.ts"))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	header := "import { z } from 'zod'\n" +
+		"import { UserProfileSchema } from './user_profile.gen'\n\n"
+
+	if !strings.HasPrefix(string(content), header) {
+		t.Fatalf("expected phone.gen.ts to start with:\n%s\ngot:\n%s\n", header, string(content))
+	}
+}
+
+func TestTsTypeGoTypes(t *testing.T) {
+	table := Table{
+		Name: "invoice",
+		Columns: []Column{
+			{Name: "total", DataType: "numeric"},
+			{Name: "tax", DataType: "numeric"},
+			{Name: "fee", DataType: "money"},
+			{Name: "data", DataType: "jsonb"},
+			{Name: "meta", DataType: "json"},
+			{Name: "file", DataType: "bytea"},
+			{Name: "paid", DataType: "int4", Nullable: true},
+			{Name: "status", DataType: "invoice_status", EnumValues: []string{"open", "paid"}},
+			{Name: "deleted_at", DataType: "timestamptz", Nullable: true},
+		},
+	}
+	cfg := GenerateConfig{
+		Model: ModelConfig{
+			Driver: PostgresDriver,
+			TypeMap: TypeMap{
+				{DBType: "money", GoType: "decimal.Decimal", TsType: "Decimal"},
+				{DBType: "json", GoType: "datatypes.JSON"},
+			},
+			Tables: map[string]TableConfig{
+				"invoice": {
+					Columns: map[string]ColumnConfig{
+						"tax": {GoType: "decimal.Decimal"},
+					},
+				},
+			},
+			Nullable: NullableConfig{Strategy: NullableSQL},
+		},
+	}
+
+	// Types follow the json of the go type every column is generated with
+	expected := map[string]string{
+		"total":      "number",
+		"tax":        "Decimal",
+		"fee":        "Decimal",
+		"data":       "string",
+		"meta":       "unknown",
+		"file":       "string",
+		"paid":       "unknown",
+		"status":     "'open' | 'paid'",
+		"deleted_at": "string",
+	}
+
+	for _, col := range table.Columns {
+		if tsType := tsType(table, col, cfg); tsType != expected[col.Name] {
+			t.Fatalf("expected ts type '%s' for column '%s'; got '%s'\n", expected[col.Name], col.Name, tsType)
+		}
+	}
+
+	mysqlTable := Table{
+		Name: "invoice",
+		Columns: []Column{
+			{Name: "total", DataType: "decimal"},
+			{Name: "count", DataType: "numeric"},
+			{Name: "active", DataType: "boolean"},
+		},
+	}
+	cfg = GenerateConfig{Model: ModelConfig{Driver: MysqlDriver}}
+	expected = map[string]string{
+		"total":  "number",
+		"count":  "number",
+		"active": "boolean",
+	}
+
+	for _, col := range mysqlTable.Columns {
+		if tsType := tsType(mysqlTable, col, cfg); tsType != expected[col.Name] {
+			t.Fatalf("expected ts type '%s' for column '%s'; got '%s'\n", expected[col.Name], col.Name, tsType)
+		}
+	}
+}
+
+func TestGenerateTsModelsTypes(t *testing.T) {
+	var err error
+
+	if err = (TsTypes{Bigint: "long"}).Validate(); !errors.Is(err, ErrInvalidTsTypes) {
+		t.Fatalf("should have error %v; got %v\n", ErrInvalidTsTypes, err)
+	}
+
+	if err = (TsTypes{JSONImport: "./json"}).Validate(); !errors.Is(err, ErrInvalidTsTypes) {
+		t.Fatalf("should have error %v; got %v\n", ErrInvalidTsTypes, err)
+	}
+
+	tsDir := filepath.Join(t.TempDir(), "web")
+	tables := []Table{
+		{
+			Name: "invoice",
+			Columns: []Column{
+				{Name: "id", DataType: "int8"},
+				{Name: "total", DataType: "numeric"},
+				{Name: "data", DataType: "jsonb"},
+				{Name: "issued_at", DataType: "timestamptz", Nullable: true},
+			},
+		},
+	}
+	cfg := GenerateConfig{
+		Types: TsTypes{
+			Timestamp:  tsISODateString,
+			Bigint:     "bigint",
+			Numeric:    "number",
+			JSON:       "JsonValue",
+			JSONImport: "./json",
+		},
+	}
+
+	if err = GenerateTsModels(tables, PostgresDriver, tsDir, "model", "gen.ts", cfg); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	content, err := os.ReadFile(filepath.Join(tsDir, "model.gen.ts"))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expected := "import type { JsonValue } from './json'\n\n" +
+		"export type ISODateString = string & { readonly __brand: 'ISODateString' }\n\n" +
+		"export interface Invoice {\n" +
+		"\tid: bigint\n" +
+		"\ttotal: number\n" +
+		"\tdata: JsonValue\n" +
+		"\tissuedAt: ISODateString | null\n" +
+		"}\n\n"
+
+	if string(content) != expected {
+		t.Fatalf("expected ts output:\n%s\ngot:\n%s\n", expected, string(content))
+	}
+
+	cfg.PerTable = true
+	cfg.Mode = TsModeZod
+
+	if err = GenerateTsModels(tables, PostgresDriver, tsDir, "", "gen.ts", cfg); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	expectedFiles := map[string]string{
+		"index.ts": "export * from './types'\n" +
+			"export * from './invoice.gen'\n",
+		"types.ts": "import { z } from 'zod'\n\n" +
+			"export const ISODateStringSchema = z.string().datetime({ offset: true }).brand('ISODateString')\n\n" +
+			"export type ISODateString = z.infer<typeof ISODateStringSchema>\n",
+		"invoice.gen.ts": "import { z } from 'zod'\n" +
+			"import type { JsonValue } from './json'\n" +
+			"import { ISODateStringSchema } from './types'\n\n" +
+			"export const InvoiceSchema = z.object({\n" +
+			"\tid: z.coerce.bigint(),\n" +
+			"\ttotal: z.number(),\n" +
+			"\tdata: z.custom<JsonValue>(),\n" +
+			"\tissuedAt: ISODateStringSchema.nullable(),\n" +
+			"})\n\n" +
+			"export type Invoice = z.infer<typeof InvoiceSchema>\n",
+	}
+
+	for name, expected := range expectedFiles {
+		content, err := os.ReadFile(filepath.Join(tsDir, name))
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		if string(content) != expected {
+			t.Fatalf("expected %s:\n%s\ngot:\n%s\n", name, expected, string(content))
+		}
+	}
+}
+
+func TestGenerateTsModelsBrandedIDs(t *testing.T) {
+	var err error
+
+	tsDir := filepath.Join(t.TempDir(), "web")
+	tables := []Table{
+		{
+			Name: "phone",
+			Columns: []Column{
+				{Name: "id", DataType: "uuid", PrimaryKey: true},
+				{Name: "user_profile_id", DataType: "int4", Nullable: true},
+			},
+			ForeignKeys: []ForeignKey{
+				{ColumnName: "user_profile_id", ForeignTableName: "user_profile"},
+			},
+		},
+		{
+			Name:    "user_profile",
+			Columns: []Column{{Name: "id", DataType: "int4", PrimaryKey: true}},
+		},
+		{
+			Name: "user_role",
+			Columns: []Column{
+				{Name: "user_profile_id", DataType: "int4", PrimaryKey: true},
+				{Name: "role", DataType: "text", PrimaryKey: true},
+			},
+			ForeignKeys: []ForeignKey{
+				{ColumnName: "user_profile_id", ForeignTableName: "user_profile"},
+			},
+		},
+	}
+
+	cfg := GenerateConfig{BrandedIDs: true}
+
+	if err = GenerateTsModels(tables, PostgresDriver, tsDir, "model", "gen.ts", cfg); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	content, err := os.ReadFile(filepath.Join(tsDir, "model.gen.ts"))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expected := "export type PhoneId = string & { readonly __brand: 'PhoneId' }\n\n" +
+		"export type UserProfileId = number & { readonly __brand: 'UserProfileId' }\n\n" +
+		"export interface Phone {\n" +
+		"\tid: PhoneId\n" +
+		"\tuserProfileID: UserProfileId | null\n" +
+		"\tuserProfile: UserProfile | null\n" +
+		"}\n\n" +
+		"export interface UserProfile {\n" +
+		"\tid: UserProfileId\n" +
+		"}\n\n" +
+		"export interface UserRole {\n" +
+		"\tuserProfileID: UserProfileId\n" +
+		"\trole: string\n" +
+		"\tuserProfile: UserProfile | null\n" +
+		"}\n\n"
+
+	if string(content) != expected {
+		t.Fatalf("expected ts output:\n%s\ngot:\n%s\n", expected, string(content))
+	}
+
+	cfg.PerTable = true
+	cfg.Mode = TsModeZod
+
+	if err = GenerateTsModels(tables, PostgresDriver, tsDir, "", "gen.ts", cfg); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	expectedFiles := map[string]string{
+		"types.ts": "import { z } from 'zod'\n\n" +
+			"export const PhoneIdSchema = z.string().uuid().brand('PhoneId')\n\n" +
+			"export type PhoneId = z.infer<typeof PhoneIdSchema>\n\n" +
+			"export const UserProfileIdSchema = z.number().int().brand('UserProfileId')\n\n" +
+			"export type UserProfileId = z.infer<typeof UserProfileIdSchema>\n",
+		"phone.gen.ts": "import { z } from 'zod'\n" +
+			"import { PhoneIdSchema, UserProfileIdSchema } from './types'\n" +
+			"import { UserProfileSchema } from './user_profile.gen'\n\n" +
+			"export const PhoneSchema = z.object({\n" +
+			"\tid: PhoneIdSchema,\n" +
+			"\tuserProfileID: UserProfileIdSchema.nullable(),\n" +
+			"\tuserProfile: z.lazy(() => UserProfileSchema).nullable(),\n" +
+			"})\n\n" +
+			"export type Phone = z.infer<typeof PhoneSchema>\n",
+	}
+
+	for name, expected := range expectedFiles {
+		content, err := os.ReadFile(filepath.Join(tsDir, name))
+
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		if string(content) != expected {
+			t.Fatalf("expected %s:\n%s\ngot:\n%s\n", name, expected, string(content))
+		}
+	}
+}
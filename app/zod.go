@@ -0,0 +1,197 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+// zodShapeTypes is the zod schema of every json shape, matching tsShapeTypes
+var zodShapeTypes = map[jsonShape]string{
+	jsonAny:    "z.unknown()",
+	jsonString: "z.string()",
+	jsonInt:    "z.number().int()",
+	jsonBigint: "z.number().int()",
+	jsonFloat:  "z.number()",
+	jsonBool:   "z.boolean()",
+	jsonTime:   "z.string().datetime({ offset: true })",
+	jsonBytes:  "z.string()",
+}
+
+// zodCheckMethods maps check constraint operators to zod number methods
+var zodCheckMethods = map[string]string{
+	">":  "gt",
+	">=": "gte",
+	"<":  "lt",
+	"<=": "lte",
+}
+
+// zodSchema returns the zod schema of table along with the type inferred from it
+//
+// Relations reference the schema of the related table lazily so schemas can be
+// declared in any order.  The type of a schema whose relations lead back to it
+// can't be inferred so it is declared as an interface the schema is annotated
+// with instead, accepting any input as branded and coerced fields parse values
+// of other types
+func zodSchema(table Table, cfg GenerateConfig) string {
+	var b strings.Builder
+
+	structName := cfg.Model.structName(table.Name)
+	cyclic := cfg.cyclicTables[table.Name]
+
+	if cyclic {
+		b.WriteString(tsInterface(table, cfg))
+		b.WriteString(fmt.Sprintf(
+			"export const %sSchema: z.ZodType<%s, z.ZodTypeDef, unknown> = z.object({\n",
+			structName,
+			structName,
+		))
+	} else {
+		b.WriteString(docBlock(table.Comment, ""))
+		b.WriteString(fmt.Sprintf("export const %sSchema = z.object({\n", structName))
+	}
+
+	for _, col := range table.Columns {
+		name, omitEmpty, ok := cfg.Model.jsonField(table.Name, col)
+
+		// Fields hidden from json are never part of the api contract
+		if !ok {
+			continue
+		}
+
+		b.WriteString(docBlock(col.Comment, "\t"))
+		schema := zodType(table, col, cfg)
+
+		if id := cfg.idType(table, col); id != "" {
+			schema = id + "Schema"
+		}
+
+		b.WriteString(zodField(name, schema, col.Nullable, omitEmpty, cfg.NullMode))
+	}
+
+	for _, fk := range table.ForeignKeys {
+		name, omitEmpty := cfg.Model.relationJSONField(table, fk)
+		schema := fmt.Sprintf("z.lazy(() => %sSchema)", cfg.Model.structName(fk.ForeignTableName))
+
+		b.WriteString(zodField(name, schema, true, omitEmpty, cfg.NullMode))
+	}
+
+	b.WriteString("})\n\n")
+
+	if !cyclic {
+		b.WriteString(fmt.Sprintf("export type %s = z.infer<typeof %sSchema>\n\n", structName, structName))
+	}
+
+	return b.String()
+}
+
+// zodField returns the property of a field within z.object, following the same
+// rules as tsField
+func zodField(name, schema string, nullable, omitEmpty bool, nullMode TsNullMode) string {
+	optional := omitEmpty
+
+	if nullable {
+		switch nullMode {
+		case TsNullModeOptional:
+			optional = true
+		case TsNullModeOptionalNull:
+			optional = true
+			schema += ".nullable()"
+		default:
+			schema += ".nullable()"
+		}
+	}
+
+	if optional {
+		schema += ".optional()"
+	}
+
+	return fmt.Sprintf("\t%s: %s,\n", name, schema)
+}
+
+// zodType returns the zod schema of col of table without nullability
+//
+// Columns with a typescript type from the type map can't be validated any
+// further so they are accepted as is
+func zodType(table Table, col Column, cfg GenerateConfig) string {
+	if t, ok := cfg.tsMappedType(table, col); ok {
+		return fmt.Sprintf("z.custom<%s>()", t)
+	}
+
+	shape := cfg.Model.jsonShape(table, col)
+
+	if cfg.Model.enumColumn(table, col) {
+		values := make([]string, 0, len(col.EnumValues))
+
+		for _, v := range col.EnumValues {
+			values = append(values, tsString(v))
+		}
+
+		return fmt.Sprintf("z.enum([%s])", strings.Join(values, ", "))
+	}
+
+	kind := col.kind()
+
+	if schema := zodOptionType(kind, shape, cfg.Types); schema != "" {
+		return schema
+	}
+
+	schema := zodShapeTypes[shape]
+
+	switch {
+	case shape == jsonString && kind == uuidKind:
+		schema += ".uuid()"
+	case shape == jsonString && kind == stringKind && col.MaxLength > 0:
+		schema += fmt.Sprintf(".max(%d)", col.MaxLength)
+	case shape == jsonInt || shape == jsonBigint || shape == jsonFloat:
+		for _, check := range col.Checks {
+			if method, ok := zodCheckMethods[check.Operator]; ok {
+				schema += fmt.Sprintf(".%s(%s)", method, check.Value)
+			}
+		}
+	}
+
+	return schema
+}
+
+// tsString returns value as a single quoted typescript string literal
+func tsString(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`, "\n", `\n`).Replace(value) + "'"
+}
+
+// zodOptionType returns the zod schema of the type types picks for a column of
+// kind encoded to shape, empty if the schema of the shape applies
+func zodOptionType(kind dataKind, shape jsonShape, types TsTypes) string {
+	switch t := types.columnType(kind, shape); {
+	case shape == jsonTime:
+		switch t {
+		case "Date":
+			return "z.coerce.date()"
+		case tsISODateString:
+			return tsISODateString + "Schema"
+		}
+	case shape == jsonBigint:
+		switch t {
+		case "bigint":
+			return "z.coerce.bigint()"
+		case "string":
+			return "z.string()"
+		}
+	case kind == decimalKind:
+		switch t {
+		case "number":
+			return "z.number()"
+		case "string":
+			return "z.string()"
+		}
+	case kind == jsonKind:
+		switch t {
+		case "":
+		case "unknown":
+			return "z.unknown()"
+		default:
+			return fmt.Sprintf("z.custom<%s>()", t)
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,145 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerateTsModelsZod(t *testing.T) {
+	var err error
+
+	tsDir := filepath.Join(t.TempDir(), "web")
+	tables := []Table{
+		{
+			Name: "user_profile",
+			Columns: []Column{
+				{Name: "id", DataType: "uuid"},
+				{Name: "name", DataType: "varchar", MaxLength: 100, Comment: "Full name"},
+				{Name: "age", DataType: "int4", Nullable: true, Checks: []Check{{Operator: ">=", Value: "18"}}},
+				{Name: "status", DataType: "user_status", EnumValues: []string{"active", "on hold"}},
+				{Name: "created_at", DataType: "timestamptz"},
+			},
+		},
+		{
+			Name: "phone",
+			Columns: []Column{
+				{Name: "id", DataType: "int8"},
+				{Name: "user_profile_id", DataType: "uuid"},
+			},
+			ForeignKeys: []ForeignKey{
+				{ColumnName: "user_profile_id", ForeignTableName: "user_profile"},
+			},
+		},
+	}
+
+	if err = GenerateTsModels(tables, PostgresDriver, tsDir, "model", "gen.ts", GenerateConfig{
+		Mode:     TsModeZod,
+		NullMode: TsNullModeOptionalNull,
+	}); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	content, err := os.ReadFile(filepath.Join(tsDir, "model.gen.ts"))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	expected := "import { z } from 'zod'\n\n" +
+		"export const UserProfileSchema = z.object({\n" +
+		"\tid: z.string().uuid(),\n" +
+		"\t/** Full name */\n" +
+		"\tname: z.string().max(100),\n" +
+		"\tage: z.number().int().gte(18).nullable().optional(),\n" +
+		"\tstatus: z.enum(['active', 'on hold']),\n" +
+		"\tcreatedAt: z.string().datetime({ offset: true }),\n" +
+		"})\n\n" +
+		"export type UserProfile = z.infer<typeof UserProfileSchema>\n\n" +
+		"export const PhoneSchema = z.object({\n" +
+		"\tid: z.number().int(),\n" +
+		"\tuserProfileID: z.string().uuid(),\n" +
+		"\tuserProfile: z.lazy(() => UserProfileSchema).nullable().optional(),\n" +
+		"})\n\n" +
+		"export type Phone = z.infer<typeof PhoneSchema>\n\n"
+
+	if string(content) != expected {
+		t.Fatalf("expected zod output:\n%s\ngot:\n%s\n", expected, string(content))
+	}
+}
+
+func TestGenerateTsModelsZodCycles(t *testing.T) {
+	var err error
+
+	tsDir := filepath.Join(t.TempDir(), "web")
+	tables := []Table{
+		{
+			Name:    "employee",
+			Comment: "Staff member",
+			Columns: []Column{
+				{Name: "id", DataType: "int4"},
+				{Name: "manager_id", DataType: "int4", Nullable: true},
+			},
+			ForeignKeys: []ForeignKey{
+				{ColumnName: "manager_id", ForeignTableName: "employee"},
+			},
+		},
+		{
+			Name: "badge",
+			Columns: []Column{
+				{Name: "id", DataType: "int4"},
+				{Name: "employee_id", DataType: "int4"},
+			},
+			ForeignKeys: []ForeignKey{
+				{ColumnName: "employee_id", ForeignTableName: "employee"},
+			},
+		},
+	}
+
+	if err = GenerateTsModels(tables, PostgresDriver, tsDir, "model", "gen.ts", GenerateConfig{
+		Mode: TsModeZod,
+	}); err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	content, err := os.ReadFile(filepath.Join(tsDir, "model.gen.ts"))
+
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// Only the schema referencing itself is annotated, the one referencing it can
+	// still be inferred
+	expected := "import { z } from 'zod'\n\n" +
+		"/** Staff member */\n" +
+		"export interface Employee {\n" +
+		"\tid: number\n" +
+		"\tmanagerID: number | null\n" +
+		"\tmanager: Employee | null\n" +
+		"}\n\n" +
+		"export const EmployeeSchema: z.ZodType<Employee, z.ZodTypeDef, unknown> = z.object({\n" +
+		"\tid: z.number().int(),\n" +
+		"\tmanagerID: z.number().int().nullable(),\n" +
+		"\tmanager: z.lazy(() => EmployeeSchema).nullable(),\n" +
+		"})\n\n" +
+		"export const BadgeSchema = z.object({\n" +
+		"\tid: z.number().int(),\n" +
+		"\temployeeID: z.number().int(),\n" +
+		"\temployee: z.lazy(() => EmployeeSchema).nullable(),\n" +
+		"})\n\n" +
+		"export type Badge = z.infer<typeof BadgeSchema>\n\n"
+
+	if string(content) != expected {
+		t.Fatalf("expected zod output:\n%s\ngot:\n%s\n", expected, string(content))
+	}
+
+	cyclic := tsCyclicTables([]Table{
+		{Name: "a", ForeignKeys: []ForeignKey{{ColumnName: "b_id", ForeignTableName: "b"}}},
+		{Name: "b", ForeignKeys: []ForeignKey{{ColumnName: "a_id", ForeignTableName: "a"}}},
+		{Name: "c", ForeignKeys: []ForeignKey{{ColumnName: "a_id", ForeignTableName: "a"}}},
+	})
+
+	if !cyclic["a"] || !cyclic["b"] || cyclic["c"] {
+		t.Fatalf("only tables a and b should be cyclic; got %v\n", cyclic)
+	}
+}
@@ -0,0 +1,408 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/TravisS25/model-gen/app"
+	"github.com/pkg/errors"
+	"github.com/stretchr/objx"
+)
+
+var (
+	errInvalidTypeMap  = errors.New("model-gen: type_map key must be a list of dictionaries")
+	errInvalidTables   = errors.New("model-gen: tables key must be a dictionary of table names")
+	errInvalidTags     = errors.New("model-gen: tags key must be a list of dictionaries")
+	errInvalidNullable = errors.New("model-gen: nullable key must be a dictionary")
+	errInvalidTsTypes  = errors.New("model-gen: ts_types key must be a dictionary")
+)
+
+// legacyConvertTypes holds the database types each of the deprecated convert
+// settings applies to
+var legacyConvertTypes = map[string][]string{
+	"convert_timestamp": {"timestamptz", "timestamp", "datetime"},
+	"convert_date":      {"date"},
+	"convert_bigint":    {"int8", "bigint"},
+	"convert_uuid":      {"uuid"},
+}
+
+// typeMapFromConfig parses the type_map key of the config file
+//
+// Each entry maps a database type to a go type, import path and ts type.  The
+// database type can either be a single name that applies to every driver or a
+// dictionary of driver specific names, in which case an entry is created per driver
+//
+//	type_map:
+//	  - db_type:
+//	      postgres: numeric
+//	      mysql: decimal
+//	    nullable: false
+//	    column_pattern: _amount$
+//	    go_type: decimal.Decimal
+//	    go_import: github.com/shopspring/decimal
+//	    ts_type: string
+func typeMapFromConfig(value interface{}) (app.TypeMap, error) {
+	var typeMap app.TypeMap
+
+	if value == nil {
+		return nil, nil
+	}
+
+	entries, ok := value.([]interface{})
+
+	if !ok {
+		return nil, errors.WithStack(errInvalidTypeMap)
+	}
+
+	for _, entry := range entries {
+		entryMap, ok := entry.(map[string]interface{})
+
+		if !ok {
+			return nil, errors.WithStack(errInvalidTypeMap)
+		}
+
+		entryObjx := objx.New(entryMap)
+		mapping := app.TypeMapping{
+			ColumnPattern: entryObjx.Get("column_pattern").Str(),
+			GoType:        entryObjx.Get("go_type").Str(),
+			GoImport:      entryObjx.Get("go_import").Str(),
+			TsType:        entryObjx.Get("ts_type").Str(),
+		}
+
+		if nullable := entryObjx.Get("nullable"); nullable.IsBool() {
+			n := nullable.Bool()
+			mapping.Nullable = &n
+		}
+
+		dbType := entryObjx.Get("db_type")
+
+		switch {
+		case dbType.IsStr():
+			mapping.DBType = dbType.Str()
+			typeMap = append(typeMap, mapping)
+		case dbType.IsObjxMap() || dbType.IsMSI():
+			for driver, driverType := range dbType.ObjxMap() {
+				if _, ok = dbDriverMap[app.DBDriver(driver)]; !ok {
+					return nil, errors.WithStack(
+						fmt.Errorf("%w: unknown driver '%s' in db_type", errInvalidTypeMap, driver),
+					)
+				}
+
+				driverMapping := mapping
+				driverMapping.Driver = app.DBDriver(driver)
+				driverMapping.DBType = fmt.Sprint(driverType)
+				typeMap = append(typeMap, driverMapping)
+			}
+		default:
+			return nil, errors.WithStack(
+				fmt.Errorf("%w: db_type must be a string or dictionary of drivers", errInvalidTypeMap),
+			)
+		}
+	}
+
+	if err := typeMap.Validate(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return typeMap, nil
+}
+
+// legacyTypeMap converts the deprecated convert settings into type mappings
+func legacyTypeMap(convert map[string]string) app.TypeMap {
+	var typeMap app.TypeMap
+
+	for _, key := range []string{"convert_timestamp", "convert_date", "convert_bigint", "convert_uuid"} {
+		if convert[key] == "" {
+			continue
+		}
+
+		for _, dbType := range legacyConvertTypes[key] {
+			typeMap = append(typeMap, app.TypeMapping{
+				DBType: dbType,
+				GoType: convert[key],
+			})
+		}
+	}
+
+	return typeMap
+}
+
+// tablesFromConfig parses the tables key of the config file
+//
+//	tables:
+//	  tbl_usr:
+//	    struct_name: User
+//	    columns:
+//	      settings:
+//	        go_type: datatypes.JSONMap
+//	        go_import: gorm.io/datatypes
+//	      email:
+//	        field_name: EmailAddress
+//	        json_name: email
+//	        tags:
+//	          validate: email
+//	      password_hash:
+//	        json_omit: true
+func tablesFromConfig(value interface{}) (map[string]app.TableConfig, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	tablesMap, ok := value.(map[string]interface{})
+
+	if !ok {
+		return nil, errors.WithStack(errInvalidTables)
+	}
+
+	tables := make(map[string]app.TableConfig, len(tablesMap))
+
+	for tableName, tableValue := range tablesMap {
+		tableMap, ok := tableValue.(map[string]interface{})
+
+		if !ok {
+			return nil, errors.WithStack(fmt.Errorf("%w: table '%s' must be a dictionary", errInvalidTables, tableName))
+		}
+
+		tableObjx := objx.New(tableMap)
+		table := app.TableConfig{
+			StructName: tableObjx.Get("struct_name").Str(),
+			Columns:    make(map[string]app.ColumnConfig),
+		}
+
+		columns := tableObjx.Get("columns")
+
+		if !columns.IsNil() && !columns.IsObjxMap() && !columns.IsMSI() {
+			return nil, errors.WithStack(
+				fmt.Errorf("%w: columns of table '%s' must be a dictionary", errInvalidTables, tableName),
+			)
+		}
+
+		for columnName, columnValue := range columns.ObjxMap() {
+			columnMap, ok := columnValue.(map[string]interface{})
+
+			if !ok {
+				return nil, errors.WithStack(
+					fmt.Errorf("%w: column '%s.%s' must be a dictionary", errInvalidTables, tableName, columnName),
+				)
+			}
+
+			columnObjx := objx.New(columnMap)
+			column := app.ColumnConfig{
+				GoType:    columnObjx.Get("go_type").Str(),
+				GoImport:  columnObjx.Get("go_import").Str(),
+				FieldName: columnObjx.Get("field_name").Str(),
+				JSONName:  columnObjx.Get("json_name").Str(),
+				JSONOmit:  columnObjx.Get("json_omit").Bool(),
+				Tags:      make(map[string]string),
+			}
+
+			for tagName, tagValue := range columnObjx.Get("tags").ObjxMap() {
+				column.Tags[tagName] = fmt.Sprint(tagValue)
+			}
+
+			table.Columns[columnName] = column
+		}
+
+		tables[tableName] = table
+	}
+
+	return tables, nil
+}
+
+// graphqlTablesFromConfig parses the graphql key of every table of the tables
+// key of the config file
+//
+//	tables:
+//	  tbl_usr:
+//	    graphql:
+//	      type_name: Account
+//	      implements: [Node]
+//	      omit_reverse_relations: true
+//	  audit_log:
+//	    graphql:
+//	      omit: true
+func graphqlTablesFromConfig(value interface{}) (map[string]app.GraphQLTableConfig, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	tablesMap, ok := value.(map[string]interface{})
+
+	if !ok {
+		return nil, errors.WithStack(errInvalidTables)
+	}
+
+	tables := make(map[string]app.GraphQLTableConfig, len(tablesMap))
+
+	for tableName, tableValue := range tablesMap {
+		tableMap, ok := tableValue.(map[string]interface{})
+
+		if !ok {
+			return nil, errors.WithStack(fmt.Errorf("%w: table '%s' must be a dictionary", errInvalidTables, tableName))
+		}
+
+		tableObjx := objx.New(tableMap)
+
+		if tableObjx.Get("graphql").IsNil() {
+			continue
+		}
+
+		table := app.GraphQLTableConfig{
+			Omit:                 tableObjx.Get("graphql.omit").Bool(),
+			TypeName:             tableObjx.Get("graphql.type_name").Str(),
+			OmitReverseRelations: tableObjx.Get("graphql.omit_reverse_relations").Bool(),
+		}
+
+		for _, implements := range tableObjx.Get("graphql.implements").InterSlice() {
+			table.Implements = append(table.Implements, fmt.Sprint(implements))
+		}
+
+		tables[tableName] = table
+	}
+
+	return tables, nil
+}
+
+// tagsFromConfig parses the tags key of the config file
+//
+// Each entry generates a struct tag for every column and relation field
+//
+//	tags:
+//	  - name: db
+//	    case: as-is
+//	  - name: json
+//	    case: snake
+//	    omitempty: nullable
+//	  - name: bson
+//	    case: snake
+//	    omitempty: always
+//	  - name: validate
+func tagsFromConfig(value interface{}) ([]app.TagGenerator, error) {
+	var tags []app.TagGenerator
+
+	if value == nil {
+		return nil, nil
+	}
+
+	entries, ok := value.([]interface{})
+
+	if !ok {
+		return nil, errors.WithStack(errInvalidTags)
+	}
+
+	for _, entry := range entries {
+		entryMap, ok := entry.(map[string]interface{})
+
+		if !ok {
+			return nil, errors.WithStack(errInvalidTags)
+		}
+
+		entryObjx := objx.New(entryMap)
+		tag := app.TagGenerator{
+			Name:      entryObjx.Get("name").Str(),
+			Case:      app.NamingCase(entryObjx.Get("case").Str(string(app.AsIsCase))),
+			OmitEmpty: app.OmitEmptyPolicy(entryObjx.Get("omitempty").Str(string(app.OmitEmptyNever))),
+		}
+
+		if err := tag.Validate(); err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+// nullableFromConfig parses the nullable key of the config file
+//
+// The strategy applies to every type without an entry in types and defaults to
+// pointers if field_nullable is set and plain types otherwise
+//
+//	nullable:
+//	  strategy: sql
+//	  generic_type: github.com/acme/null.Value
+//	  types:
+//	    time.Time: pointer
+//	    string: generic
+func nullableFromConfig(value interface{}, fieldNullable bool) (app.NullableConfig, error) {
+	nullable := app.NullableConfig{
+		Strategy: app.NullableNone,
+		Types:    make(map[string]app.NullableStrategy),
+	}
+
+	if fieldNullable {
+		nullable.Strategy = app.NullablePointer
+	}
+
+	if value == nil {
+		return nullable, nil
+	}
+
+	nullableMap, ok := value.(map[string]interface{})
+
+	if !ok {
+		return app.NullableConfig{}, errors.WithStack(errInvalidNullable)
+	}
+
+	nullableObjx := objx.New(nullableMap)
+	nullable.GenericType = nullableObjx.Get("generic_type").Str()
+
+	if strategy := nullableObjx.Get("strategy").Str(); strategy != "" {
+		nullable.Strategy = app.NullableStrategy(strategy)
+	}
+
+	types := nullableObjx.Get("types")
+
+	if !types.IsNil() && !types.IsObjxMap() && !types.IsMSI() {
+		return app.NullableConfig{}, errors.WithStack(
+			fmt.Errorf("%w: types must be a dictionary of go types", errInvalidNullable),
+		)
+	}
+
+	for goType, strategy := range types.ObjxMap() {
+		nullable.Types[goType] = app.NullableStrategy(fmt.Sprint(strategy))
+	}
+
+	if err := nullable.Validate(); err != nil {
+		return app.NullableConfig{}, errors.WithStack(err)
+	}
+
+	return nullable, nil
+}
+
+// tsTypesFromConfig parses the ts_types key of the config file
+//
+// Options left out keep generating strings
+//
+//	ts_types:
+//	  timestamp: Date
+//	  bigint: bigint
+//	  numeric: number
+//	  json: JsonValue
+//	  json_import: ./json
+func tsTypesFromConfig(value interface{}) (app.TsTypes, error) {
+	if value == nil {
+		return app.TsTypes{}, nil
+	}
+
+	tsTypesMap, ok := value.(map[string]interface{})
+
+	if !ok {
+		return app.TsTypes{}, errors.WithStack(errInvalidTsTypes)
+	}
+
+	tsTypesObjx := objx.New(tsTypesMap)
+	tsTypes := app.TsTypes{
+		Timestamp:  tsTypesObjx.Get("timestamp").Str(),
+		Bigint:     tsTypesObjx.Get("bigint").Str(),
+		Numeric:    tsTypesObjx.Get("numeric").Str(),
+		JSON:       tsTypesObjx.Get("json").Str(),
+		JSONImport: tsTypesObjx.Get("json_import").Str(),
+	}
+
+	if err := tsTypes.Validate(); err != nil {
+		return app.TsTypes{}, errors.WithStack(err)
+	}
+
+	return tsTypes, nil
+}
@@ -0,0 +1,272 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/TravisS25/model-gen/app"
+)
+
+func TestTypeMapFromConfig(t *testing.T) {
+	var err error
+
+	if _, err = typeMapFromConfig("string"); !errors.Is(err, errInvalidTypeMap) {
+		t.Fatalf("should have error %v; got %v\n", errInvalidTypeMap, err)
+	}
+
+	if _, err = typeMapFromConfig([]interface{}{
+		map[string]interface{}{
+			"db_type": map[string]interface{}{"oracle": "number"},
+			"go_type": "int64",
+		},
+	}); !errors.Is(err, errInvalidTypeMap) {
+		t.Fatalf("should have error %v; got %v\n", errInvalidTypeMap, err)
+	}
+
+	if _, err = typeMapFromConfig([]interface{}{
+		map[string]interface{}{
+			"db_type": "uuid",
+		},
+	}); !errors.Is(err, app.ErrInvalidTypeMap) {
+		t.Fatalf("should have error %v; got %v\n", app.ErrInvalidTypeMap, err)
+	}
+
+	typeMap, err := typeMapFromConfig([]interface{}{
+		map[string]interface{}{
+			"db_type": map[string]interface{}{
+				"postgres": "numeric",
+				"mysql":    "decimal",
+			},
+			"nullable":       false,
+			"column_pattern": "_amount$",
+			"go_type":        "decimal.Decimal",
+			"go_import":      "github.com/shopspring/decimal",
+			"ts_type":        "string",
+		},
+	})
+
+	if err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	if len(typeMap) != 2 {
+		t.Fatalf("expected a mapping per driver; got %d\n", len(typeMap))
+	}
+
+	if m, ok, _ := typeMap.Lookup(app.MysqlDriver, "decimal", false, "total_amount"); !ok || m.GoType != "decimal.Decimal" {
+		t.Fatalf("expected mysql decimal to be mapped; got %v\n", m)
+	}
+
+	if _, ok, _ := typeMap.Lookup(app.MysqlDriver, "numeric", false, "total_amount"); ok {
+		t.Fatalf("postgres type name should not apply to mysql\n")
+	}
+
+	legacy := legacyTypeMap(map[string]string{"convert_bigint": "string"})
+
+	if m, ok, _ := legacy.Lookup(app.SqliteDriver, "bigint", true, "id"); !ok || m.GoType != "string" {
+		t.Fatalf("legacy convert_bigint should map bigint; got %v\n", m)
+	}
+}
+
+func TestTablesFromConfig(t *testing.T) {
+	var err error
+
+	if _, err = tablesFromConfig([]interface{}{}); !errors.Is(err, errInvalidTables) {
+		t.Fatalf("should have error %v; got %v\n", errInvalidTables, err)
+	}
+
+	if _, err = tablesFromConfig(map[string]interface{}{
+		"users": map[string]interface{}{"columns": "email"},
+	}); !errors.Is(err, errInvalidTables) {
+		t.Fatalf("should have error %v; got %v\n", errInvalidTables, err)
+	}
+
+	tables, err := tablesFromConfig(map[string]interface{}{
+		"tbl_usr": map[string]interface{}{
+			"struct_name": "User",
+			"columns": map[string]interface{}{
+				"settings": map[string]interface{}{
+					"go_type":   "datatypes.JSONMap",
+					"go_import": "gorm.io/datatypes",
+				},
+				"email": map[string]interface{}{
+					"tags": map[string]interface{}{"validate": "email"},
+				},
+				"password_hash": map[string]interface{}{
+					"json_omit": true,
+				},
+			},
+			"graphql": map[string]interface{}{
+				"type_name":              "Account",
+				"implements":             []interface{}{"Node"},
+				"omit_reverse_relations": true,
+			},
+		},
+		"audit_log": map[string]interface{}{
+			"graphql": map[string]interface{}{"omit": true},
+		},
+	})
+
+	if err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	table := tables["tbl_usr"]
+
+	if table.StructName != "User" {
+		t.Fatalf("expected struct name 'User'; got '%s'\n", table.StructName)
+	}
+	if table.Columns["email"].Tags["validate"] != "email" {
+		t.Fatalf("expected validate tag on email; got %v\n", table.Columns["email"].Tags)
+	}
+	if !table.Columns["password_hash"].JSONOmit {
+		t.Fatalf("password_hash should be omitted from json\n")
+	}
+
+	imports := app.ModelConfig{Tables: tables}.GoImports(app.PostgresDriver)
+
+	if len(imports) != 1 || imports[0] != "gorm.io/datatypes" {
+		t.Fatalf("expected datatypes import; got %v\n", imports)
+	}
+}
+
+func TestGraphQLTablesFromConfig(t *testing.T) {
+	var err error
+
+	if _, err = graphqlTablesFromConfig([]interface{}{}); !errors.Is(err, errInvalidTables) {
+		t.Fatalf("should have error %v; got %v\n", errInvalidTables, err)
+	}
+
+	tables, err := graphqlTablesFromConfig(map[string]interface{}{
+		"tbl_usr": map[string]interface{}{
+			"struct_name": "User",
+			"graphql": map[string]interface{}{
+				"type_name":              "Account",
+				"implements":             []interface{}{"Node"},
+				"omit_reverse_relations": true,
+			},
+		},
+		"audit_log": map[string]interface{}{
+			"graphql": map[string]interface{}{"omit": true},
+		},
+		"phone": map[string]interface{}{
+			"struct_name": "Phone",
+		},
+	})
+
+	if err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	table := tables["tbl_usr"]
+
+	if table.TypeName != "Account" || len(table.Implements) != 1 || !table.OmitReverseRelations {
+		t.Fatalf("expected graphql config; got %+v\n", table)
+	}
+	if !tables["audit_log"].Omit {
+		t.Fatalf("audit_log should be omitted from graphql\n")
+	}
+	if _, ok := tables["phone"]; ok {
+		t.Fatalf("tables without graphql config should be left out\n")
+	}
+}
+
+func TestTagsFromConfig(t *testing.T) {
+	var err error
+
+	if _, err = tagsFromConfig("json"); !errors.Is(err, errInvalidTags) {
+		t.Fatalf("should have error %v; got %v\n", errInvalidTags, err)
+	}
+
+	if _, err = tagsFromConfig([]interface{}{
+		map[string]interface{}{"name": "json", "case": "upper"},
+	}); !errors.Is(err, app.ErrInvalidTagGenerator) {
+		t.Fatalf("should have error %v; got %v\n", app.ErrInvalidTagGenerator, err)
+	}
+
+	tags, err := tagsFromConfig([]interface{}{
+		map[string]interface{}{"name": "db"},
+		map[string]interface{}{"name": "json", "case": "snake", "omitempty": "nullable"},
+	})
+
+	if err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	if len(tags) != 2 {
+		t.Fatalf("expected 2 tags; got %d\n", len(tags))
+	}
+
+	if tags[0].Case != app.AsIsCase || tags[0].OmitEmpty != app.OmitEmptyNever {
+		t.Fatalf("expected defaults for db tag; got %v\n", tags[0])
+	}
+
+	if tags[1].Case != app.SnakeCase || tags[1].OmitEmpty != app.OmitEmptyNullable {
+		t.Fatalf("expected snake case nullable omitempty json tag; got %v\n", tags[1])
+	}
+}
+
+func TestNullableFromConfig(t *testing.T) {
+	var err error
+
+	if _, err = nullableFromConfig("sql", false); !errors.Is(err, errInvalidNullable) {
+		t.Fatalf("should have error %v; got %v\n", errInvalidNullable, err)
+	}
+
+	if _, err = nullableFromConfig(map[string]interface{}{"strategy": "maybe"}, false); !errors.Is(err, app.ErrInvalidNullable) {
+		t.Fatalf("should have error %v; got %v\n", app.ErrInvalidNullable, err)
+	}
+
+	nullable, err := nullableFromConfig(nil, true)
+
+	if err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	if nullable.Strategy != app.NullablePointer {
+		t.Fatalf("strategy should default to pointer with field_nullable; got '%s'\n", nullable.Strategy)
+	}
+
+	nullable, err = nullableFromConfig(map[string]interface{}{
+		"strategy":     "sql",
+		"generic_type": "github.com/acme/null.Value",
+		"types": map[string]interface{}{
+			"time.time": "generic",
+		},
+	}, false)
+
+	if err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	if nullable.Strategy != app.NullableSQL || nullable.Types["time.time"] != app.NullableGeneric {
+		t.Fatalf("unexpected nullable config %+v\n", nullable)
+	}
+}
+
+func TestTsTypesFromConfig(t *testing.T) {
+	var err error
+
+	if _, err = tsTypesFromConfig("Date"); !errors.Is(err, errInvalidTsTypes) {
+		t.Fatalf("should have error %v; got %v\n", errInvalidTsTypes, err)
+	}
+
+	if _, err = tsTypesFromConfig(map[string]interface{}{"timestamp": "Moment"}); !errors.Is(err, app.ErrInvalidTsTypes) {
+		t.Fatalf("should have error %v; got %v\n", app.ErrInvalidTsTypes, err)
+	}
+
+	tsTypes, err := tsTypesFromConfig(map[string]interface{}{
+		"timestamp":   "ISODateString",
+		"bigint":      "bigint",
+		"json":        "JsonValue",
+		"json_import": "./json",
+	})
+
+	if err != nil {
+		t.Fatalf("should not have error; %s\n", err.Error())
+	}
+
+	if tsTypes.Timestamp != "ISODateString" || tsTypes.Bigint != "bigint" || tsTypes.JSONImport != "./json" {
+		t.Fatalf("unexpected ts types %+v\n", tsTypes)
+	}
+}
@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/TravisS25/model-gen/app"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/objx"
+)
+
+var (
+	errInvalidDiagramFormat = errors.New("model-gen: must choose valid --format.  Options are 'mermaid', 'dot', 'plantuml', 'dbml'")
+)
+
+var diagramCmdCfg = diagramCmdConfig{
+	Format: flagName{
+		LongHand:  "format",
+		ShortHand: "f",
+	},
+	Tables: flagName{
+		LongHand: "tables",
+	},
+	ExcludeTables: flagName{
+		LongHand: "exclude-tables",
+	},
+	Schemas: flagName{
+		LongHand: "schemas",
+	},
+	OutFile: flagName{
+		LongHand:  "out",
+		ShortHand: "o",
+	},
+}
+
+var diagramFormatMap = map[app.DiagramFormat]bool{
+	app.MermaidDiagramFormat:  true,
+	app.DotDiagramFormat:      true,
+	app.PlantUMLDiagramFormat: true,
+	app.DBMLDiagramFormat:     true,
+}
+
+type diagramCmdConfig struct {
+	Format        flagName
+	Tables        flagName
+	ExcludeTables flagName
+	Schemas       flagName
+	OutFile       flagName
+}
+
+// diagramCmd renders the tables of the database as an entity relationship diagram
+var diagramCmd = &cobra.Command{
+	Use:   "diagram",
+	Short: "Render the database tables and their relationships as an ER diagram",
+	Long: `Render the introspected tables, their columns and the foreign keys between
+them as an entity relationship diagram in mermaid, graphviz dot, plantuml or dbml.
+
+Settings are read from the diagram_cmd key of the config file while the database
+connection is read from root_cmd.  Tables are grouped by schema when more than
+one schema is given.`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		driver, _ := cmd.Flags().GetString(generateModelCmdCfg.Driver.LongHand)
+		url, _ := cmd.Flags().GetString(generateModelCmdCfg.URL.LongHand)
+		schema, _ := cmd.Flags().GetString(generateModelCmdCfg.Schema.LongHand)
+		schemas, _ := cmd.Flags().GetStringSlice(diagramCmdCfg.Schemas.LongHand)
+
+		// Listing the schemas to draw is as good as setting the schema
+		if schema == "" && len(schemas) > 0 {
+			schema = schemas[0]
+		}
+
+		return rootCmdPreRunValidation(rootValidationConfig{
+			cli: rootCliConfig{
+				driver: app.DBDriver(driver),
+				url:    url,
+				schema: schema,
+			},
+			skipTs: true,
+		})
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var err error
+		var url, driver, schema, format, outFile string
+		var tables, excludeTables, schemas []string
+
+		if err = viper.ReadInConfig(); err == nil {
+			rootCmd := objx.New(viper.Get("root_cmd").(map[string]interface{}))
+
+			driver = rootCmd.Get("driver").Str()
+			url = rootCmd.Get("url").Str()
+			schema = rootCmd.Get("schema").Str()
+
+			if value, ok := viper.Get("diagram_cmd").(map[string]interface{}); ok {
+				diagramCmd := objx.New(value)
+
+				format = diagramCmd.Get("format").Str()
+				outFile = diagramCmd.Get("out").Str()
+				tables = stringsFromConfig(diagramCmd.Get("tables").InterSlice())
+				excludeTables = stringsFromConfig(diagramCmd.Get("exclude_tables").InterSlice())
+				schemas = stringsFromConfig(diagramCmd.Get("schemas").InterSlice())
+			}
+		}
+
+		driverTmp, _ := cmd.Flags().GetString(generateModelCmdCfg.Driver.LongHand)
+		urlTmp, _ := cmd.Flags().GetString(generateModelCmdCfg.URL.LongHand)
+		schemaTmp, _ := cmd.Flags().GetString(generateModelCmdCfg.Schema.LongHand)
+		formatTmp, _ := cmd.Flags().GetString(diagramCmdCfg.Format.LongHand)
+		outFileTmp, _ := cmd.Flags().GetString(diagramCmdCfg.OutFile.LongHand)
+		tablesTmp, _ := cmd.Flags().GetStringSlice(diagramCmdCfg.Tables.LongHand)
+		excludeTablesTmp, _ := cmd.Flags().GetStringSlice(diagramCmdCfg.ExcludeTables.LongHand)
+		schemasTmp, _ := cmd.Flags().GetStringSlice(diagramCmdCfg.Schemas.LongHand)
+
+		if driverTmp != "" {
+			driver = driverTmp
+		}
+		if urlTmp != "" {
+			url = urlTmp
+		}
+		if schemaTmp != "" {
+			schema = schemaTmp
+		}
+		if formatTmp != "" {
+			format = formatTmp
+		}
+		if outFileTmp != "" {
+			outFile = outFileTmp
+		}
+		if len(tablesTmp) > 0 {
+			tables = tablesTmp
+		}
+		if len(excludeTablesTmp) > 0 {
+			excludeTables = excludeTablesTmp
+		}
+		if len(schemasTmp) > 0 {
+			schemas = schemasTmp
+		}
+
+		if format == "" {
+			format = string(app.MermaidDiagramFormat)
+		}
+		if _, ok := diagramFormatMap[app.DiagramFormat(format)]; !ok {
+			return errors.WithStack(errInvalidDiagramFormat)
+		}
+
+		if len(schemas) == 0 {
+			schemas = []string{schema}
+		}
+
+		gormDB, err := getDBFromDriver(app.DBDriver(driver), url)
+
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		diagramSchemas := make([]app.DiagramSchema, 0, len(schemas))
+
+		for _, name := range schemas {
+			dbTables, err := app.LoadSchema(gormDB, app.DBDriver(driver), name, false)
+
+			if err != nil {
+				return errors.WithStack(err)
+			}
+
+			if dbTables, err = app.FilterTables(dbTables, tables, excludeTables); err != nil {
+				return errors.WithStack(err)
+			}
+
+			diagramSchemas = append(diagramSchemas, app.DiagramSchema{Name: name, Tables: dbTables})
+		}
+
+		content, err := app.RenderDiagram(diagramSchemas, app.DiagramFormat(format))
+
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		if outFile == "" {
+			fmt.Print(content)
+			return nil
+		}
+
+		return errors.WithStack(os.WriteFile(outFile, []byte(content), 0644))
+	},
+}
+
+// stringsFromConfig returns the non empty strings of values from the config file
+func stringsFromConfig(values []interface{}) []string {
+	var strs []string
+
+	for _, value := range values {
+		if s, ok := value.(string); ok && strings.TrimSpace(s) != "" {
+			strs = append(strs, strings.TrimSpace(s))
+		}
+	}
+
+	return strs
+}
+
+func init() {
+	rootCmd.AddCommand(diagramCmd)
+
+	diagramCmd.Flags().StringP(
+		diagramCmdCfg.Format.LongHand,
+		diagramCmdCfg.Format.ShortHand,
+		"",
+		"Format of the diagram.  Options are mermaid, dot, plantuml, dbml (default is mermaid)",
+	)
+	diagramCmd.Flags().StringSlice(
+		diagramCmdCfg.Tables.LongHand,
+		nil,
+		"Comma separated patterns of the tables to draw, eg. 'user_*,phone'.  Every table is drawn when empty",
+	)
+	diagramCmd.Flags().StringSlice(
+		diagramCmdCfg.ExcludeTables.LongHand,
+		nil,
+		"Comma separated patterns of the tables to leave out of the diagram",
+	)
+	diagramCmd.Flags().StringSlice(
+		diagramCmdCfg.Schemas.LongHand,
+		nil,
+		"Comma separated schemas to draw, each grouped on its own.  Only --schema is drawn when empty",
+	)
+	diagramCmd.Flags().StringP(
+		diagramCmdCfg.OutFile.LongHand,
+		diagramCmdCfg.OutFile.ShortHand,
+		"",
+		"File the diagram is written to.  The diagram is printed when empty",
+	)
+}
@@ -18,6 +18,8 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/TravisS25/model-gen/app"
 	"github.com/pkg/errors"
@@ -40,6 +42,8 @@ var (
 	errRootKeyNotSet         = errors.New("model-gen: root_cmd key in config file must be set")
 	errRootKeyDictionary     = errors.New("model-gen: root_cmd key must be dictionary type")
 	errInvalidTsFileSettings = errors.New("model-gen: --ts-dir and --ts-file must be set together")
+	errInvalidTsNullMode     = errors.New("model-gen: must choose valid --ts-null-mode.  Options are 'null', 'optional', 'optional-null'")
+	errInvalidTsMode         = errors.New("model-gen: must choose valid --ts-mode.  Options are 'interface', 'zod'")
 )
 
 var generateModelCmdCfg = generateModelCmdConfig{
@@ -64,6 +68,9 @@ var generateModelCmdCfg = generateModelCmdConfig{
 	FieldWithTypeTag: flagName{
 		LongHand: "field-with-type-tag",
 	},
+	FieldWithValidateTag: flagName{
+		LongHand: "field-with-validate-tag",
+	},
 	OutFile: flagName{
 		LongHand: "out-file",
 	},
@@ -79,18 +86,6 @@ var generateModelCmdCfg = generateModelCmdConfig{
 	ConvertTimestamp: flagName{
 		LongHand: "convert-timestamp",
 	},
-	ConvertDate: flagName{
-		LongHand: "convert-date",
-	},
-	ConvertBigint: flagName{
-		LongHand: "convert-bigint",
-	},
-	ConvertUUID: flagName{
-		LongHand: "convert-uuid",
-	},
-	LanguageType: flagName{
-		LongHand: "language-type",
-	},
 	RemoveGeneratedDirs: flagName{
 		LongHand: "remove-generated-dirs",
 	},
@@ -103,11 +98,116 @@ var generateModelCmdCfg = generateModelCmdConfig{
 	TsOutFile: flagName{
 		LongHand: "ts-out-file",
 	},
+	TsNullMode: flagName{
+		LongHand: "ts-null-mode",
+	},
+	TsMode: flagName{
+		LongHand: "ts-mode",
+	},
+	TsPerTable: flagName{
+		LongHand: "ts-per-table",
+	},
+	TsBrandedIDs: flagName{
+		LongHand: "ts-branded-ids",
+	},
+	FieldWithIDTypes: flagName{
+		LongHand: "field-with-id-types",
+	},
+	KotlinDir: flagName{
+		LongHand: "kotlin-dir",
+	},
+	KotlinFile: flagName{
+		LongHand: "kotlin-file",
+	},
+	KotlinPackage: flagName{
+		LongHand: "kotlin-package",
+	},
+	SwiftDir: flagName{
+		LongHand: "swift-dir",
+	},
+	SwiftFile: flagName{
+		LongHand: "swift-file",
+	},
+	PythonDir: flagName{
+		LongHand: "python-dir",
+	},
+	PythonFile: flagName{
+		LongHand: "python-file",
+	},
+	PythonLiteralEnums: flagName{
+		LongHand: "python-literal-enums",
+	},
+	RustDir: flagName{
+		LongHand: "rust-dir",
+	},
+	RustFile: flagName{
+		LongHand: "rust-file",
+	},
+	RustSqlx: flagName{
+		LongHand: "rust-sqlx",
+	},
+	CSharpDir: flagName{
+		LongHand: "csharp-dir",
+	},
+	CSharpFile: flagName{
+		LongHand: "csharp-file",
+	},
+	CSharpNamespace: flagName{
+		LongHand: "csharp-namespace",
+	},
+	CSharpClasses: flagName{
+		LongHand: "csharp-classes",
+	},
+	DartDir: flagName{
+		LongHand: "dart-dir",
+	},
+	DartFile: flagName{
+		LongHand: "dart-file",
+	},
+	ProtoDir: flagName{
+		LongHand: "proto-dir",
+	},
+	ProtoPackage: flagName{
+		LongHand: "proto-package",
+	},
+	ProtoLockFile: flagName{
+		LongHand: "proto-lock-file",
+	},
+	GraphQLDir: flagName{
+		LongHand: "graphql-dir",
+	},
+	GraphQLFile: flagName{
+		LongHand: "graphql-file",
+	},
+	JSONSchemaDir: flagName{
+		LongHand: "json-schema-dir",
+	},
+	OpenAPIDir: flagName{
+		LongHand: "openapi-dir",
+	},
+	OpenAPIFile: flagName{
+		LongHand: "openapi-file",
+	},
+	OpenAPITitle: flagName{
+		LongHand: "openapi-title",
+	},
+	OpenAPIVersion: flagName{
+		LongHand: "openapi-version",
+	},
+	ManifestFile: flagName{
+		LongHand: "manifest-file",
+	},
+}
+
+var tsNullModeMap = map[app.TsNullMode]bool{
+	app.TsNullModeNull:         true,
+	app.TsNullModeOptional:     true,
+	app.TsNullModeOptionalNull: true,
 }
 
-var languageTypeMap = map[app.LanguageType]bool{
-	app.GoLanguageType: true,
-	app.TsLanguageType: true,
+var tsModeMap = map[app.TsMode]bool{
+	app.TsModeInterface: true,
+	app.TsModeZod:       true,
 }
 
 var dbDriverMap = map[app.DBDriver]bool{
@@ -122,40 +222,75 @@ type rootViperConfig struct {
 }
 
 type rootCliConfig struct {
-	driver app.DBDriver
-	url    string
-	schema string
-	tsDir  string
-	tsFile string
+	driver     app.DBDriver
+	url        string
+	schema     string
+	tsDir      string
+	tsFile     string
+	tsPerTable bool
 }
 
 type rootValidationConfig struct {
 	configFile string
 	cli        rootCliConfig
+
+	// skipTs skips validating the typescript settings for commands that don't
+	// generate typescript
+	skipTs bool
 }
 
 type generateModelCmdConfig struct {
 	Driver flagName
 	URL    flagName
 
-	FieldNullable       flagName
-	FieldCoverable      flagName
-	FieldSignable       flagName
-	FieldWithIndexTag   flagName
-	FieldWithTypeTag    flagName
-	OutFile             flagName
-	QueryOutPath        flagName
-	ModelOutPath        flagName
-	Schema              flagName
-	ConvertTimestamp    flagName
-	ConvertDate         flagName
-	ConvertBigint       flagName
-	ConvertUUID         flagName
-	LanguageType        flagName
-	RemoveGeneratedDirs flagName
-	TsDir               flagName
-	TsFile              flagName
-	TsOutFile           flagName
+	FieldNullable        flagName
+	FieldCoverable       flagName
+	FieldSignable        flagName
+	FieldWithIndexTag    flagName
+	FieldWithTypeTag     flagName
+	FieldWithValidateTag flagName
+	OutFile              flagName
+	QueryOutPath         flagName
+	ModelOutPath         flagName
+	Schema               flagName
+	ConvertTimestamp     flagName
+	RemoveGeneratedDirs  flagName
+	TsDir                flagName
+	TsFile               flagName
+	TsOutFile            flagName
+	TsNullMode           flagName
+	TsMode               flagName
+	TsPerTable           flagName
+	TsBrandedIDs         flagName
+	FieldWithIDTypes     flagName
+	KotlinDir            flagName
+	KotlinFile           flagName
+	KotlinPackage        flagName
+	SwiftDir             flagName
+	SwiftFile            flagName
+	PythonDir            flagName
+	PythonFile           flagName
+	PythonLiteralEnums   flagName
+	RustDir              flagName
+	RustFile             flagName
+	RustSqlx             flagName
+	CSharpDir            flagName
+	CSharpFile           flagName
+	CSharpNamespace      flagName
+	CSharpClasses        flagName
+	DartDir              flagName
+	DartFile             flagName
+	ProtoDir             flagName
+	ProtoPackage         flagName
+	ProtoLockFile        flagName
+	GraphQLDir           flagName
+	GraphQLFile          flagName
+	JSONSchemaDir        flagName
+	OpenAPIDir           flagName
+	OpenAPIFile          flagName
+	OpenAPITitle         flagName
+	OpenAPIVersion       flagName
+	ManifestFile         flagName
 }
 
 // generator is a "wrapper" struct used to simply override the "GenerateModel" function
@@ -175,6 +310,10 @@ func (g *generator) GenerateModel(model string, opts ...gen.ModelOpt) interface{
 	return g.Generator.GenerateModel(model, opts...)
 }
 
+func (g *generator) GenerateModelAs(model, structName string, opts ...gen.ModelOpt) interface{} {
+	return g.Generator.GenerateModelAs(model, structName, opts...)
+}
+
 var cfgFile string
 
 // rootCmd represents the base command when called without any subcommands
@@ -195,16 +334,18 @@ to quickly create a Cobra application.`,
 		driver, _ := cmd.Flags().GetString(generateModelCmdCfg.Driver.LongHand)
 		url, _ := cmd.Flags().GetString(generateModelCmdCfg.URL.LongHand)
 		schema, _ := cmd.Flags().GetString(generateModelCmdCfg.Schema.LongHand)
-		tsDir, _ := cmd.Flags().GetString(generateModelCmdCfg.Schema.LongHand)
-		tsFile, _ := cmd.Flags().GetString(generateModelCmdCfg.Schema.LongHand)
+		tsDir, _ := cmd.Flags().GetString(generateModelCmdCfg.TsDir.LongHand)
+		tsFile, _ := cmd.Flags().GetString(generateModelCmdCfg.TsFile.LongHand)
+		tsPerTable, _ := cmd.Flags().GetBool(generateModelCmdCfg.TsPerTable.LongHand)
 
 		return rootCmdPreRunValidation(rootValidationConfig{
 			cli: rootCliConfig{
-				driver: app.DBDriver(driver),
-				url:    url,
-				schema: schema,
-				tsDir:  tsDir,
-				tsFile: tsFile,
+				driver:     app.DBDriver(driver),
+				url:        url,
+				schema:     schema,
+				tsDir:      tsDir,
+				tsFile:     tsFile,
+				tsPerTable: tsPerTable,
 			},
 		})
 	},
@@ -213,19 +354,32 @@ to quickly create a Cobra application.`,
 		var gormDB *gorm.DB
 		var err error
 		var removeGenDirs, fieldNullable, fieldCoverable, fieldSignable, fieldWithIndexTag,
-			fieldWithTypeTag bool
+			fieldWithTypeTag, fieldWithValidateTag, fieldWithIDTypes bool
 		var url, driver, schema, convertTimestamp, convertDate, convertBigint,
 			convertUUID, outFile, queryOutPath string
-		var modelOutPath, tsDir, tsFile, tsOutFile string
+		var typeMap app.TypeMap
+		var tables map[string]app.TableConfig
+		var graphqlTables map[string]app.GraphQLTableConfig
+		var tags []app.TagGenerator
+		var nullable app.NullableConfig
+		var tsTypes app.TsTypes
+		var nullableValue interface{}
+		var modelOutPath, manifestFile string
+
+		// Targets read their own settings so the config is kept for them
+		rootCfg := objx.New(map[string]interface{}{})
 
 		if err = viper.ReadInConfig(); err == nil {
 			rootCmd := objx.New(viper.Get("root_cmd").(map[string]interface{}))
+			rootCfg = rootCmd
 
 			fieldNullable = rootCmd.Get("field_nullable").Bool()
 			fieldCoverable = rootCmd.Get("field_coverable").Bool()
 			fieldSignable = rootCmd.Get("field_signable").Bool()
 			fieldWithIndexTag = rootCmd.Get("field_with_index_tag").Bool()
 			fieldWithTypeTag = rootCmd.Get("field_with_type_tag").Bool()
+			fieldWithValidateTag = rootCmd.Get("field_with_validate_tag").Bool()
+			fieldWithIDTypes = rootCmd.Get("field_with_id_types").Bool()
 			removeGenDirs = rootCmd.Get("remove_generated_dirs").Bool()
 
 			driver = rootCmd.Get("driver").Str()
@@ -238,9 +392,24 @@ to quickly create a Cobra application.`,
 			convertDate = rootCmd.Get("convert_date").Str()
 			convertBigint = rootCmd.Get("convert_bigint").Str()
 			convertUUID = rootCmd.Get("convert_uuid").Str()
-			tsDir = rootCmd.Get("ts_dir").Str()
-			tsFile = rootCmd.Get("ts_file").Str()
-			tsOutFile = rootCmd.Get("ts_out_file").Str()
+
+			if typeMap, err = typeMapFromConfig(rootCmd.Get("type_map").Data()); err != nil {
+				return err
+			}
+			if tables, err = tablesFromConfig(rootCmd.Get("tables").Data()); err != nil {
+				return err
+			}
+			if graphqlTables, err = graphqlTablesFromConfig(rootCmd.Get("tables").Data()); err != nil {
+				return err
+			}
+			if tags, err = tagsFromConfig(rootCmd.Get("tags").Data()); err != nil {
+				return err
+			}
+			if tsTypes, err = tsTypesFromConfig(rootCmd.Get("ts_types").Data()); err != nil {
+				return err
+			}
+			manifestFile = rootCmd.Get("manifest_file").Str()
+			nullableValue = rootCmd.Get("nullable").Data()
 		}
 
 		fieldNullableTmp, _ := cmd.Flags().GetBool(generateModelCmdCfg.FieldNullable.LongHand)
@@ -248,6 +417,8 @@ to quickly create a Cobra application.`,
 		fieldSignableTmp, _ := cmd.Flags().GetBool(generateModelCmdCfg.FieldSignable.LongHand)
 		fieldWithIndexTagTmp, _ := cmd.Flags().GetBool(generateModelCmdCfg.FieldWithIndexTag.LongHand)
 		fieldWithTypeTagTmp, _ := cmd.Flags().GetBool(generateModelCmdCfg.FieldWithTypeTag.LongHand)
+		fieldWithValidateTagTmp, _ := cmd.Flags().GetBool(generateModelCmdCfg.FieldWithValidateTag.LongHand)
+		fieldWithIDTypesTmp, _ := cmd.Flags().GetBool(generateModelCmdCfg.FieldWithIDTypes.LongHand)
 		removeGenDirsTmp, _ := cmd.Flags().GetBool(generateModelCmdCfg.RemoveGeneratedDirs.LongHand)
 
 		driverTmp, _ := cmd.Flags().GetString(generateModelCmdCfg.Driver.LongHand)
@@ -257,12 +428,7 @@ to quickly create a Cobra application.`,
 		queryOutPathTmp, _ := cmd.Flags().GetString(generateModelCmdCfg.QueryOutPath.LongHand)
 		modelOutPathTmp, _ := cmd.Flags().GetString(generateModelCmdCfg.ModelOutPath.LongHand)
 		convertTimestampTmp, _ := cmd.Flags().GetString(generateModelCmdCfg.ConvertTimestamp.LongHand)
-		convertDateTmp, _ := cmd.Flags().GetString(generateModelCmdCfg.ConvertDate.LongHand)
-		convertBigintTmp, _ := cmd.Flags().GetString(generateModelCmdCfg.ConvertBigint.LongHand)
-		convertUUIDTmp, _ := cmd.Flags().GetString(generateModelCmdCfg.ConvertUUID.LongHand)
-		tsDirTmp, _ := cmd.Flags().GetString(generateModelCmdCfg.TsDir.LongHand)
-		tsFileTmp, _ := cmd.Flags().GetString(generateModelCmdCfg.TsFile.LongHand)
-		tsOutFileTmp, _ := cmd.Flags().GetString(generateModelCmdCfg.TsOutFile.LongHand)
+		manifestFileTmp, _ := cmd.Flags().GetString(generateModelCmdCfg.ManifestFile.LongHand)
 
 		if fieldNullableTmp {
 			fieldNullable = fieldNullableTmp
@@ -279,6 +445,12 @@ to quickly create a Cobra application.`,
 		if fieldWithTypeTagTmp {
 			fieldWithTypeTag = fieldWithTypeTagTmp
 		}
+		if fieldWithValidateTagTmp {
+			fieldWithValidateTag = fieldWithValidateTagTmp
+		}
+		if fieldWithIDTypesTmp {
+			fieldWithIDTypes = fieldWithIDTypesTmp
+		}
 		if removeGenDirsTmp {
 			removeGenDirs = removeGenDirsTmp
 		}
@@ -304,34 +476,44 @@ to quickly create a Cobra application.`,
 		if convertTimestampTmp != "" {
 			convertTimestamp = convertTimestampTmp
 		}
-		if convertDateTmp != "" {
-			convertDate = convertDateTmp
+		if manifestFileTmp != "" {
+			manifestFile = manifestFileTmp
 		}
-		if convertBigintTmp != "" {
-			convertBigint = convertBigintTmp
-		}
-		if convertUUIDTmp != "" {
-			convertUUID = convertUUIDTmp
-		}
-		if tsDirTmp != "" {
-			tsDir = tsDirTmp
+
+		// The nullable strategy defaults to --field-nullable so it is parsed once
+		// both have been read
+		if nullable, err = nullableFromConfig(nullableValue, fieldNullable); err != nil {
+			return err
 		}
-		if tsFileTmp != "" {
-			tsFile = tsFileTmp
+
+		queryOutPath, modelOutPath = resolveOutPaths(queryOutPath, modelOutPath)
+
+		// All output is generated into a stage first and only moved into place once
+		// every generator succeeded so a failed run leaves previous output untouched
+		stage := app.NewOutputStage()
+		defer stage.Rollback()
+
+		stagedQueryOutPath, err := stage.Dir(queryOutPath)
+
+		if err != nil {
+			return errors.WithStack(err)
 		}
-		if tsOutFileTmp != "" {
-			tsOutFile = tsOutFileTmp
+
+		stagedModelOutPath, err := stage.Dir(modelOutPath)
+
+		if err != nil {
+			return errors.WithStack(err)
 		}
 
 		cfg = gen.Config{
-			FieldNullable:     fieldNullable,
+			FieldNullable:     fieldNullable || nullable.Pointers(),
 			FieldCoverable:    fieldCoverable,
 			FieldSignable:     fieldSignable,
 			FieldWithIndexTag: fieldWithIndexTag,
 			FieldWithTypeTag:  fieldWithTypeTag,
 			OutFile:           outFile,
-			OutPath:           queryOutPath,
-			ModelPkgPath:      modelOutPath,
+			OutPath:           stagedQueryOutPath,
+			ModelPkgPath:      stagedModelOutPath,
 		}
 
 		switch app.DBDriver(driver) {
@@ -347,70 +529,175 @@ to quickly create a Cobra application.`,
 			return fmt.Errorf("model-gen: init db err: %s\n", err.Error())
 		}
 
-		dataMap := map[string]func(detailType string) (dataType string){}
-
-		// Convert any types given from cli or file to desired types
-		if convertTimestamp != "" {
-			dataMap["timestamptz"] = func(detailType string) (dataType string) {
-				return convertTimestamp
-			}
+		// The deprecated convert settings are appended so any type_map entry for
+		// the same database type takes precedence
+		typeMap = append(typeMap, legacyTypeMap(map[string]string{
+			"convert_timestamp": convertTimestamp,
+			"convert_date":      convertDate,
+			"convert_bigint":    convertBigint,
+			"convert_uuid":      convertUUID,
+		})...)
+
+		modelCfg := app.ModelConfig{
+			Driver:       app.DBDriver(driver),
+			TypeMap:      typeMap,
+			Tables:       tables,
+			Tags:         tags,
+			ValidateTags: fieldWithValidateTag,
+			Nullable:     nullable,
 		}
-		if convertDate != "" {
-			dataMap["date"] = func(detailType string) (dataType string) {
-				return convertDate
+
+		checks := fieldWithValidateTag
+
+		for _, t := range targets {
+			if t.checks != nil && t.dir.str(rootCfg, cmd.Flags()) != "" && t.checks(t.values(rootCfg, cmd.Flags())) {
+				checks = true
 			}
 		}
-		if convertBigint != "" {
-			dataMap["int8"] = func(detailType string) (dataType string) {
-				return convertBigint
-			}
+
+		dbTables, err := app.LoadSchema(gormDB, app.DBDriver(driver), schema, checks)
+
+		if err != nil {
+			return errors.WithStack(err)
 		}
-		if convertUUID != "" {
-			dataMap["uuid"] = func(detailType string) (dataType string) {
-				return convertUUID
+
+		for _, table := range dbTables {
+			for _, fk := range table.ExternalForeignKeys {
+				fmt.Printf(
+					"model-gen: warning: %s.%s references %s.%s of another schema so no relation is generated for it\n",
+					table.Name,
+					fk.ColumnName,
+					fk.ForeignSchema,
+					fk.ForeignTableName,
+				)
 			}
 		}
 
 		g := gen.NewGenerator(cfg)
 		g.UseDB(gormDB)
-		g.WithDataTypeMap(dataMap)
+		g.WithDataTypeMap(typeMap.DataTypeMap(app.DBDriver(driver)))
+		g.WithImportPkgPath(modelCfg.GoImports(app.DBDriver(driver))...)
+
+		if fieldWithIDTypes {
+			if modelCfg.IDTypes, err = app.LoadIDTypes(
+				&generator{Generator: g},
+				dbTables,
+				app.DBDriver(driver),
+				modelCfg,
+			); err != nil {
+				return errors.WithStack(err)
+			}
+		}
 
 		if err = app.GenerateModels(
 			&generator{Generator: g},
-			gormDB,
+			dbTables,
 			app.DBDriver(driver),
-			schema,
+			modelCfg,
 		); err != nil {
 			return errors.WithStack(err)
 		}
 
+		if err = app.ApplyTableComments(stagedModelOutPath, dbTables, modelCfg); err != nil {
+			return errors.WithStack(err)
+		}
+		if err = app.ApplyNullableTypes(stagedModelOutPath, dbTables, modelCfg); err != nil {
+			return errors.WithStack(err)
+		}
+		if err = app.WriteIDTypes(stagedModelOutPath, dbTables, modelCfg); err != nil {
+			return errors.WithStack(err)
+		}
+
+		run := &targetRun{
+			tables:        dbTables,
+			driver:        app.DBDriver(driver),
+			model:         modelCfg,
+			graphqlTables: graphqlTables,
+			tsTypes:       tsTypes,
+		}
 		nonGoOutput := false
 
-		if tsDir != "" && tsFile != "" {
+		for _, t := range targets {
+			dir := t.dir.str(rootCfg, cmd.Flags())
+
+			if dir == "" {
+				continue
+			}
+
 			nonGoOutput = true
 
-			fmt.Printf("Generating ts files....\n")
-			fmt.Printf("%s", tsOutFile)
+			stagedDir, err := stage.Dir(dir)
 
-			if err = app.GenerateTsModels(
-				modelOutPath,
-				outFile,
-				tsDir,
-				tsFile,
-				tsOutFile,
-				app.GenerateConfig{},
-			); err != nil {
+			if err != nil {
+				return errors.WithStack(err)
+			}
+
+			if err = t.generate(stagedDir, t.values(rootCfg, cmd.Flags()), run); err != nil {
 				return errors.WithStack(err)
 			}
 		}
 
+		// Go code is only an intermediate step when generating other languages so
+		// it is never moved into place when it is meant to be removed
 		if nonGoOutput && removeGenDirs {
-			if err = app.RemoveGenDirs(queryOutPath, modelOutPath); err != nil {
-				return err
+			if err = stage.Discard(queryOutPath); err != nil {
+				return errors.WithStack(err)
+			}
+			if err = stage.Discard(modelOutPath); err != nil {
+				return errors.WithStack(err)
 			}
 		}
 
-		return nil
+		manifest, err := app.ReadManifest(manifestFile)
+
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		result, err := stage.Commit(manifest)
+
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		for _, file := range result.Kept {
+			fmt.Printf("model-gen: warning: %s is no longer generated but was edited so it was not deleted\n", file)
+		}
+
+		fmt.Printf(
+			"model-gen: %d created, %d updated, %d deleted, %d unchanged\n",
+			len(result.Created),
+			len(result.Updated),
+			len(result.Deleted),
+			len(result.Unchanged),
+		)
+
+		var removeErr error
+
+		if nonGoOutput && removeGenDirs {
+			var modified []string
+
+			modified, removeErr = app.RemoveGenDirs(manifest, queryOutPath, modelOutPath)
+
+			for _, file := range modified {
+				fmt.Printf("model-gen: warning: %s was edited since it was generated and was not removed\n", file)
+			}
+		}
+
+		// The manifest is saved even if removing failed partway so it forgets the
+		// files that were already removed
+		if err = manifest.Save(); err != nil {
+			return errors.WithStack(err)
+		}
+
+		// Field numbers are only recorded once the files using them are in place
+		if run.protoLock != nil {
+			if err = run.protoLock.Save(); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+
+		return removeErr
 	},
 }
 
@@ -434,6 +721,26 @@ func getDBFromDriver(driver app.DBDriver, url string) (*gorm.DB, error) {
 	return gormDB, err
 }
 
+// resolveOutPaths applies the same defaults gorm/gen uses for the query and model
+// output paths so they can be staged before generating
+//
+// A model path without a path separator is treated as a package name and placed
+// next to the query path
+func resolveOutPaths(queryOutPath, modelOutPath string) (string, string) {
+	if queryOutPath == "" {
+		queryOutPath = app.DefaultQueryOutPath
+	}
+
+	if modelOutPath == "" {
+		modelOutPath = filepath.Base(app.DefaultModelOutPath)
+	}
+	if !strings.Contains(modelOutPath, string(os.PathSeparator)) {
+		modelOutPath = filepath.Join(filepath.Dir(filepath.Clean(queryOutPath)), modelOutPath)
+	}
+
+	return queryOutPath, modelOutPath
+}
+
 func rootCmdPreRunValidation(cfg rootValidationConfig) error {
 	var err error
 	var ok bool
@@ -460,6 +767,7 @@ func rootCmdPreRunValidation(cfg rootValidationConfig) error {
 	url := rootCmdObjx.Get("url").Str()
 	tsDir := rootCmdObjx.Get("ts_dir").Str()
 	tsFile := rootCmdObjx.Get("ts_file").Str()
+	tsPerTable := rootCmdObjx.Get("ts_per_table").Bool()
 
 	if cfg.cli.driver != "" {
 		driver = cfg.cli.driver
@@ -476,6 +784,9 @@ func rootCmdPreRunValidation(cfg rootValidationConfig) error {
 	if cfg.cli.tsFile != "" {
 		tsFile = cfg.cli.tsFile
 	}
+	if cfg.cli.tsPerTable {
+		tsPerTable = cfg.cli.tsPerTable
+	}
 
 	if driver == "" || url == "" {
 		return errors.WithStack(errRequiredRootFields)
@@ -489,7 +800,12 @@ func rootCmdPreRunValidation(cfg rootValidationConfig) error {
 		return errors.WithStack(errMustSetSchema)
 	}
 
-	if (tsDir != "" && tsFile == "") || (tsDir == "" && tsFile != "") {
+	if cfg.skipTs {
+		return nil
+	}
+
+	// Every table gets its own file in per table mode so no file is named
+	if (tsDir != "" && tsFile == "" && !tsPerTable) || (tsDir == "" && tsFile != "") {
 		return errors.WithStack(errInvalidTsFileSettings)
 	}
 
@@ -548,15 +864,191 @@ func init() {
 		false,
 		"Generate with gorm column type tag",
 	)
+	rootCmd.PersistentFlags().Bool(
+		generateModelCmdCfg.FieldWithValidateTag.LongHand,
+		false,
+		"Generate go-playground/validator tags from column constraints like not null, length and check constraints",
+	)
+	rootCmd.PersistentFlags().Bool(
+		generateModelCmdCfg.FieldWithIDTypes.LongHand,
+		false,
+		"Generate a typed id per table primary key, eg. type UserID int64, used by the primary key and every foreign key referencing it",
+	)
 	rootCmd.PersistentFlags().String(
 		generateModelCmdCfg.OutFile.LongHand,
 		"gen.go",
 		"Query code file name for go",
 	)
+	rootCmd.PersistentFlags().String(
+		generateModelCmdCfg.TsDir.LongHand,
+		"",
+		"Directory ts models will be generated to.  Ts is only generated when set",
+	)
+	rootCmd.PersistentFlags().String(
+		generateModelCmdCfg.TsFile.LongHand,
+		"",
+		"Name of the single ts file every model is generated into.  Not used with --ts-per-table",
+	)
 	rootCmd.PersistentFlags().String(
 		generateModelCmdCfg.TsOutFile.LongHand,
-		"gen.ts",
-		"Query code file name for ts",
+		"",
+		"Query code file name for ts.  Default is 'gen.ts'",
+	)
+	rootCmd.PersistentFlags().String(
+		generateModelCmdCfg.TsNullMode.LongHand,
+		"",
+		`How nullable columns are typed in ts.  Options are 'null' (name: T | null), 'optional' (name?: T)
+		and 'optional-null' (name?: T | null).  Default is 'null'`,
+	)
+	rootCmd.PersistentFlags().String(
+		generateModelCmdCfg.TsMode.LongHand,
+		"",
+		"What is generated for every table in ts.  Options are 'interface' and 'zod' (zod schemas and inferred types).  Default is 'interface'",
+	)
+	rootCmd.PersistentFlags().Bool(
+		generateModelCmdCfg.TsPerTable.LongHand,
+		false,
+		"Generate a ts file per table along with an index.ts re-exporting all of them instead of a single file",
+	)
+	rootCmd.PersistentFlags().Bool(
+		generateModelCmdCfg.TsBrandedIDs.LongHand,
+		false,
+		"Generate a branded id type per table primary key, eg. UserId, used by the primary key and every foreign key referencing it",
+	)
+	rootCmd.PersistentFlags().String(
+		generateModelCmdCfg.KotlinDir.LongHand,
+		"",
+		"Directory kotlin data classes will be generated to.  Kotlin is only generated when set",
+	)
+	rootCmd.PersistentFlags().String(
+		generateModelCmdCfg.KotlinFile.LongHand,
+		"",
+		"Name of the single kotlin file every data class is generated into.  Each class gets its own file when empty",
+	)
+	rootCmd.PersistentFlags().String(
+		generateModelCmdCfg.KotlinPackage.LongHand,
+		"",
+		"Package the generated kotlin files declare, eg. com.acme.models",
+	)
+	rootCmd.PersistentFlags().String(
+		generateModelCmdCfg.SwiftDir.LongHand,
+		"",
+		"Directory swift Codable structs will be generated to.  Swift is only generated when set",
+	)
+	rootCmd.PersistentFlags().String(
+		generateModelCmdCfg.SwiftFile.LongHand,
+		"",
+		"Name of the single swift file every struct is generated into.  Each struct gets its own file when empty",
+	)
+	rootCmd.PersistentFlags().String(
+		generateModelCmdCfg.PythonDir.LongHand,
+		"",
+		"Directory pydantic models will be generated to along with an __init__.py.  Python is only generated when set",
+	)
+	rootCmd.PersistentFlags().String(
+		generateModelCmdCfg.PythonFile.LongHand,
+		"",
+		"Name of the single python module every model is generated into.  Each table gets its own module when empty",
+	)
+	rootCmd.PersistentFlags().Bool(
+		generateModelCmdCfg.PythonLiteralEnums.LongHand,
+		false,
+		"Generates Literal types instead of enum classes for enum columns in python",
+	)
+	rootCmd.PersistentFlags().String(
+		generateModelCmdCfg.RustDir.LongHand,
+		"",
+		"Directory rust serde structs will be generated to.  Rust is only generated when set",
+	)
+	rootCmd.PersistentFlags().String(
+		generateModelCmdCfg.RustFile.LongHand,
+		"",
+		"Name of the single rust file every struct is generated into.  Each table gets its own module and a mod.rs when empty",
+	)
+	rootCmd.PersistentFlags().Bool(
+		generateModelCmdCfg.RustSqlx.LongHand,
+		false,
+		"Derives sqlx::FromRow for rust structs and sqlx::Type for their enums",
+	)
+	rootCmd.PersistentFlags().String(
+		generateModelCmdCfg.CSharpDir.LongHand,
+		"",
+		"Directory c# records will be generated to.  C# is only generated when set",
+	)
+	rootCmd.PersistentFlags().String(
+		generateModelCmdCfg.CSharpFile.LongHand,
+		"",
+		"Name of the single c# file every record is generated into.  Each record gets its own file when empty",
+	)
+	rootCmd.PersistentFlags().String(
+		generateModelCmdCfg.CSharpNamespace.LongHand,
+		"",
+		"Namespace the generated c# files declare, eg. Acme.Models",
+	)
+	rootCmd.PersistentFlags().Bool(
+		generateModelCmdCfg.CSharpClasses.LongHand,
+		false,
+		"Generates classes with settable properties instead of positional records in c#",
+	)
+	rootCmd.PersistentFlags().String(
+		generateModelCmdCfg.DartDir.LongHand,
+		"",
+		"Directory dart json_serializable classes will be generated to.  Dart is only generated when set",
+	)
+	rootCmd.PersistentFlags().String(
+		generateModelCmdCfg.DartFile.LongHand,
+		"",
+		"Name of the single dart library every class is generated into.  Each table gets its own library when empty",
+	)
+	rootCmd.PersistentFlags().String(
+		generateModelCmdCfg.ProtoDir.LongHand,
+		"",
+		"Directory .proto files will be generated to, one per table.  Protobuf is only generated when set",
+	)
+	rootCmd.PersistentFlags().String(
+		generateModelCmdCfg.ProtoPackage.LongHand,
+		"",
+		"Package the generated .proto files declare, eg. acme.models",
+	)
+	rootCmd.PersistentFlags().String(
+		generateModelCmdCfg.ProtoLockFile.LongHand,
+		"",
+		"File the field numbers of generated protobuf messages are kept in so they never change across runs.  Defaults to .model_gen_proto_lock.json",
+	)
+	rootCmd.PersistentFlags().String(
+		generateModelCmdCfg.GraphQLDir.LongHand,
+		"",
+		"Directory the graphql schema will be generated to.  GraphQL is only generated when set",
+	)
+	rootCmd.PersistentFlags().String(
+		generateModelCmdCfg.GraphQLFile.LongHand,
+		"",
+		"Name of the single graphql schema file every type is generated into.  Each table gets its own file when empty",
+	)
+	rootCmd.PersistentFlags().String(
+		generateModelCmdCfg.JSONSchemaDir.LongHand,
+		"",
+		"Directory a json schema per table will be generated to.  JSON schemas are only generated when set",
+	)
+	rootCmd.PersistentFlags().String(
+		generateModelCmdCfg.OpenAPIDir.LongHand,
+		"",
+		"Directory the openapi 3.1 document holding the schema of every table under components.schemas will be generated to.  OpenAPI is only generated when set",
+	)
+	rootCmd.PersistentFlags().String(
+		generateModelCmdCfg.OpenAPIFile.LongHand,
+		"",
+		"Name of the generated openapi document, openapi.json by default",
+	)
+	rootCmd.PersistentFlags().String(
+		generateModelCmdCfg.OpenAPITitle.LongHand,
+		"",
+		"Title of the generated openapi document, Models by default",
+	)
+	rootCmd.PersistentFlags().String(
+		generateModelCmdCfg.OpenAPIVersion.LongHand,
+		"",
+		"Version of the generated openapi document, 1.0.0 by default",
 	)
 	rootCmd.PersistentFlags().String(
 		generateModelCmdCfg.QueryOutPath.LongHand,
@@ -578,11 +1070,20 @@ func init() {
 		"",
 		"Converts any db fields with timestamp data type to one entered",
 	)
+	rootCmd.PersistentFlags().MarkDeprecated(
+		generateModelCmdCfg.ConvertTimestamp.LongHand,
+		"use the type_map key in the config file instead",
+	)
 	rootCmd.PersistentFlags().Bool(
 		generateModelCmdCfg.RemoveGeneratedDirs.LongHand,
 		false,
 		`This option will allow cleanup of the generated go files that are created when converting from go
-		to whatever language selected`,
+		to whatever language selected.  Only files recorded in the manifest and unmodified since are removed`,
+	)
+	rootCmd.PersistentFlags().String(
+		generateModelCmdCfg.ManifestFile.LongHand,
+		"",
+		"File used to keep track of generated files and their content hashes (default is ./"+app.DefaultManifestFile+")",
 	)
 
 	rootCmd.MarkFlagRequired(generateModelCmdCfg.Driver.LongHand)
@@ -611,6 +1112,6 @@ func initConfig() {
 
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err == nil {
-		fmt.Println("Using config file:", viper.ConfigFileUsed())
+		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 	}
 }
@@ -1,5 +1,12 @@
 package cmd
 
+import (
+	"errors"
+	"testing"
+
+	"github.com/TravisS25/model-gen/app"
+)
+
 // func TestRootCmdPreRunValidation(t *testing.T) {
 // 	var err error
 
@@ -150,3 +157,30 @@ package cmd
 // 		t.Fatalf("error should contain '%v'; got '%v'\n", errMustSetSchemaConfig, err)
 // 	}
 // }
+
+func TestRootCmdPreRunValidationTs(t *testing.T) {
+	var err error
+
+	cli := rootCliConfig{driver: app.PostgresDriver, url: "url", schema: "public", tsDir: "web"}
+
+	if err = rootCmdPreRunValidation(rootValidationConfig{cli: cli}); !errors.Is(err, errInvalidTsFileSettings) {
+		t.Fatalf("should have error %v; got %v\n", errInvalidTsFileSettings, err)
+	}
+
+	if err = rootCmdPreRunValidation(rootValidationConfig{cli: cli, skipTs: true}); err != nil {
+		t.Fatalf("should not have error; got %v\n", err)
+	}
+
+	cli.tsPerTable = true
+
+	if err = rootCmdPreRunValidation(rootValidationConfig{cli: cli}); err != nil {
+		t.Fatalf("should not have error; got %v\n", err)
+	}
+
+	cli.tsDir = ""
+	cli.tsFile = "model"
+
+	if err = rootCmdPreRunValidation(rootValidationConfig{cli: cli}); !errors.Is(err, errInvalidTsFileSettings) {
+		t.Fatalf("should have error %v; got %v\n", errInvalidTsFileSettings, err)
+	}
+}
@@ -0,0 +1,304 @@
+package cmd
+
+import (
+	"github.com/TravisS25/model-gen/app"
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/objx"
+)
+
+// setting is a setting read from configKey of the root_cmd key of the config
+// file, overridden by flag when it is set
+type setting struct {
+	flag      flagName
+	configKey string
+}
+
+// str returns the string value of s
+func (s setting) str(rootCfg objx.Map, flags *pflag.FlagSet) string {
+	if value, _ := flags.GetString(s.flag.LongHand); value != "" {
+		return value
+	}
+
+	return rootCfg.Get(s.configKey).Str()
+}
+
+// boolean returns the bool value of s
+func (s setting) boolean(rootCfg objx.Map, flags *pflag.FlagSet) bool {
+	if value, _ := flags.GetBool(s.flag.LongHand); value {
+		return value
+	}
+
+	return rootCfg.Get(s.configKey).Bool()
+}
+
+// target is a language or schema format generated next to the go models
+type target struct {
+	// dir is the directory the target is generated into, the target is only
+	// generated when it is set
+	dir setting
+
+	// settings are the string settings of the target and switches are its bool
+	// settings, both passed to generate keyed by config key
+	settings []setting
+	switches []setting
+
+	// checks returns whether the target is generated from check constraints,
+	// nil if it never is
+	checks func(values targetValues) bool
+
+	// generate generates the target into dir, the staged directory of dir
+	generate func(dir string, values targetValues, run *targetRun) error
+}
+
+// targetValues holds the values of the settings of a target keyed by config key
+type targetValues struct {
+	settings map[string]string
+	switches map[string]bool
+}
+
+// targetRun holds what every target is generated from
+type targetRun struct {
+	tables        []app.Table
+	driver        app.DBDriver
+	model         app.ModelConfig
+	graphqlTables map[string]app.GraphQLTableConfig
+	tsTypes       app.TsTypes
+
+	// protoLock is set once protobuf is generated and saved after the generated
+	// files are in place
+	protoLock *app.ProtoLock
+}
+
+// targets are every target the root command generates, in the order they are
+// generated
+var targets = []target{
+	{
+		dir: setting{generateModelCmdCfg.TsDir, "ts_dir"},
+		settings: []setting{
+			{generateModelCmdCfg.TsFile, "ts_file"},
+			{generateModelCmdCfg.TsOutFile, "ts_out_file"},
+			{generateModelCmdCfg.TsNullMode, "ts_null_mode"},
+			{generateModelCmdCfg.TsMode, "ts_mode"},
+		},
+		switches: []setting{
+			{generateModelCmdCfg.TsPerTable, "ts_per_table"},
+			{generateModelCmdCfg.TsBrandedIDs, "ts_branded_ids"},
+		},
+		checks: func(values targetValues) bool {
+			return app.TsMode(values.settings["ts_mode"]) == app.TsModeZod
+		},
+		generate: generateTs,
+	},
+	{
+		dir: setting{generateModelCmdCfg.KotlinDir, "kotlin_dir"},
+		settings: []setting{
+			{generateModelCmdCfg.KotlinFile, "kotlin_file"},
+			{generateModelCmdCfg.KotlinPackage, "kotlin_package"},
+		},
+		generate: func(dir string, values targetValues, run *targetRun) error {
+			return app.GenerateKotlinModels(
+				run.tables,
+				dir,
+				values.settings["kotlin_file"],
+				app.GenerateConfig{Model: run.model, Package: values.settings["kotlin_package"]},
+			)
+		},
+	},
+	{
+		dir:      setting{generateModelCmdCfg.SwiftDir, "swift_dir"},
+		settings: []setting{{generateModelCmdCfg.SwiftFile, "swift_file"}},
+		generate: func(dir string, values targetValues, run *targetRun) error {
+			return app.GenerateSwiftModels(
+				run.tables,
+				dir,
+				values.settings["swift_file"],
+				app.GenerateConfig{Model: run.model},
+			)
+		},
+	},
+	{
+		dir:      setting{generateModelCmdCfg.PythonDir, "python_dir"},
+		settings: []setting{{generateModelCmdCfg.PythonFile, "python_file"}},
+		switches: []setting{{generateModelCmdCfg.PythonLiteralEnums, "python_literal_enums"}},
+		generate: func(dir string, values targetValues, run *targetRun) error {
+			return app.GeneratePythonModels(
+				run.tables,
+				dir,
+				values.settings["python_file"],
+				app.GenerateConfig{Model: run.model, LiteralEnums: values.switches["python_literal_enums"]},
+			)
+		},
+	},
+	{
+		dir:      setting{generateModelCmdCfg.RustDir, "rust_dir"},
+		settings: []setting{{generateModelCmdCfg.RustFile, "rust_file"}},
+		switches: []setting{{generateModelCmdCfg.RustSqlx, "rust_sqlx"}},
+		generate: func(dir string, values targetValues, run *targetRun) error {
+			return app.GenerateRustModels(
+				run.tables,
+				dir,
+				values.settings["rust_file"],
+				app.GenerateConfig{Model: run.model, FromRow: values.switches["rust_sqlx"]},
+			)
+		},
+	},
+	{
+		dir: setting{generateModelCmdCfg.CSharpDir, "csharp_dir"},
+		settings: []setting{
+			{generateModelCmdCfg.CSharpFile, "csharp_file"},
+			{generateModelCmdCfg.CSharpNamespace, "csharp_namespace"},
+		},
+		switches: []setting{{generateModelCmdCfg.CSharpClasses, "csharp_classes"}},
+		generate: func(dir string, values targetValues, run *targetRun) error {
+			return app.GenerateCSharpModels(
+				run.tables,
+				dir,
+				values.settings["csharp_file"],
+				app.GenerateConfig{
+					Model:   run.model,
+					Package: values.settings["csharp_namespace"],
+					Classes: values.switches["csharp_classes"],
+				},
+			)
+		},
+	},
+	{
+		dir:      setting{generateModelCmdCfg.DartDir, "dart_dir"},
+		settings: []setting{{generateModelCmdCfg.DartFile, "dart_file"}},
+		generate: func(dir string, values targetValues, run *targetRun) error {
+			return app.GenerateDartModels(
+				run.tables,
+				dir,
+				values.settings["dart_file"],
+				app.GenerateConfig{Model: run.model},
+			)
+		},
+	},
+	{
+		dir: setting{generateModelCmdCfg.ProtoDir, "proto_dir"},
+		settings: []setting{
+			{generateModelCmdCfg.ProtoPackage, "proto_package"},
+			{generateModelCmdCfg.ProtoLockFile, "proto_lock_file"},
+		},
+		generate: func(dir string, values targetValues, run *targetRun) error {
+			lock, err := app.ReadProtoLock(values.settings["proto_lock_file"])
+
+			if err != nil {
+				return err
+			}
+
+			run.protoLock = lock
+
+			return app.GenerateProtoFiles(
+				run.tables,
+				dir,
+				lock,
+				app.GenerateConfig{Model: run.model, Package: values.settings["proto_package"]},
+			)
+		},
+	},
+	{
+		dir:      setting{generateModelCmdCfg.GraphQLDir, "graphql_dir"},
+		settings: []setting{{generateModelCmdCfg.GraphQLFile, "graphql_file"}},
+		generate: func(dir string, values targetValues, run *targetRun) error {
+			return app.GenerateGraphQLSchema(
+				run.tables,
+				dir,
+				values.settings["graphql_file"],
+				app.GenerateConfig{Model: run.model, GraphQLTables: run.graphqlTables},
+			)
+		},
+	},
+	{
+		dir:    setting{generateModelCmdCfg.JSONSchemaDir, "json_schema_dir"},
+		checks: alwaysChecks,
+		generate: func(dir string, values targetValues, run *targetRun) error {
+			return app.GenerateJSONSchemas(run.tables, dir, app.GenerateConfig{Model: run.model})
+		},
+	},
+	{
+		dir: setting{generateModelCmdCfg.OpenAPIDir, "openapi_dir"},
+		settings: []setting{
+			{generateModelCmdCfg.OpenAPIFile, "openapi_file"},
+			{generateModelCmdCfg.OpenAPITitle, "openapi_title"},
+			{generateModelCmdCfg.OpenAPIVersion, "openapi_version"},
+		},
+		checks: alwaysChecks,
+		generate: func(dir string, values targetValues, run *targetRun) error {
+			return app.GenerateOpenAPIComponents(
+				run.tables,
+				dir,
+				values.settings["openapi_file"],
+				app.GenerateConfig{
+					Model:   run.model,
+					Title:   values.settings["openapi_title"],
+					Version: values.settings["openapi_version"],
+				},
+			)
+		},
+	},
+}
+
+// alwaysChecks is the checks of targets always generated from check constraints
+func alwaysChecks(targetValues) bool {
+	return true
+}
+
+// values returns the values of the settings of t
+func (t target) values(rootCfg objx.Map, flags *pflag.FlagSet) targetValues {
+	values := targetValues{
+		settings: make(map[string]string, len(t.settings)),
+		switches: make(map[string]bool, len(t.switches)),
+	}
+
+	for _, s := range t.settings {
+		values.settings[s.configKey] = s.str(rootCfg, flags)
+	}
+	for _, s := range t.switches {
+		values.switches[s.configKey] = s.boolean(rootCfg, flags)
+	}
+
+	return values
+}
+
+// generateTs generates typescript interfaces or zod schemas
+func generateTs(dir string, values targetValues, run *targetRun) error {
+	nullMode := app.TsNullMode(values.settings["ts_null_mode"])
+	mode := app.TsMode(values.settings["ts_mode"])
+
+	if nullMode == "" {
+		nullMode = app.TsNullModeNull
+	}
+	if _, ok := tsNullModeMap[nullMode]; !ok {
+		return errors.WithStack(errInvalidTsNullMode)
+	}
+	if mode == "" {
+		mode = app.TsModeInterface
+	}
+	if _, ok := tsModeMap[mode]; !ok {
+		return errors.WithStack(errInvalidTsMode)
+	}
+
+	outFile := values.settings["ts_out_file"]
+
+	if outFile == "" {
+		outFile = "gen.ts"
+	}
+
+	return app.GenerateTsModels(
+		run.tables,
+		run.driver,
+		dir,
+		values.settings["ts_file"],
+		outFile,
+		app.GenerateConfig{
+			Model:      run.model,
+			NullMode:   nullMode,
+			Mode:       mode,
+			PerTable:   values.switches["ts_per_table"],
+			Types:      run.tsTypes,
+			BrandedIDs: values.switches["ts_branded_ids"],
+		},
+	)
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/objx"
+)
+
+func TestSetting(t *testing.T) {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String(generateModelCmdCfg.KotlinDir.LongHand, "", "")
+	flags.Bool(generateModelCmdCfg.RustSqlx.LongHand, false, "")
+
+	rootCfg := objx.New(map[string]interface{}{"kotlin_dir": "config", "rust_sqlx": true})
+	dir := setting{generateModelCmdCfg.KotlinDir, "kotlin_dir"}
+	sqlx := setting{generateModelCmdCfg.RustSqlx, "rust_sqlx"}
+
+	if value := dir.str(rootCfg, flags); value != "config" {
+		t.Fatalf("expected value of config file; got '%s'\n", value)
+	}
+	if !sqlx.boolean(rootCfg, flags) {
+		t.Fatalf("expected value of config file to be true\n")
+	}
+
+	flags.Set(generateModelCmdCfg.KotlinDir.LongHand, "flag")
+
+	if value := dir.str(rootCfg, flags); value != "flag" {
+		t.Fatalf("flag should override config file; got '%s'\n", value)
+	}
+
+	// A flag default would always win over the config file
+	outFile := setting{generateModelCmdCfg.TsOutFile, "ts_out_file"}
+	rootCfg = objx.New(map[string]interface{}{"ts_out_file": "api.ts"})
+
+	if value := outFile.str(rootCfg, rootCmd.PersistentFlags()); value != "api.ts" {
+		t.Fatalf("expected value of config file; got '%s'\n", value)
+	}
+}
+
+func TestTargetsFlags(t *testing.T) {
+	flags := rootCmd.PersistentFlags()
+
+	for _, target := range targets {
+		settings := append([]setting{target.dir}, target.settings...)
+
+		for _, s := range append(settings, target.switches...) {
+			if flags.Lookup(s.flag.LongHand) == nil {
+				t.Fatalf("flag '%s' of setting '%s' should be registered\n", s.flag.LongHand, s.configKey)
+			}
+		}
+	}
+}